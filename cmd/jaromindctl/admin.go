@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/database"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+const minAdminPasswordLength = 12
+
+func runAdmin(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: jaromindctl admin <create|rotate-password> [flags]")
+	}
+
+	switch args[0] {
+	case "create":
+		return adminCreate(args[1:])
+	case "rotate-password":
+		return adminRotatePassword(args[1:])
+	}
+	return fmt.Errorf("unknown admin subcommand %q", args[0])
+}
+
+// adminCreate - jaromindctl admin create --email E --name N [--role R] [--password P]
+func adminCreate(args []string) error {
+	fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	email := fs.String("email", "", "admin email (required)")
+	name := fs.String("name", "", "display name (required)")
+	role := fs.String("role", models.RoleSuperAdmin, "content-admin or super-admin")
+	password := fs.String("password", "", "initial password (default: $ADMIN_PASSWORD, or generated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" || *name == "" {
+		fs.Usage()
+		return errors.New("--email and --name are required")
+	}
+
+	pw, generated, err := resolvePassword(*password)
+	if err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	admin, err := servicesimpl.NewAdminService().Create(ctx, *email, *name, pw, *role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Created %s %s (%s)\n", admin.Role, admin.Email, admin.ID.Hex())
+	if generated {
+		fmt.Printf("🔑 Generated password: %s\n", pw)
+	}
+	return nil
+}
+
+// adminRotatePassword - jaromindctl admin rotate-password --email E [--password P]
+func adminRotatePassword(args []string) error {
+	fs := flag.NewFlagSet("admin rotate-password", flag.ContinueOnError)
+	email := fs.String("email", "", "admin email (required)")
+	password := fs.String("password", "", "new password (default: $ADMIN_PASSWORD, or generated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		fs.Usage()
+		return errors.New("--email is required")
+	}
+
+	pw, generated, err := resolvePassword(*password)
+	if err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := servicesimpl.NewAdminService().SetPassword(ctx, *email, pw); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Password rotated for %s; existing sessions were revoked\n", *email)
+	if generated {
+		fmt.Printf("🔑 Generated password: %s\n", pw)
+	}
+	return nil
+}
+
+// resolvePassword - Flag value, then $ADMIN_PASSWORD, then a random password
+func resolvePassword(flagValue string) (string, bool, error) {
+	pw := flagValue
+	if pw == "" {
+		pw = os.Getenv("ADMIN_PASSWORD")
+	}
+	if pw == "" {
+		generated, err := utils.GenerateSecureToken(18)
+		return generated, true, err
+	}
+	if len(pw) < minAdminPasswordLength {
+		return "", false, fmt.Errorf("admin passwords must be at least %d characters", minAdminPasswordLength)
+	}
+	return pw, false, nil
+}
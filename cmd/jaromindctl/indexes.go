@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/database"
+)
+
+func runIndexes(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: jaromindctl indexes <plan|apply> [flags]")
+	}
+
+	switch args[0] {
+	case "plan":
+		return indexesPlan(args[1:])
+	case "apply":
+		return indexesApply(args[1:])
+	}
+	return fmt.Errorf("unknown indexes subcommand %q", args[0])
+}
+
+// indexesPlan - jaromindctl indexes plan [--all]. Dry run: nothing is changed.
+func indexesPlan(args []string) error {
+	fs := flag.NewFlagSet("indexes plan", flag.ContinueOnError)
+	all := fs.Bool("all", false, "also list indexes that are already up to date")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	plan, err := database.PlanIndexes(ctx, database.GetDB())
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, change := range plan {
+		if change.Action == database.IndexCreate || change.Action == database.IndexRecreate {
+			pending++
+		}
+		if change.Action != database.IndexUnchanged || *all {
+			fmt.Println(" ", change)
+		}
+	}
+	fmt.Printf("%d indexes to create or rebuild (+ create, ~ rebuild, ? not declared, left alone)\n", pending)
+	return nil
+}
+
+// indexesApply - jaromindctl indexes apply
+func indexesApply(args []string) error {
+	fs := flag.NewFlagSet("indexes apply", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	// Building an index on a large collection can take a while
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	plan, err := database.EnsureIndexes(ctx, database.GetDB(), false)
+	if err != nil {
+		return err
+	}
+
+	applied := 0
+	for _, change := range plan {
+		if change.Action == database.IndexCreate || change.Action == database.IndexRecreate {
+			applied++
+		}
+	}
+	fmt.Printf("✅ Applied %d indexes\n", applied)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// runKeys - JWT signing key rotation. The procedure is described in utils/keys.go.
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: jaromindctl keys <list|generate|retire> [flags]")
+	}
+
+	switch args[0] {
+	case "list":
+		return keysList(args[1:])
+	case "generate":
+		return keysGenerate(args[1:])
+	case "retire":
+		return keysRetire(args[1:])
+	}
+	return fmt.Errorf("unknown keys subcommand %q", args[0])
+}
+
+// keysList - jaromindctl keys list [--dir D]
+func keysList(args []string) error {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	dir := fs.String("dir", utils.KeysDir(), "key directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(*dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		fmt.Printf("No keys in %s\n", *dir)
+		return nil
+	}
+	for _, path := range paths {
+		key, err := utils.ReadKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		usage := "sign+verify"
+		if key.Private == nil {
+			usage = "verify only"
+		}
+		fmt.Printf("  %-24s %-6s %s\n", key.ID, key.Method.Alg(), usage)
+	}
+	return nil
+}
+
+// keysGenerate - jaromindctl keys generate [--alg EdDSA|RS256] [--kid K] [--dir D]
+func keysGenerate(args []string) error {
+	fs := flag.NewFlagSet("keys generate", flag.ContinueOnError)
+	dir := fs.String("dir", utils.KeysDir(), "key directory")
+	alg := fs.String("alg", "EdDSA", "EdDSA or RS256")
+	kid := fs.String("kid", time.Now().UTC().Format("2006-01-02"), "key ID (defaults to today's date)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := utils.GenerateSigningKey(*kid, *alg)
+	if err != nil {
+		return err
+	}
+
+	path, err := utils.WritePrivateKey(*dir, key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Wrote %s (%s)\n", path, key.Method.Alg())
+	fmt.Println("   Deploy it with JWT_ACTIVE_KID pinned to the current key first, then switch.")
+	return nil
+}
+
+// keysRetire - jaromindctl keys retire --kid K [--dir D]
+// Replaces <kid>.pem with <kid>.pub.pem so the key verifies but can no longer sign.
+func keysRetire(args []string) error {
+	fs := flag.NewFlagSet("keys retire", flag.ContinueOnError)
+	dir := fs.String("dir", utils.KeysDir(), "key directory")
+	kid := fs.String("kid", "", "key ID to retire (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *kid == "" {
+		fs.Usage()
+		return errors.New("--kid is required")
+	}
+
+	privatePath := filepath.Join(*dir, *kid+".pem")
+	key, err := utils.ReadKeyFile(privatePath)
+	if err != nil {
+		return err
+	}
+
+	publicPath, err := utils.WritePublicKey(*dir, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(privatePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Retired %s: %s is now verify-only\n", *kid, publicPath)
+	return nil
+}
@@ -0,0 +1,62 @@
+// jaromindctl - Operator commands for the JaroMind backend.
+//
+// It connects to the same MongoDB as the API (MONGO_URI / DB_NAME, .env is
+// loaded the same way), so it can be run next to the server:
+//
+//	go run ./cmd/jaromindctl admin create --email ops@jaromind.com --name Ops
+//	go run ./cmd/jaromindctl admin rotate-password --email ops@jaromind.com
+//	go run ./cmd/jaromindctl migrate up
+//	go run ./cmd/jaromindctl indexes plan
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command - A top-level subcommand; args excludes the command name itself
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"admin":   {summary: "Create admins and rotate their credentials", run: runAdmin},
+	"indexes": {summary: "Show and apply the declared MongoDB indexes", run: runIndexes},
+	"keys":    {summary: "Generate, list and retire JWT signing keys", run: runKeys},
+	"migrate": {summary: "Apply, roll back and list schema migrations", run: runMigrate},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: jaromindctl <command> [subcommand] [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/database"
+	"github.com/AbaraEmmanuel/jaromind-backend/migrations"
+)
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: jaromindctl migrate <status|up|down> [flags]")
+	}
+
+	switch args[0] {
+	case "status":
+		return migrateStatus(args[1:])
+	case "up":
+		return migrateUp(args[1:])
+	case "down":
+		return migrateDown(args[1:])
+	}
+	return fmt.Errorf("unknown migrate subcommand %q", args[0])
+}
+
+// migrateStatus - jaromindctl migrate status
+func migrateStatus(args []string) error {
+	fs := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	statuses, err := migrations.List(ctx, database.GetDB())
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("  %04d  %-28s %s\n", s.Version, s.Name, applied)
+	}
+	return nil
+}
+
+// migrateUp - jaromindctl migrate up [--to N]
+func migrateUp(args []string) error {
+	fs := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	to := fs.Int("to", 0, "last version to apply (default: all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	applied, err := migrations.Up(ctx, database.GetDB(), *to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Applied %d migrations\n", len(applied))
+	return nil
+}
+
+// migrateDown - jaromindctl migrate down [--to N]. Without --to only the
+// latest applied migration is reverted.
+func migrateDown(args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	to := fs.Int("to", -1, "revert every migration newer than this version (default: the latest only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	database.InitDatabase()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	db := database.GetDB()
+	target := *to
+	if target < 0 {
+		statuses, err := migrations.List(ctx, db)
+		if err != nil {
+			return err
+		}
+		// Revert down to the newest applied migration before the latest one
+		latest := 0
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				target, latest = latest, s.Version
+			}
+		}
+		if latest == 0 {
+			fmt.Println("No migrations applied")
+			return nil
+		}
+	}
+
+	reverted, err := migrations.Down(ctx, db, target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Reverted %d migrations\n", len(reverted))
+	return nil
+}
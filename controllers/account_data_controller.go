@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// ExportAccountData handles GET /user/export - a JSON download of everything stored about the student
+func ExportAccountData(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	export, err := servicesimpl.NewAccountDataService().Export(c.Request.Context(), userID)
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("jaromind-export-%s-%s.json", userID, export.ExportedAt.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// DeleteAccount handles DELETE /user/account. Reviews are anonymized and
+// enrollments removed right away; the account itself is removed after the
+// grace period by a background job.
+func DeleteAccount(c *gin.Context) {
+	// The body may be empty for social-login accounts, which have no password
+	var request DeleteAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledAt, err := servicesimpl.NewAccountDataService().RequestDeletion(c.Request.Context(), middleware.CurrentUserID(c), request.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrIncorrectPassword) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Confirm with your current password"})
+			return
+		}
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Your account has been deleted and you have been signed out everywhere",
+		"scheduledAt": scheduledAt,
+	})
+}
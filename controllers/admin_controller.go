@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// adminErrorStatus maps admin service errors to HTTP status codes
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrAdminNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAdminExists):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRole):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrLastSuperAdmin), errors.Is(err, services.ErrCannotModifySelf):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func respondAdminError(c *gin.Context, err error) {
+	status := adminErrorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = "Something went wrong, please try again"
+	}
+	c.JSON(status, gin.H{"success": false, "error": message})
+}
+
+// ListAdmins handles GET /admin/admins
+func ListAdmins(c *gin.Context) {
+	admins, err := servicesimpl.NewAdminService().List(c.Request.Context())
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"admins":  admins,
+		"count":   len(admins),
+	})
+}
+
+type InviteAdminRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Name  string `json:"name" binding:"required"`
+	Role  string `json:"role"`
+}
+
+// InviteAdmin handles POST /admin/admins - creates the account and emails a set-password link
+func InviteAdmin(c *gin.Context) {
+	var request InviteAdminRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	admin, err := servicesimpl.NewAdminService().Invite(c.Request.Context(), request.Email, request.Name, request.Role, middleware.CurrentUserID(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Invitation sent",
+		"admin":   admin,
+	})
+}
+
+type UpdateAdminRequest struct {
+	Name     *string `json:"name"`
+	Role     *string `json:"role"`
+	IsActive *bool   `json:"isActive"`
+}
+
+// UpdateAdmin handles PATCH /admin/admins/:id - rename, change role, or (de)activate
+func UpdateAdmin(c *gin.Context) {
+	var request UpdateAdminRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	admin, err := servicesimpl.NewAdminService().Update(c.Request.Context(), c.Param("id"), middleware.CurrentUserID(c), services.AdminUpdate{
+		Name:     request.Name,
+		Role:     request.Role,
+		IsActive: request.IsActive,
+	})
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"admin":   admin,
+	})
+}
+
+// ResetAdminPassword handles POST /admin/admins/:id/reset-password - emails the admin a reset token
+func ResetAdminPassword(c *gin.Context) {
+	if err := servicesimpl.NewAdminService().SendPasswordReset(c.Request.Context(), c.Param("id")); err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset email sent",
+	})
+}
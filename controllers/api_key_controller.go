@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+func respondAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAPIKeyRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Something went wrong, please try again"})
+	}
+}
+
+// apiKeyOwnerScope - "" (every key) for admins who manage other admins, else the caller
+func apiKeyOwnerScope(c *gin.Context) string {
+	if models.HasPermission(middleware.CurrentRole(c), models.PermAdminManage) {
+		return ""
+	}
+	return middleware.CurrentUserID(c)
+}
+
+// ListAPIKeys handles GET /admin/api-keys - your keys, or with ?all=true every key (super-admins)
+func ListAPIKeys(c *gin.Context) {
+	ownerID := middleware.CurrentUserID(c)
+	if c.Query("all") == "true" {
+		ownerID = apiKeyOwnerScope(c)
+	}
+
+	keys, err := servicesimpl.NewAPIKeyService().List(c.Request.Context(), ownerID)
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"apiKeys": keys,
+		"count":   len(keys),
+		"scopes":  models.APIKeyScopes,
+	})
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string              `json:"name" binding:"required"`
+	Scopes    []models.Permission `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time          `json:"expiresAt"`
+}
+
+// CreateAPIKey handles POST /admin/api-keys. The key is only ever shown in this response.
+func CreateAPIKey(c *gin.Context) {
+	var request CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	apiKey, plain, err := servicesimpl.NewAPIKeyService().Create(
+		c.Request.Context(),
+		middleware.CurrentUserID(c),
+		middleware.CurrentRole(c),
+		request.Name,
+		request.Scopes,
+		request.ExpiresAt,
+	)
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Store this key now, it won't be shown again",
+		"key":     plain,
+		"apiKey":  apiKey,
+	})
+}
+
+// RevokeAPIKey handles DELETE /admin/api-keys/:id
+func RevokeAPIKey(c *gin.Context) {
+	err := servicesimpl.NewAPIKeyService().Revoke(c.Request.Context(), c.Param("id"), apiKeyOwnerScope(c))
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "API key revoked"})
+}
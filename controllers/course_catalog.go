@@ -0,0 +1,377 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultCoursePageSize = 20
+	maxCoursePageSize     = 100
+	maxCatalogTags        = 10
+)
+
+// sortableCourseFields - What ?sortBy= accepts. Anything else would sort on
+// an unindexed or internal field.
+var sortableCourseFields = map[string]bool{
+	"createdAt":       true,
+	"updatedAt":       true,
+	"title":           true,
+	"price":           true,
+	"rating":          true,
+	"reviewCount":     true,
+	"enrollmentCount": true,
+	"lessonCount":     true,
+}
+
+// catalogFacetFields - The fields ?facets=true counts courses by, besides price
+var catalogFacetFields = []string{"category", "level", "language", "classLevel", "subject"}
+
+// defaultPriceBands - Boundaries between the paid price bands
+const defaultPriceBands = "5000,20000,50000"
+
+// catalogRequest - A parsed catalog listing request
+type catalogRequest struct {
+	query  repository.CourseQuery
+	page   int64 // 0 when paging by cursor
+	limit  int64
+	facets bool
+}
+
+// parseCatalogRequest reads the filters, sort and page of a catalog listing:
+//
+//	?type= &classLevel= &subject= &category= &level= &language= &featured=true
+//	?status= - only for admins, the public catalog lists published courses
+//	?tag=a&tag=b (or ?tag=a,b) - courses with every tag
+//	?minPrice= &maxPrice= &minRating=
+//	?sortBy= &order=asc|desc
+//	?page= &limit=, or ?cursor= with the nextCursor of the previous page
+//	?facets=true - also count the courses per category, level, ... and price band
+func parseCatalogRequest(c *gin.Context) (*catalogRequest, models.FieldErrors) {
+	errs := models.FieldErrors{}
+	query := repository.CourseQuery{Match: repository.Fields{}}
+
+	for param, field := range map[string]string{
+		"type":       "type",
+		"classLevel": "classLevel",
+		"subject":    "subject",
+		"category":   "category",
+		"status":     "status",
+		"level":      "level",
+		"language":   "language",
+	} {
+		if value := strings.TrimSpace(c.Query(param)); value != "" {
+			query.Match[field] = value
+		}
+	}
+	if status, ok := query.Match["status"].(string); ok && !models.IsCourseStatus(status) {
+		errs["status"] = "must be one of " + strings.Join(models.CourseStatuses, ", ")
+	}
+	if c.Query("featured") == "true" {
+		query.Match["isFeatured"] = true
+	}
+
+	for _, value := range c.QueryArray("tag") {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				query.Tags = append(query.Tags, tag)
+			}
+		}
+	}
+	if len(query.Tags) > maxCatalogTags {
+		errs["tag"] = fmt.Sprintf("at most %d tags can be combined", maxCatalogTags)
+	}
+
+	query.MinPrice = parseCatalogNumber(c, errs, "minPrice", 0, -1)
+	query.MaxPrice = parseCatalogNumber(c, errs, "maxPrice", 0, -1)
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		errs["maxPrice"] = "must not be less than minPrice"
+	}
+	query.MinRating = parseCatalogNumber(c, errs, "minRating", 0, 5)
+
+	query.Sort = repository.Sort{Field: c.DefaultQuery("sortBy", "createdAt")}
+	if !sortableCourseFields[query.Sort.Field] {
+		errs["sortBy"] = "must be one of " + strings.Join(sortableCourseFieldNames(), ", ")
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+		query.Sort.Descending = true
+	case "asc":
+	default:
+		errs["order"] = "must be asc or desc"
+	}
+
+	request := &catalogRequest{limit: defaultCoursePageSize, facets: c.Query("facets") == "true"}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || limit < 1 || limit > maxCoursePageSize {
+			errs["limit"] = fmt.Sprintf("must be a whole number from 1 to %d", maxCoursePageSize)
+		}
+		request.limit = limit
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		if c.Query("page") != "" {
+			errs["page"] = "cannot be combined with cursor"
+		}
+		cursor, err := repository.DecodeCourseCursor(token)
+		if err != nil {
+			errs["cursor"] = "is invalid"
+		} else if cursor.Field != query.Sort.Field || cursor.Descending != query.Sort.Descending {
+			errs["cursor"] = "belongs to a listing with another sortBy or order"
+		}
+		query.After = cursor
+	} else {
+		request.page = 1
+		if raw := c.Query("page"); raw != "" {
+			page, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || page < 1 {
+				errs["page"] = "must be a whole number from 1"
+			}
+			request.page = page
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if request.page > 0 {
+		query.Skip = (request.page - 1) * request.limit
+	}
+	// One extra course tells whether there is a next page
+	query.Limit = request.limit + 1
+	request.query = query
+	return request, nil
+}
+
+// parseCatalogNumber - An optional number parameter within [min, max]
+// (max < 0: no upper bound); nil when it is absent or invalid
+func parseCatalogNumber(c *gin.Context, errs models.FieldErrors, param string, min, max float64) *float64 {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	switch {
+	case err != nil:
+		errs[param] = "must be a number"
+	case value < min:
+		errs[param] = fmt.Sprintf("must be at least %g", min)
+	case max >= 0 && value > max:
+		errs[param] = fmt.Sprintf("must be at most %g", max)
+	default:
+		return &value
+	}
+	return nil
+}
+
+// catalogFacets - What ?facets=true counts. The price bands are "free", then
+// one band up to each COURSE_PRICE_BANDS boundary (ascending, comma-separated)
+// and one above the last, e.g. "free", "0-5000", ..., "50000+".
+func catalogFacets() repository.CourseFacets {
+	boundaries, err := parsePriceBands(utils.GetEnv("COURSE_PRICE_BANDS", defaultPriceBands))
+	if err != nil {
+		log.Printf("⚠️ Invalid COURSE_PRICE_BANDS, using %s: %v", defaultPriceBands, err)
+		boundaries, _ = parsePriceBands(defaultPriceBands)
+	}
+
+	zero := 0.0
+	bands := []repository.PriceBand{{Key: "free", UpTo: &zero}}
+	above := &zero
+	for i := range boundaries {
+		bands = append(bands, repository.PriceBand{
+			Key:   formatPrice(*above) + "-" + formatPrice(boundaries[i]),
+			Above: above,
+			UpTo:  &boundaries[i],
+		})
+		above = &boundaries[i]
+	}
+	bands = append(bands, repository.PriceBand{Key: formatPrice(*above) + "+", Above: above})
+
+	return repository.CourseFacets{Fields: catalogFacetFields, PriceBands: bands}
+}
+
+// parsePriceBands - Ascending, positive boundaries from a comma-separated list
+func parsePriceBands(list string) ([]float64, error) {
+	var boundaries []float64
+	for _, part := range strings.Split(list, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if value <= 0 || (len(boundaries) > 0 && value <= boundaries[len(boundaries)-1]) {
+			return nil, fmt.Errorf("boundaries must be positive and ascending, got %s", list)
+		}
+		boundaries = append(boundaries, value)
+	}
+	return boundaries, nil
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+func sortableCourseFieldNames() []string {
+	names := make([]string, 0, len(sortableCourseFields))
+	for name := range sortableCourseFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// respondCatalogPage writes one page of courses with its position in the
+// whole listing, and the facet counts if there are any. courses may hold the
+// extra course asked for by parseCatalogRequest; it is dropped here.
+func respondCatalogPage(c *gin.Context, request *catalogRequest, courses []bson.M, total int64, facets map[string][]repository.FacetCount) {
+	hasMore := int64(len(courses)) > request.limit
+	if hasMore {
+		courses = courses[:request.limit]
+	}
+
+	// "id" is the public identifier; _id stays internal
+	for _, course := range courses {
+		delete(course, "_id")
+	}
+
+	response := gin.H{
+		"courses":    courses,
+		"count":      len(courses),
+		"total":      total,
+		"limit":      request.limit,
+		"hasMore":    hasMore,
+		"nextCursor": nil,
+	}
+	if hasMore {
+		response["nextCursor"] = repository.CursorAfter(courses[len(courses)-1], request.query.Sort).Encode()
+	}
+	if request.page > 0 {
+		response["page"] = request.page
+		response["totalPages"] = (total + request.limit - 1) / request.limit
+	}
+	if facets != nil {
+		response["facets"] = facets
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func respondCatalogErrors(c *gin.Context, errs models.FieldErrors) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Invalid catalog query",
+		"fields": errs,
+	})
+}
+
+const (
+	maxSearchQueryLength = 200
+	maxSearchPageSize    = 50
+	defaultSuggestions   = 8
+	maxSuggestions       = 20
+)
+
+// SearchCourses handles GET /courses/search?q= (&page= &limit=) - active
+// courses ranked by how well they match, with highlighted snippets
+func SearchCourses(c *gin.Context) {
+	errs := models.FieldErrors{}
+	query := parseSearchText(c, errs)
+	page := parseCatalogInt(c, errs, "page", 1, 1, -1)
+	limit := parseCatalogInt(c, errs, "limit", defaultCoursePageSize, 1, maxSearchPageSize)
+	if len(errs) > 0 {
+		respondCatalogErrors(c, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hits, total, err := servicesimpl.NewCourseSearchService().Search(ctx, query, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search courses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":      query,
+		"results":    hits,
+		"count":      len(hits),
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+		"totalPages": (total + limit - 1) / limit,
+		"hasMore":    page*limit < total,
+	})
+}
+
+// SuggestCourses handles GET /courses/suggest?q= (&limit=) - autocomplete
+// for the search box: matching course titles and word completions
+func SuggestCourses(c *gin.Context) {
+	errs := models.FieldErrors{}
+	query := parseSearchText(c, errs)
+	limit := parseCatalogInt(c, errs, "limit", defaultSuggestions, 1, maxSuggestions)
+	if len(errs) > 0 {
+		respondCatalogErrors(c, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	suggestions, completions, err := servicesimpl.NewCourseSearchService().Suggest(ctx, query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":       query,
+		"suggestions": suggestions,
+		"completions": completions,
+	})
+}
+
+// parseSearchText - The required ?q= of a search
+func parseSearchText(c *gin.Context, errs models.FieldErrors) string {
+	query := strings.TrimSpace(c.Query("q"))
+	switch {
+	case query == "":
+		errs["q"] = "is required"
+	case len(query) > maxSearchQueryLength:
+		errs["q"] = fmt.Sprintf("must be at most %d characters", maxSearchQueryLength)
+	}
+	return query
+}
+
+// parseCatalogInt - An optional whole number parameter within [min, max]
+// (max < 0: no upper bound), or fallback when it is absent
+func parseCatalogInt(c *gin.Context, errs models.FieldErrors, param string, fallback, min, max int) int {
+	raw := c.Query(param)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || (max >= 0 && value > max) {
+		if max >= 0 {
+			errs[param] = fmt.Sprintf("must be a whole number from %d to %d", min, max)
+		} else {
+			errs[param] = fmt.Sprintf("must be a whole number from %d", min)
+		}
+		return fallback
+	}
+	return value
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -9,111 +11,52 @@ import (
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive" 
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-	"github.com/AbaraEmmanuel/jaromind-backend/database"
 	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
 )
 
-// Helper functions to get collections
-func getCoursesCollection() *mongo.Collection {
-	return database.DB.Collection("courses")
-}
-
-func getEnrollmentsCollection() *mongo.Collection {
-	return database.DB.Collection("enrollments")
-}
+// GetAllCourses - One page of the published courses, filtered and sorted (see parseCatalogRequest)
+func GetAllCourses(c *gin.Context) {
+	request, errs := parseCatalogRequest(c)
+	if errs != nil {
+		respondCatalogErrors(c, errs)
+		return
+	}
 
-func getReviewsCollection() *mongo.Collection {
-	return database.DB.Collection("reviews")
-}
+	// Drafts, courses in review and archived courses are never listed here
+	request.query.Match["status"] = models.CourseStatusPublished
 
-// GetAllCourses - Get all active courses with optional filters
-// GetAllCourses - Get all active courses with optional filters
-func GetAllCourses(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Build filter
-	filter := bson.M{"isActive": true}
-
-	// Optional filters
-	if courseType := c.Query("type"); courseType != "" {
-		filter["type"] = courseType
-	}
-	if classLevel := c.Query("classLevel"); classLevel != "" {
-		filter["classLevel"] = classLevel
-	}
-	if subject := c.Query("subject"); subject != "" {
-		filter["subject"] = subject
-	}
-	if status := c.Query("status"); status != "" {
-		filter["status"] = status
-	}
-	if category := c.Query("category"); category != "" {
-		filter["category"] = category
-	}
-	if featured := c.Query("featured"); featured == "true" {
-		filter["isFeatured"] = true
-	}
-
-	// Sorting
-	sortBy := c.DefaultQuery("sortBy", "createdAt")
-	order := c.DefaultQuery("order", "desc")
-	sortOrder := 1
-	if order == "desc" {
-		sortOrder = -1
+	var (
+		courses []bson.M
+		facets  map[string][]repository.FacetCount
+		total   int64
+		err     error
+	)
+	if request.facets {
+		total, facets, err = repository.Default().Courses.QueryFacets(ctx, request.query, catalogFacets(), &courses)
+	} else {
+		total, err = repository.Default().Courses.Query(ctx, request.query, &courses)
 	}
-
-	opts := options.Find().SetSort(bson.D{{Key: sortBy, Value: sortOrder}})
-
-	cursor, err := getCoursesCollection().Find(ctx, filter, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courses"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	// FIXED: Use bson.M and ensure consistent ID field
-	var rawCourses []bson.M
-	if err = cursor.All(ctx, &rawCourses); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse courses"})
-		return
-	}
+	respondCatalogPage(c, request, courses, total, facets)
+}
 
-	// Process to ensure consistent structure
-	var courses []bson.M
-	for _, rawCourse := range rawCourses {
-		course := bson.M{}
-		
-		// Copy all fields
-		for key, value := range rawCourse {
-			course[key] = value
-		}
-		
-		// Ensure id field is correct
-		// If course has id field, use it
-		if id, exists := rawCourse["id"]; exists && id != "" {
-			course["id"] = id
-		} else {
-			// If no id field, create from _id
-			if mongoID, exists := rawCourse["_id"]; exists {
-				if objID, ok := mongoID.(primitive.ObjectID); ok {
-					course["id"] = objID.Hex()
-				}
-			}
-		}
-		
-		// Remove _id field to avoid confusion
-		delete(course, "_id")
-		
-		courses = append(courses, course)
+// respondCourseLookupError - 404 for unknown courses, 500 for anything else
+func respondCourseLookupError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrCourseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"courses": courses,
-		"count":   len(courses),
-	})
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load course"})
 }
 
 // GetCourseByID - Get single course with full details
@@ -123,39 +66,17 @@ func GetCourseByID(c *gin.Context) {
 
 	courseID := c.Param("id")
 	
-	// Try to find by id field first
 	var course bson.M
-	err := getCoursesCollection().FindOne(ctx, bson.M{"id": courseID, "isActive": true}).Decode(&course)
-	
-	// If not found by id field, try by _id (for backward compatibility)
-	if err != nil {
-		// Try to convert to ObjectID
-		if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-			err = getCoursesCollection().FindOne(ctx, bson.M{"_id": objID, "isActive": true}).Decode(&course)
-		}
-	}
-	
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if err := repository.Default().Courses.Find(ctx, courseID, repository.Fields{"status": models.CourseStatusPublished}, &course); err != nil {
+		respondCourseLookupError(c, err)
 		return
 	}
-
-	// Ensure id field is present in response
-	if _, exists := course["id"]; !exists {
-		if mongoID, exists := course["_id"]; exists {
-			if objID, ok := mongoID.(primitive.ObjectID); ok {
-				course["id"] = objID.Hex()
-			}
-		}
-	}
 	
-	// Remove _id field
+	// Remove _id field; "id" is the public identifier
 	delete(course, "_id")
 
-	// Get reviews for this course
-	cursor, _ := getReviewsCollection().Find(ctx, bson.M{"courseId": courseID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(10))
-	var reviews []models.Review
-	cursor.All(ctx, &reviews)
+	// Get the latest reviews for this course
+	reviews, _ := repository.Default().Reviews.ListByCourse(ctx, courseID, 10)
 
 	c.JSON(http.StatusOK, gin.H{
 		"course":  course,
@@ -170,12 +91,12 @@ func CreateCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Parse incoming JSON into bson.M to handle flexible structure
-	var courseData bson.M
-	if err := c.ShouldBindJSON(&courseData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Only the fields of models.CourseInput can be written, and they are validated
+	input, ok := bindCourseInput(c, true)
+	if !ok {
 		return
 	}
+	courseData := input.Fields()
 
 	// Generate a new UUID for the course
 	courseID := uuid.New().String()
@@ -184,25 +105,32 @@ func CreateCourse(c *gin.Context) {
 	courseData["id"] = courseID
 	courseData["createdAt"] = time.Now()
 	courseData["updatedAt"] = time.Now()
-	courseData["isActive"] = true
+
+	// New courses go through review before they are published
+	courseData["status"] = models.CourseStatusDraft
+	courseData["isActive"] = false
 	
-	// Set defaults if not provided
-	if _, exists := courseData["enrollmentCount"]; !exists {
-		courseData["enrollmentCount"] = 0
-	}
-	if _, exists := courseData["rating"]; !exists {
-		courseData["rating"] = 0.0
-	}
-	if _, exists := courseData["reviewCount"]; !exists {
-		courseData["reviewCount"] = 0
-	}
+	// Counters start at zero; they are maintained by enrollments and reviews
+	courseData["enrollmentCount"] = 0
+	courseData["rating"] = 0.0
+	courseData["reviewCount"] = 0
 	if _, exists := courseData["lessonCount"]; !exists {
 		courseData["lessonCount"] = 0
 	}
 
+	// Courses linked to a tutor account show that tutor's profile
+	if err := servicesimpl.NewCourseAuthoringService().LinkTutor(ctx, courseData); err != nil {
+		if errors.Is(err, services.ErrTutorNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
+		return
+	}
+
 	// Insert the course
-	_, err := getCoursesCollection().InsertOne(ctx, courseData)
-	if err != nil {
+	if _, err := servicesimpl.NewCourseRevisionService().Insert(ctx, courseActor(c), courseData); err != nil {
+		log.Printf("❌ Failed to create course %s: %v", courseID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
 		return
 	}
@@ -213,80 +141,81 @@ func CreateCourse(c *gin.Context) {
 	})
 }
 
-// UpdateCourse - Admin only
+// UpdateCourse - Admin only. Every change is kept as a revision (see ListCourseRevisions).
 func UpdateCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	courseID := c.Param("id")
 
-	// Parse updates
-	var updates bson.M
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Parse updates - only the fields of models.CourseInput, validated
+	input, ok := bindCourseInput(c, false)
+	if !ok {
+		return
+	}
+	updates := input.Fields()
+	if len(updates) == 0 {
+		respondFieldErrors(c, models.FieldErrors{"body": "must contain at least one field"})
 		return
 	}
-
-	// Don't allow updating the ID
-	delete(updates, "id")
-	delete(updates, "_id")
 	
 	// Add updated timestamp
 	updates["updatedAt"] = time.Now()
 	
-	// Build update document
-	updateDoc := bson.M{"$set": updates}
-
-	// Try to update by id field
-	result, err := getCoursesCollection().UpdateOne(ctx, bson.M{"id": courseID}, updateDoc)
-	
-	// If not found by id, try by _id
-	if err != nil || result.MatchedCount == 0 {
-		if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-			result, err = getCoursesCollection().UpdateOne(ctx, bson.M{"_id": objID}, updateDoc)
+	// Linking a tutor account copies its profile; an empty tutorId unlinks it
+	var unset []string
+	if tutorID, exists := updates["tutorId"]; exists {
+		if tutorID == "" {
+			delete(updates, "tutorId")
+			unset = append(unset, "tutorId")
+		} else if err := servicesimpl.NewCourseAuthoringService().LinkTutor(ctx, updates); err != nil {
+			if errors.Is(err, services.ErrTutorNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
+			return
 		}
 	}
-	
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
-		return
-	}
 
-	if result.MatchedCount == 0 {
+	_, err := servicesimpl.NewCourseRevisionService().Update(ctx, courseActor(c), models.CourseRevisionUpdate, courseID, nil, updates, unset...)
+	if errors.Is(err, services.ErrCourseNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
 		return
 	}
+	if err != nil {
+		log.Printf("❌ Failed to update course %s: %v", courseID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Course updated successfully"})
 }
 
-// DeleteCourse - Soft delete
+// DeleteCourse - Soft delete: archives the course whatever its status
 func DeleteCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	courseID := c.Param("id")
 	
-	// Try to delete by id field
-	result, err := getCoursesCollection().UpdateOne(ctx, bson.M{"id": courseID}, bson.M{"$set": bson.M{"isActive": false}})
-	
-	// If not found by id, try by _id
-	if err != nil || result.MatchedCount == 0 {
-		if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-			result, err = getCoursesCollection().UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"isActive": false}})
-		}
+	now := time.Now()
+	_, err := servicesimpl.NewCourseRevisionService().Update(ctx, courseActor(c), models.CourseRevisionDelete, courseID, nil, bson.M{
+		"status":     models.CourseStatusArchived,
+		"isActive":   false,
+		"archivedAt": now,
+		"updatedAt":  now,
+	}, "scheduledPublishAt", "scheduledUnpublishAt")
+	if errors.Is(err, services.ErrCourseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+		return
 	}
-	
 	if err != nil {
+		log.Printf("❌ Failed to delete course %s: %v", courseID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete course"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{"message": "Course deleted successfully"})
 }
 
@@ -296,33 +225,28 @@ func EnrollInCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	courseID := c.Param("courseId")
-	userID, exists := c.Get("userID")
+	courseID := c.Param("id")
+	userID := c.GetString("userID")
 	
-	if !exists {
+	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
+	store := repository.Default()
+
 	// Check if course exists
 	var course models.Course
-	err := getCoursesCollection().FindOne(ctx, bson.M{"id": courseID, "isActive": true}).Decode(&course)
+	err := store.Courses.Find(ctx, courseID, repository.Fields{"status": models.CourseStatusPublished}, &course)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-
-	// Check if already enrolled
-	count, _ := getEnrollmentsCollection().CountDocuments(ctx, bson.M{"userId": userID, "courseId": courseID})
-	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Already enrolled in this course"})
+		respondCourseLookupError(c, err)
 		return
 	}
 
-	// Create enrollment
+	// Create enrollment (one per student and course)
 	enrollment := models.Enrollment{
 		ID:               uuid.New().String(),
-		UserID:           userID.(string),
+		UserID:           userID,
 		CourseID:         courseID,
 		EnrolledAt:       time.Now(),
 		LastAccessedAt:   time.Now(),
@@ -332,14 +256,18 @@ func EnrollInCourse(c *gin.Context) {
 		UpdatedAt:        time.Now(),
 	}
 
-	_, err = getEnrollmentsCollection().InsertOne(ctx, enrollment)
+	err = store.Enrollments.Insert(ctx, &enrollment)
+	if errors.Is(err, services.ErrAlreadyEnrolled) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Already enrolled in this course"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll in course"})
 		return
 	}
 
 	// Increment enrollment count
-	getCoursesCollection().UpdateOne(ctx, bson.M{"id": courseID}, bson.M{"$inc": bson.M{"enrollmentCount": 1}})
+	store.Courses.Increment(ctx, courseID, "enrollmentCount", 1)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message":    "Successfully enrolled",
@@ -352,28 +280,26 @@ func GetUserEnrollments(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	userID, exists := c.Get("userID")
+	userID := c.GetString("userID")
 	
-	if !exists {
+	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	cursor, err := getEnrollmentsCollection().Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.D{{Key: "lastAccessedAt", Value: -1}}))
+	store := repository.Default()
+
+	enrollments, err := store.Enrollments.ListByUser(ctx, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollments"})
 		return
 	}
-	defer cursor.Close(ctx)
-
-	var enrollments []models.Enrollment
-	cursor.All(ctx, &enrollments)
 
 	// Get course details for each enrollment
 	var enrollmentDetails []gin.H
 	for _, enrollment := range enrollments {
 		var course models.Course
-		err := getCoursesCollection().FindOne(ctx, bson.M{"id": enrollment.CourseID}).Decode(&course)
+		err := store.Courses.Find(ctx, enrollment.CourseID, nil, &course)
 		if err == nil {
 			enrollmentDetails = append(enrollmentDetails, gin.H{
 				"enrollment": enrollment,
@@ -393,10 +319,10 @@ func UpdateProgress(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	courseID := c.Param("courseId")
-	userID, exists := c.Get("userID")
+	courseID := c.Param("id")
+	userID := c.GetString("userID")
 	
-	if !exists {
+	if userID == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
@@ -412,20 +338,22 @@ func UpdateProgress(c *gin.Context) {
 	}
 
 	update := bson.M{
-		"$set": bson.M{
-			"progress":         request.Progress,
-			"completedLessons": request.CompletedLessons,
-			"lastAccessedAt":   time.Now(),
-			"updatedAt":        time.Now(),
-		},
+		"progress":         request.Progress,
+		"completedLessons": request.CompletedLessons,
+		"lastAccessedAt":   time.Now(),
+		"updatedAt":        time.Now(),
 	}
 
 	// If course is 100% complete
 	if request.Progress >= 100 {
-		update["$set"].(bson.M)["completedAt"] = time.Now()
+		update["completedAt"] = time.Now()
 	}
 
-	_, err := getEnrollmentsCollection().UpdateOne(ctx, bson.M{"userId": userID, "courseId": courseID}, update)
+	err := repository.Default().Enrollments.Update(ctx, userID, courseID, update)
+	if errors.Is(err, services.ErrNotEnrolled) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not enrolled in this course"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update progress"})
 		return
@@ -448,9 +376,16 @@ func AddReview(c *gin.Context) {
         return
     }
 
+    userIDStr, ok := userID.(string)
+    if !ok {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        return
+    }
+
+    store := repository.Default()
+
     // Check if user is enrolled
-    count, _ := getEnrollmentsCollection().CountDocuments(ctx, bson.M{"userId": userID, "courseId": courseID})
-    if count == 0 {
+    if _, err := store.Enrollments.Find(ctx, userIDStr, courseID); err != nil {
         c.JSON(http.StatusForbidden, gin.H{"error": "Must be enrolled to review"})
         return
     }
@@ -468,12 +403,6 @@ func AddReview(c *gin.Context) {
     userName := c.GetString("userName")
     // userAvatar := c.GetString("userAvatar")
 
-    userIDStr, ok := userID.(string)
-    if !ok {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-        return
-    }
-
     // Convert user ID string to ObjectID
     userObjectID, err := primitive.ObjectIDFromHex(userIDStr)
     if err != nil {
@@ -494,14 +423,18 @@ func AddReview(c *gin.Context) {
         UpdatedAt:  time.Now(),
     }
 
-    _, err = getReviewsCollection().InsertOne(ctx, review)
+    err = store.Reviews.Insert(ctx, &review)
+    if errors.Is(err, services.ErrReviewExists) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "You have already reviewed this course"})
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add review"})
         return
     }
 
     // Update course rating
-    updateCourseRating(courseID)
+    updateCourseRating(ctx, courseID)
 
     c.JSON(http.StatusCreated, gin.H{
         "message": "Review added successfully",
@@ -510,44 +443,18 @@ func AddReview(c *gin.Context) {
 }
 
 // Helper function to recalculate course rating
-func updateCourseRating(courseID string) {
-    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-    defer cancel()
-
-    cursor, _ := getReviewsCollection().Find(ctx, bson.M{"course_id": courseID}) // Use string comparison
-    var reviews []models.Review
-    cursor.All(ctx, &reviews)
+func updateCourseRating(ctx context.Context, courseID string) {
+    store := repository.Default()
 
-    if len(reviews) == 0 {
+    avgRating, reviewCount, err := store.Reviews.Rating(ctx, courseID)
+    if err != nil || reviewCount == 0 {
         return
     }
 
-    var totalRating int
-    for _, review := range reviews {
-        totalRating += review.Rating
-    }
-
-    avgRating := float64(totalRating) / float64(len(reviews))
-    
-    // Try to update by id field first (for UUIDs)
-    _, err := getCoursesCollection().UpdateOne(ctx, bson.M{"id": courseID}, bson.M{
-        "$set": bson.M{
-            "rating":      avgRating,
-            "reviewCount": len(reviews),
-        },
+    store.Courses.Update(ctx, courseID, nil, bson.M{
+        "rating":      avgRating,
+        "reviewCount": reviewCount,
     })
-    
-    // If not found by id, try by _id (for ObjectIDs)
-    if err != nil {
-        if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-            getCoursesCollection().UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
-                "$set": bson.M{
-                    "rating":      avgRating,
-                    "reviewCount": len(reviews),
-                },
-            })
-        }
-    }
 }
 
 // GetCourseStats - Get statistics for a course
@@ -557,19 +464,11 @@ func GetCourseStats(c *gin.Context) {
 
 	courseID := c.Param("id")
 
-	// Try to find by id field
+	store := repository.Default()
+
 	var course bson.M
-	err := getCoursesCollection().FindOne(ctx, bson.M{"id": courseID}).Decode(&course)
-	
-	// If not found, try by _id
-	if err != nil {
-		if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-			err = getCoursesCollection().FindOne(ctx, bson.M{"_id": objID}).Decode(&course)
-		}
-	}
-	
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if err := store.Courses.Find(ctx, courseID, repository.Fields{"status": models.CourseStatusPublished}, &course); err != nil {
+		respondCourseLookupError(c, err)
 		return
 	}
 
@@ -583,10 +482,7 @@ func GetCourseStats(c *gin.Context) {
 		}
 	}
 
-	completionCount, _ := getEnrollmentsCollection().CountDocuments(ctx, bson.M{
-		"courseId": courseID,
-		"completedAt": bson.M{"$ne": nil},
-	})
+	completionCount, _ := store.Enrollments.CountCompleted(ctx, courseID)
 
 	completionRate := 0.0
 	if enrollmentCount > 0 {
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// bindCourseInput decodes a course payload, rejecting fields that can't be
+// written, and validates it. On failure it writes a 400 with per-field errors.
+func bindCourseInput(c *gin.Context, creating bool) (*models.CourseInput, bool) {
+	var input models.CourseInput
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		respondFieldErrors(c, decodeFieldErrors(err))
+		return nil, false
+	}
+
+	if errs := input.Validate(creating); errs != nil {
+		respondFieldErrors(c, errs)
+		return nil, false
+	}
+	return &input, true
+}
+
+// decodeFieldErrors turns a JSON decoding error into field errors where possible
+func decodeFieldErrors(err error) models.FieldErrors {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return models.FieldErrors{typeErr.Field: "must be " + jsonTypeName(typeErr.Type)}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		if field == "status" || field == "isActive" {
+			return models.FieldErrors{field: "is changed through the review workflow, see /admin/courses/:id/status"}
+		}
+		return models.FieldErrors{field: "is not an editable field"}
+	case errors.Is(err, io.EOF):
+		return models.FieldErrors{"body": "is required"}
+	}
+	return models.FieldErrors{"body": "must be a valid JSON object"}
+}
+
+// jsonTypeName - How a Go type looks in JSON, for error messages
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+func respondFieldErrors(c *gin.Context, errs models.FieldErrors) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   "Invalid course data",
+		"fields":  errs,
+	})
+}
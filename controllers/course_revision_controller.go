@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+const (
+	defaultRevisionPageSize = 20
+	maxRevisionPageSize     = 100
+)
+
+// ListCourseRevisions handles GET /admin/courses/:id/revisions - the changes
+// to a course, newest first, each with the fields before and after.
+// ?before= (the nextBefore of the previous page) &limit=
+func ListCourseRevisions(c *gin.Context) {
+	errs := models.FieldErrors{}
+	before := parseCatalogInt(c, errs, "before", 0, 1, -1)
+	limit := parseCatalogInt(c, errs, "limit", defaultRevisionPageSize, 1, maxRevisionPageSize)
+	if len(errs) > 0 {
+		respondFieldErrors(c, errs)
+		return
+	}
+
+	revisions, err := servicesimpl.NewCourseRevisionService().List(c.Request.Context(), c.Param("id"), before, limit)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+
+	response := gin.H{"success": true, "revisions": revisions, "count": len(revisions), "nextBefore": nil}
+	if len(revisions) == limit && revisions[len(revisions)-1].Number > 1 {
+		response["nextBefore"] = revisions[len(revisions)-1].Number
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RollbackCourse handles POST /admin/courses/:id/revisions/:number/rollback -
+// restores the course as it was at that revision. The status and schedule
+// are left alone; use the review workflow for those.
+func RollbackCourse(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number < 1 {
+		respondTutorError(c, services.ErrRevisionNotFound)
+		return
+	}
+
+	revision, err := servicesimpl.NewCourseRevisionService().Rollback(c.Request.Context(), courseActor(c), c.Param("id"), number)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"message":  "Course rolled back to revision " + strconv.Itoa(number),
+		"revision": revision,
+	})
+}
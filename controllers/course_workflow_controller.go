@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// courseActor - The signed-in admin or tutor acting on a course
+func courseActor(c *gin.Context) services.CourseActor {
+	return services.CourseActor{
+		ID:    middleware.CurrentUserID(c),
+		Email: middleware.CurrentUserEmail(c),
+		Role:  middleware.CurrentRole(c),
+	}
+}
+
+// ============================================
+// REVIEW WORKFLOW (ADMIN)
+// ============================================
+
+// ListAdminCourses handles GET /admin/courses - the catalog listing with
+// every status, for reviewers (?status=in_review for the review queue)
+func ListAdminCourses(c *gin.Context) {
+	request, errs := parseCatalogRequest(c)
+	if errs != nil {
+		respondCatalogErrors(c, errs)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		courses []bson.M
+		facets  map[string][]repository.FacetCount
+		total   int64
+		err     error
+	)
+	if request.facets {
+		total, facets, err = repository.Default().Courses.QueryFacets(ctx, request.query, catalogFacets(), &courses)
+	} else {
+		total, err = repository.Default().Courses.Query(ctx, request.query, &courses)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to fetch courses"})
+		return
+	}
+
+	respondCatalogPage(c, request, courses, total, facets)
+}
+
+type ChangeCourseStatusRequest struct {
+	Status  string `json:"status" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+// ChangeCourseStatus handles POST /admin/courses/:id/status - moves a course
+// through the workflow (models.CourseTransitions). Sending a course in review
+// back to draft needs a comment for the tutor.
+func ChangeCourseStatus(c *gin.Context) {
+	var request ChangeCourseStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(request.Status))
+	if !models.IsCourseStatus(status) {
+		respondFieldErrors(c, models.FieldErrors{"status": "must be one of " + strings.Join(models.CourseStatuses, ", ")})
+		return
+	}
+
+	err := servicesimpl.NewCourseAuthoringService().Transition(c.Request.Context(), courseActor(c), c.Param("id"), status, request.Comment)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Course moved to " + status, "status": status})
+}
+
+// ScheduleCourse handles PUT /admin/courses/:id/schedule - sets when the
+// course is published and archived by the scheduler; a missing or null time
+// clears it
+func ScheduleCourse(c *gin.Context) {
+	var schedule services.CourseSchedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "publishAt and unpublishAt must be RFC 3339 times"})
+		return
+	}
+
+	err := servicesimpl.NewCourseAuthoringService().Schedule(c.Request.Context(), courseActor(c), c.Param("id"), schedule)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Schedule updated", "schedule": schedule})
+}
+
+// ============================================
+// REVIEWER COMMENTS (ADMIN AND TUTOR)
+// ============================================
+
+type CourseCommentRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+// ListCourseComments handles GET /admin/courses/:id/comments and
+// GET /tutor/courses/:id/comments - notes and status changes, newest first
+func ListCourseComments(c *gin.Context) {
+	comments, err := servicesimpl.NewCourseAuthoringService().ListComments(c.Request.Context(), courseActor(c), c.Param("id"))
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "comments": comments, "count": len(comments)})
+}
+
+// AddCourseComment handles POST /admin/courses/:id/comments and
+// POST /tutor/courses/:id/comments
+func AddCourseComment(c *gin.Context) {
+	var request CourseCommentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondFieldErrors(c, models.FieldErrors{"comment": "is required"})
+		return
+	}
+
+	comment, err := servicesimpl.NewCourseAuthoringService().AddComment(c.Request.Context(), courseActor(c), c.Param("id"), request.Comment)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "comment": comment})
+}
+
+// ============================================
+// SUBMITTING FOR REVIEW (TUTOR)
+// ============================================
+
+// SubmitTutorCourse handles POST /tutor/courses/:id/submit - sends one of the
+// tutor's drafts to the reviewers, with an optional note
+func SubmitTutorCourse(c *gin.Context) {
+	changeTutorCourseStatus(c, models.CourseStatusInReview, "Course submitted for review")
+}
+
+// WithdrawTutorCourse handles POST /tutor/courses/:id/withdraw - takes one of
+// the tutor's courses out of review so it can be edited again
+func WithdrawTutorCourse(c *gin.Context) {
+	changeTutorCourseStatus(c, models.CourseStatusDraft, "Course withdrawn from review")
+}
+
+func changeTutorCourseStatus(c *gin.Context, status, message string) {
+	var request struct {
+		Comment string `json:"comment"`
+	}
+	// The body is optional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+	}
+
+	err := servicesimpl.NewCourseAuthoringService().Transition(c.Request.Context(), courseActor(c), c.Param("id"), status, request.Comment)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": message, "status": status})
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ImpersonateUser handles POST /admin/impersonate/:userId. Returns a short-lived
+// token that acts as the student; every request made with it is audited.
+func ImpersonateUser(c *gin.Context) {
+	var request ImpersonateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": services.ErrImpersonationReason.Error()})
+		return
+	}
+
+	token, err := servicesimpl.NewImpersonationService().Start(
+		c.Request.Context(),
+		middleware.CurrentUserID(c),
+		middleware.CurrentUserEmail(c),
+		c.Param("userId"),
+		request.Reason,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImpersonationReason):
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		case errors.Is(err, services.ErrAccountPendingDeletion):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to start impersonation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "impersonation": token})
+}
+
+// ListAuditLogs handles GET /admin/audit-logs (?action=, ?actorId=, ?subjectId=, ?limit=)
+func ListAuditLogs(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 64)
+
+	entries, err := servicesimpl.NewAuditService().List(c.Request.Context(), services.AuditFilter{
+		Action:    c.Query("action"),
+		ActorID:   c.Query("actorId"),
+		SubjectID: c.Query("subjectId"),
+		Limit:     limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to load audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"auditLogs": entries,
+		"count":     len(entries),
+	})
+}
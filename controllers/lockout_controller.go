@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// loginAllowed - Rejects the request with 429 while the account or client IP is locked out
+func loginAllowed(c *gin.Context, accountType, email string) bool {
+	retryAfter, err := servicesimpl.NewLoginGuardService().Check(c.Request.Context(), accountType, email, c.ClientIP())
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Header("Retry-After", fmt.Sprint(seconds))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"success":    false,
+			"error":      err.Error(),
+			"retryAfter": seconds,
+		})
+		return false
+	}
+
+	// Don't lock everyone out because the counter store is unavailable
+	log.Printf("⚠️ Login guard check failed: %v", err)
+	return true
+}
+
+// recordLoginFailure - Counts a wrong email/password against the account and client IP
+func recordLoginFailure(c *gin.Context, accountType, email string) {
+	if err := servicesimpl.NewLoginGuardService().RecordFailure(c.Request.Context(), accountType, email, c.ClientIP()); err != nil {
+		log.Printf("⚠️ Failed to record login failure: %v", err)
+	}
+}
+
+// recordLoginSuccess - Resets the account counter once tokens are issued
+func recordLoginSuccess(c *gin.Context, accountType, email string) {
+	if err := servicesimpl.NewLoginGuardService().RecordSuccess(c.Request.Context(), accountType, email); err != nil {
+		log.Printf("⚠️ Failed to reset login counter: %v", err)
+	}
+}
+
+// ListLockouts handles GET /admin/lockouts (?all=true includes counters that are not locked)
+func ListLockouts(c *gin.Context) {
+	lockouts, err := servicesimpl.NewLoginGuardService().List(c.Request.Context(), c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to load lockouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"lockouts":   lockouts,
+		"count":      len(lockouts),
+		"serverTime": time.Now(),
+	})
+}
+
+// ClearLockout handles DELETE /admin/lockouts/:id
+func ClearLockout(c *gin.Context) {
+	err := servicesimpl.NewLoginGuardService().Clear(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrLockoutNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to clear lockout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Lockout cleared"})
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code"`
+}
+
+// respondMFAChallenge - Password step passed; the client must now call /login/mfa
+func respondMFAChallenge(c *gin.Context, result *models.LoginResult) {
+	message := "Enter the code from your authenticator app"
+	if result.MFAEnrollRequired {
+		message = "Two-factor authentication must be set up before you can log in"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"message":           message,
+		"mfaRequired":       true,
+		"mfaEnrollRequired": result.MFAEnrollRequired,
+		"mfaToken":          result.MFAToken,
+	})
+}
+
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrInvalidMFAChallenge):
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, services.ErrMFANotEnabled), errors.Is(err, services.ErrMFAAlreadyEnabled),
+		errors.Is(err, services.ErrMFANotEnrolling):
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, services.ErrMFARequired):
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": err.Error()})
+	case errors.Is(err, services.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"success": false, "error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Two-factor request failed"})
+	}
+}
+
+// currentAccountType - students/tutors vs admins, from the token role
+func currentAccountType(c *gin.Context) string {
+	return models.AccountTypeForRole(middleware.CurrentRole(c))
+}
+
+// ============================================
+// LOGIN STEP 2 (PUBLIC)
+// ============================================
+
+// CompleteMFALogin handles POST /login/mfa - exchanges mfaToken + code for tokens
+func CompleteMFALogin(c *gin.Context) {
+	var request MFAChallengeRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "mfaToken and code are required"})
+		return
+	}
+
+	tokens, err := servicesimpl.NewMFAService().CompleteLogin(c.Request.Context(), request.MFAToken, request.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+	recordSessionDevice(c, tokens)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"message":      "Login successfully",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// EnrollMFAWithChallenge handles POST /login/mfa/enroll - setup for accounts that must enroll to log in
+func EnrollMFAWithChallenge(c *gin.Context) {
+	var request MFAChallengeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	enrollment, err := servicesimpl.NewMFAService().EnrollWithChallenge(c.Request.Context(), request.MFAToken)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Scan the QR code, save your recovery codes, then confirm with a code at /login/mfa",
+		"enrollment": enrollment,
+	})
+}
+
+// ============================================
+// SELF-SERVICE (AUTHENTICATED)
+// ============================================
+
+// GetMFAStatus handles GET /user/2fa and /admin/2fa
+func GetMFAStatus(c *gin.Context) {
+	mfa := servicesimpl.NewMFAService()
+	accountType := currentAccountType(c)
+
+	enabled, err := mfa.IsEnabled(c.Request.Context(), accountType, middleware.CurrentUserID(c))
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"enabled":  enabled,
+		"required": mfa.IsRequired(accountType),
+	})
+}
+
+// SetupMFA handles POST /user/2fa/setup and /admin/2fa/setup
+func SetupMFA(c *gin.Context) {
+	enrollment, err := servicesimpl.NewMFAService().BeginEnrollment(c.Request.Context(), currentAccountType(c), middleware.CurrentUserID(c))
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"message":    "Scan the QR code, save your recovery codes, then confirm with a code",
+		"enrollment": enrollment,
+	})
+}
+
+// EnableMFA handles POST /user/2fa/enable and /admin/2fa/enable
+func EnableMFA(c *gin.Context) {
+	var request MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	err := servicesimpl.NewMFAService().ConfirmEnrollment(c.Request.Context(), currentAccountType(c), middleware.CurrentUserID(c), request.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Two-factor authentication enabled"})
+}
+
+// DisableMFA handles POST /user/2fa/disable and /admin/2fa/disable
+func DisableMFA(c *gin.Context) {
+	var request MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	err := servicesimpl.NewMFAService().Disable(c.Request.Context(), currentAccountType(c), middleware.CurrentUserID(c), request.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Two-factor authentication disabled"})
+}
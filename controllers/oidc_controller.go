@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// ListOIDCProviders handles GET /auth/oidc/providers - for rendering "Sign in with ..." buttons
+func ListOIDCProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": servicesimpl.NewOIDCService().Providers()})
+}
+
+// oidcNonceCookie - Binds a social login to the browser that started it
+const oidcNonceCookie = "oidc_nonce"
+
+// StartOIDCLogin handles GET /auth/oidc/:provider/start. Redirects the browser
+// to the provider, or returns the URL with ?format=json (call it with
+// credentials so the browser keeps the nonce cookie).
+func StartOIDCLogin(c *gin.Context) {
+	authorization, err := servicesimpl.NewOIDCService().Begin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	// Lax, so the cookie comes along on the provider's top-level redirect back
+	setOIDCNonceCookie(c, authorization.BrowserNonce, int(time.Until(authorization.ExpiresAt).Seconds()))
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{"authorizationUrl": authorization.URL})
+		return
+	}
+	c.Redirect(http.StatusFound, authorization.URL)
+}
+
+// setOIDCNonceCookie - Scoped to the provider's routes; maxAge < 0 deletes it
+func setOIDCNonceCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcNonceCookie,
+		Value:    value,
+		Path:     "/auth/oidc/" + strings.ToLower(c.Param("provider")),
+		MaxAge:   maxAge,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// OIDCCallback handles GET /auth/oidc/:provider/callback, where the provider
+// sends the browser back. With OIDC_FRONTEND_CALLBACK_URL set, the result is
+// passed to the frontend in the URL fragment (never sent to servers);
+// otherwise it is returned as JSON.
+func OIDCCallback(c *gin.Context) {
+	// The nonce is single-use like the state it is bound to
+	browserNonce, _ := c.Cookie(oidcNonceCookie)
+	setOIDCNonceCookie(c, "", -1)
+
+	if providerError := c.Query("error"); providerError != "" {
+		respondOIDCResult(c, nil, fmt.Errorf("%w: %s", services.ErrOIDCLoginFailed, providerError))
+		return
+	}
+
+	result, err := servicesimpl.NewOIDCService().Complete(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"), browserNonce)
+	respondOIDCResult(c, result, err)
+}
+
+func respondOIDCResult(c *gin.Context, result *models.LoginResult, err error) {
+	if err == nil {
+		recordSessionDevice(c, result.Tokens)
+	}
+
+	frontendURL := os.Getenv("OIDC_FRONTEND_CALLBACK_URL")
+	if frontendURL == "" {
+		if err != nil {
+			respondOIDCError(c, err)
+			return
+		}
+		if result.MFARequired {
+			respondMFAChallenge(c, result)
+			return
+		}
+		tokens := result.Tokens
+		c.JSON(http.StatusOK, gin.H{
+			"message":      "Login successfully",
+			"token":        tokens.AccessToken,
+			"refreshToken": tokens.RefreshToken,
+			"tokenType":    tokens.TokenType,
+			"expiresIn":    tokens.ExpiresIn,
+		})
+		return
+	}
+
+	fragment := url.Values{}
+	switch {
+	case err != nil:
+		_, message := oidcErrorStatus(err)
+		fragment.Set("error", message)
+	case result.MFARequired:
+		fragment.Set("mfaRequired", "true")
+		fragment.Set("mfaToken", result.MFAToken)
+		if result.MFAEnrollRequired {
+			fragment.Set("mfaEnrollRequired", "true")
+		}
+	default:
+		fragment.Set("token", result.Tokens.AccessToken)
+		fragment.Set("refreshToken", result.Tokens.RefreshToken)
+		fragment.Set("tokenType", result.Tokens.TokenType)
+		fragment.Set("expiresIn", fmt.Sprint(result.Tokens.ExpiresIn))
+	}
+	c.Redirect(http.StatusFound, frontendURL+"#"+fragment.Encode())
+}
+
+// oidcErrorStatus maps OIDC errors to a status code and a message safe to show
+func oidcErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		return http.StatusNotFound, services.ErrUnknownProvider.Error()
+	case errors.Is(err, services.ErrInvalidOIDCState):
+		return http.StatusBadRequest, services.ErrInvalidOIDCState.Error()
+	case errors.Is(err, services.ErrAccountPendingDeletion):
+		return http.StatusForbidden, services.ErrAccountPendingDeletion.Error()
+	case errors.Is(err, services.ErrOIDCEmailNotVerified):
+		return http.StatusForbidden, services.ErrOIDCEmailNotVerified.Error()
+	case errors.Is(err, services.ErrOIDCLoginFailed):
+		return http.StatusBadGateway, services.ErrOIDCLoginFailed.Error()
+	}
+	return http.StatusInternalServerError, "Something went wrong, please try again"
+}
+
+func respondOIDCError(c *gin.Context, err error) {
+	status, message := oidcErrorStatus(err)
+	c.JSON(status, gin.H{"error": message})
+}
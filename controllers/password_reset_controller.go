@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ForgotPassword handles POST /forgot-password
+func ForgotPassword(c *gin.Context) {
+	forgotPassword(c, models.AccountTypeStudent)
+}
+
+// ResetPassword handles POST /reset-password
+func ResetPassword(c *gin.Context) {
+	resetPassword(c, models.AccountTypeStudent)
+}
+
+// AdminForgotPassword handles POST /admin/forgot-password
+func AdminForgotPassword(c *gin.Context) {
+	forgotPassword(c, models.AccountTypeAdmin)
+}
+
+// AdminResetPassword handles POST /admin/reset-password
+func AdminResetPassword(c *gin.Context) {
+	resetPassword(c, models.AccountTypeAdmin)
+}
+
+// TutorForgotPassword handles POST /tutor/forgot-password
+func TutorForgotPassword(c *gin.Context) {
+	forgotPassword(c, models.AccountTypeTutor)
+}
+
+// TutorResetPassword handles POST /tutor/reset-password (also accepts invitations)
+func TutorResetPassword(c *gin.Context) {
+	resetPassword(c, models.AccountTypeTutor)
+}
+
+func forgotPassword(c *gin.Context, accountType string) {
+	var request ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Always answer the same way so the endpoint can't be used to discover accounts
+	if err := servicesimpl.NewPasswordResetService().RequestReset(c.Request.Context(), accountType, request.Email); err != nil {
+		log.Printf("⚠️ Password reset request failed for %s: %v", accountType, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an account exists for this email, a password reset link has been sent.",
+	})
+}
+
+func resetPassword(c *gin.Context, accountType string) {
+	var request ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := servicesimpl.NewPasswordResetService().ResetPassword(c.Request.Context(), accountType, request.Token, request.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password has been reset. Please log in with your new password.",
+	})
+}
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+    "errors"
     "net/http"
 	"fmt"
+    "github.com/AbaraEmmanuel/jaromind-backend/middleware"
     "github.com/AbaraEmmanuel/jaromind-backend/models"
+    "github.com/AbaraEmmanuel/jaromind-backend/repository"
+    "github.com/AbaraEmmanuel/jaromind-backend/services"
     "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
     "github.com/gin-gonic/gin"
     "go.mongodb.org/mongo-driver/bson/primitive"
@@ -25,23 +29,8 @@ func NewReviewController() *ReviewController {
 func CreateReview(ctx *gin.Context) {
     fmt.Println("\n=== CREATE REVIEW - START ===")
     
-    // Try multiple key names
-    var userID string
-    var found bool
-    
-    keys := []string{"userID", "user_id", "userId", "user"}
-    for _, key := range keys {
-        if val, exists := ctx.Get(key); exists {
-            fmt.Printf("✓ Found key '%s': %v (type: %T)\n", key, val, val)
-            if str, ok := val.(string); ok {
-                userID = str
-                found = true
-                break
-            }
-        }
-    }
-    
-    if !found {
+    userID := middleware.CurrentUserID(ctx)
+    if userID == "" {
         fmt.Println("✗ ERROR: No user ID found in context")
         ctx.JSON(http.StatusUnauthorized, models.ReviewResponse{
             Success: false,
@@ -88,6 +77,11 @@ func CreateReview(ctx *gin.Context) {
         return
     }
     
+    // Reviews must point at a published course, by its public id
+    if !requirePublishedCourse(ctx, courseID) {
+        return
+    }
+    
     // Initialize service
     fmt.Println("✓ Initializing review service...")
     reviewService := services_impl.NewReviewServiceImpl()
@@ -151,6 +145,26 @@ func CreateReview(ctx *gin.Context) {
     })
 }
 
+// requirePublishedCourse - Reviews are only shown and taken for published
+// courses. Otherwise it responds 404 and returns false.
+func requirePublishedCourse(ctx *gin.Context, courseID string) bool {
+    var course models.Course
+    err := repository.Default().Courses.Find(ctx.Request.Context(), courseID, repository.Fields{"status": models.CourseStatusPublished}, &course)
+    if err == nil {
+        return true
+    }
+
+    status, message := http.StatusInternalServerError, "Failed to load course"
+    if errors.Is(err, services.ErrCourseNotFound) {
+        status, message = http.StatusNotFound, "Course not found"
+    }
+    ctx.JSON(status, models.ReviewResponse{
+        Success: false,
+        Message: message,
+    })
+    return false
+}
+
 // GetCourseReviews handles GET /courses/:courseId/reviews
 func GetCourseReviews(ctx *gin.Context) {
     reviewService := services_impl.NewReviewServiceImpl()
@@ -164,6 +178,10 @@ func GetCourseReviews(ctx *gin.Context) {
         return
     }
 
+    if !requirePublishedCourse(ctx, courseID) {
+        return
+    }
+
     reviews, err := reviewService.GetReviewsByCourseID(ctx.Request.Context(), courseID)
     if err != nil {
         ctx.JSON(http.StatusInternalServerError, models.ReviewResponse{
@@ -179,11 +197,11 @@ func GetCourseReviews(ctx *gin.Context) {
     })
 }
 
-// GetCourseRating handles GET /courses/:courseId/rating
+// GetCourseRating handles GET /courses/:id/rating
 func GetCourseRating(ctx *gin.Context) {
     reviewService := services_impl.NewReviewServiceImpl()
     
-    courseID := ctx.Param("courseId")
+    courseID := ctx.Param("id")
     if courseID == "" {
         ctx.JSON(http.StatusBadRequest, models.ReviewResponse{
             Success: false,
@@ -192,6 +210,10 @@ func GetCourseRating(ctx *gin.Context) {
         return
     }
 
+    if !requirePublishedCourse(ctx, courseID) {
+        return
+    }
+
     avgRating, totalReviews, err := reviewService.CalculateCourseRating(ctx.Request.Context(), courseID)
     if err != nil {
         ctx.JSON(http.StatusInternalServerError, models.ReviewResponse{
@@ -245,8 +267,8 @@ func UpdateReview(ctx *gin.Context) {
     reviewService := services_impl.NewReviewServiceImpl()
     
     // Get user from context
-    userInterface, exists := ctx.Get("user")
-    if !exists {
+    userIDStr := middleware.CurrentUserID(ctx)
+    if userIDStr == "" {
         ctx.JSON(http.StatusUnauthorized, models.ReviewResponse{
             Success: false,
             Message: "Unauthorized",
@@ -254,15 +276,6 @@ func UpdateReview(ctx *gin.Context) {
         return
     }
 
-    user, ok := userInterface.(map[string]interface{})
-    if !ok {
-        ctx.JSON(http.StatusUnauthorized, models.ReviewResponse{
-            Success: false,
-            Message: "Invalid user data",
-        })
-        return
-    }
-
     // Get review ID
     reviewID := ctx.Param("reviewId")
     if reviewID == "" {
@@ -287,16 +300,6 @@ func UpdateReview(ctx *gin.Context) {
         return
     }
 
-    // Get user ID
-    userIDStr := ""
-    if id, ok := user["id"].(string); ok {
-        userIDStr = id
-    } else if id, ok := user["_id"].(string); ok {
-        userIDStr = id
-    } else if id, ok := user["_id"].(primitive.ObjectID); ok {
-        userIDStr = id.Hex()
-    }
-
     // Check if user is the author
     if existingReview.UserID.Hex() != userIDStr {
         ctx.JSON(http.StatusForbidden, models.ReviewResponse{
@@ -341,8 +344,8 @@ func DeleteReview(ctx *gin.Context) {
     reviewService := services_impl.NewReviewServiceImpl()
     
     // Get user from context
-    userInterface, exists := ctx.Get("user")
-    if !exists {
+    userIDStr := middleware.CurrentUserID(ctx)
+    if userIDStr == "" {
         ctx.JSON(http.StatusUnauthorized, models.ReviewResponse{
             Success: false,
             Message: "Unauthorized",
@@ -350,15 +353,6 @@ func DeleteReview(ctx *gin.Context) {
         return
     }
 
-    user, ok := userInterface.(map[string]interface{})
-    if !ok {
-        ctx.JSON(http.StatusUnauthorized, models.ReviewResponse{
-            Success: false,
-            Message: "Invalid user data",
-        })
-        return
-    }
-
     // Get review ID
     reviewID := ctx.Param("reviewId")
     if reviewID == "" {
@@ -383,19 +377,8 @@ func DeleteReview(ctx *gin.Context) {
         return
     }
 
-    // Get user ID
-    userIDStr := ""
-    if id, ok := user["id"].(string); ok {
-        userIDStr = id
-    } else if id, ok := user["_id"].(string); ok {
-        userIDStr = id
-    } else if id, ok := user["_id"].(primitive.ObjectID); ok {
-        userIDStr = id.Hex()
-    }
-
-    // Check if user is the author (or admin - add admin check if needed)
-    if existingReview.UserID.Hex() != userIDStr {
-        // Add admin check here if you have role-based access
+    // Check if user is the author (or can moderate reviews)
+    if existingReview.UserID.Hex() != userIDStr && !models.HasPermission(middleware.CurrentRole(ctx), models.PermReviewModerate) {
         ctx.JSON(http.StatusForbidden, models.ReviewResponse{
             Success: false,
             Message: "You can only delete your own reviews",
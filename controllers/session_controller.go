@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// recordSessionDevice - Stores the user agent and IP of a freshly issued token pair's session
+func recordSessionDevice(c *gin.Context, tokens *models.TokenPair) {
+	if tokens == nil || tokens.SessionID == "" {
+		return
+	}
+	err := servicesimpl.NewSessionService().Touch(c.Request.Context(), tokens.SessionID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Printf("⚠️ Failed to record session device: %v", err)
+	}
+}
+
+// ListSessions handles GET /user/sessions and /admin/sessions - the caller's signed-in devices
+func ListSessions(c *gin.Context) {
+	sessions, err := servicesimpl.NewSessionService().ListForAccount(c.Request.Context(), currentAccountType(c), middleware.CurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to load sessions"})
+		return
+	}
+
+	current := middleware.CurrentSessionID(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.Hex() == current
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeSession handles DELETE /user/sessions/:id and /admin/sessions/:id. Tokens
+// of that session stop working immediately, including the caller's own.
+func RevokeSession(c *gin.Context) {
+	err := servicesimpl.NewSessionService().RevokeForAccount(c.Request.Context(), currentAccountType(c), middleware.CurrentUserID(c), c.Param("id"), "signed out from another device")
+	if err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to sign out session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Session signed out"})
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// RefreshToken handles POST /token/refresh
+func RefreshToken(c *gin.Context) {
+	var request RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := servicesimpl.NewSessionService().Refresh(c.Request.Context(), request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	recordSessionDevice(c, tokens)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// Logout handles POST /logout - revokes the presented access token and its session
+func Logout(c *gin.Context) {
+	jti := middleware.CurrentTokenID(c)
+	sessionID := middleware.CurrentSessionID(c)
+	expiresAt := middleware.CurrentTokenExpiresAt(c)
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(24 * time.Hour)
+	}
+
+	if err := servicesimpl.NewSessionService().Logout(c.Request.Context(), jti, sessionID, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
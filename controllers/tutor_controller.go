@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+)
+
+// tutorErrorStatus maps tutor, authoring and revision errors to HTTP status codes
+func tutorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTutorNotFound), errors.Is(err, services.ErrCourseNotFound),
+		errors.Is(err, services.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTutorExists), errors.Is(err, services.ErrCourseNotEditable),
+		errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrRevisionUnchanged):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrCommentRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrTutorDeactivated):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
+
+func respondTutorError(c *gin.Context, err error) {
+	var fieldErrs models.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		respondFieldErrors(c, fieldErrs)
+		return
+	}
+
+	status := tutorErrorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = "Something went wrong, please try again"
+	}
+	c.JSON(status, gin.H{"success": false, "error": message})
+}
+
+// ============================================
+// TUTOR LOGIN (PUBLIC)
+// ============================================
+
+// TutorLogin handles POST /tutor/login
+func TutorLogin(c *gin.Context) {
+	var request struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid input format"})
+		return
+	}
+
+	if !loginAllowed(c, models.AccountTypeTutor, request.Email) {
+		return
+	}
+
+	result, err := servicesimpl.NewTutorService().Login(c.Request.Context(), request.Email, request.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			recordLoginFailure(c, models.AccountTypeTutor, request.Email)
+		}
+		respondTutorError(c, err)
+		return
+	}
+
+	if result.MFARequired {
+		respondMFAChallenge(c, result)
+		return
+	}
+
+	recordLoginSuccess(c, models.AccountTypeTutor, request.Email)
+	tokens := result.Tokens
+	recordSessionDevice(c, tokens)
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// ============================================
+// TUTOR SELF-SERVICE
+// ============================================
+
+type UpdateTutorProfileRequest struct {
+	Name        *string `json:"name"`
+	Bio         *string `json:"bio"`
+	Avatar      *string `json:"avatar"`
+	Expertise   *string `json:"expertise"`
+	YearsExp    *int    `json:"yearsExp" binding:"omitempty,min=0"`
+	Credentials *string `json:"credentials"`
+}
+
+func (r UpdateTutorProfileRequest) toUpdate() services.TutorUpdate {
+	return services.TutorUpdate{
+		Name:        r.Name,
+		Bio:         r.Bio,
+		Avatar:      r.Avatar,
+		Expertise:   r.Expertise,
+		YearsExp:    r.YearsExp,
+		Credentials: r.Credentials,
+	}
+}
+
+// GetTutorProfile handles GET /tutor/profile
+func GetTutorProfile(c *gin.Context) {
+	tutor, err := servicesimpl.NewTutorService().GetByID(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tutor": tutor})
+}
+
+// UpdateTutorProfile handles PATCH /tutor/profile. The display fields are
+// copied onto every course the tutor teaches.
+func UpdateTutorProfile(c *gin.Context) {
+	var request UpdateTutorProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	tutor, err := servicesimpl.NewTutorService().Update(c.Request.Context(), middleware.CurrentUserID(c), request.toUpdate())
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tutor": tutor})
+}
+
+// ListTutorCourses handles GET /tutor/courses - the tutor's courses, drafts included
+func ListTutorCourses(c *gin.Context) {
+	courses, err := servicesimpl.NewCourseAuthoringService().ListByTutor(c.Request.Context(), middleware.CurrentUserID(c))
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "courses": courses, "count": len(courses)})
+}
+
+// CreateTutorCourse handles POST /tutor/courses - always creates a draft
+func CreateTutorCourse(c *gin.Context) {
+	input, ok := bindCourseInput(c, true)
+	if !ok {
+		return
+	}
+
+	course, err := servicesimpl.NewCourseAuthoringService().CreateDraft(c.Request.Context(), middleware.CurrentUserID(c), input)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Draft created, submit it for review when it is ready",
+		"course":  course,
+	})
+}
+
+// UpdateTutorCourse handles PUT /tutor/courses/:id - only the tutor's own drafts
+func UpdateTutorCourse(c *gin.Context) {
+	input, ok := bindCourseInput(c, false)
+	if !ok {
+		return
+	}
+
+	err := servicesimpl.NewCourseAuthoringService().UpdateDraft(c.Request.Context(), middleware.CurrentUserID(c), c.Param("id"), input)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Draft updated"})
+}
+
+// ============================================
+// TUTOR MANAGEMENT (ADMIN)
+// ============================================
+
+// ListTutors handles GET /admin/tutors
+func ListTutors(c *gin.Context) {
+	tutors, err := servicesimpl.NewTutorService().List(c.Request.Context())
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tutors": tutors, "count": len(tutors)})
+}
+
+type InviteTutorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Name  string `json:"name" binding:"required"`
+}
+
+// InviteTutor handles POST /admin/tutors - creates the account and emails a set-password link
+func InviteTutor(c *gin.Context) {
+	var request InviteTutorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	tutor, err := servicesimpl.NewTutorService().Invite(c.Request.Context(), request.Email, request.Name, middleware.CurrentUserID(c))
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Invitation sent", "tutor": tutor})
+}
+
+type UpdateTutorRequest struct {
+	UpdateTutorProfileRequest
+	IsActive *bool `json:"isActive"`
+}
+
+// UpdateTutor handles PATCH /admin/tutors/:id - edit the profile or (de)activate
+func UpdateTutor(c *gin.Context) {
+	var request UpdateTutorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	update := request.toUpdate()
+	update.IsActive = request.IsActive
+	tutor, err := servicesimpl.NewTutorService().Update(c.Request.Context(), c.Param("id"), update)
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tutor": tutor})
+}
+
+// PublishCourse handles POST /admin/courses/:id/publish - publishes a course in review
+func PublishCourse(c *gin.Context) {
+	err := servicesimpl.NewCourseAuthoringService().Transition(c.Request.Context(), courseActor(c), c.Param("id"), models.CourseStatusPublished, "")
+	if err != nil {
+		respondTutorError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Course published"})
+}
@@ -2,19 +2,17 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
-	"fmt"
-	"os"
+	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/AbaraEmmanuel/jaromind-backend/models"
-	"github.com/AbaraEmmanuel/jaromind-backend/database"
-	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
 	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
-	
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"golang.org/x/crypto/bcrypt" // ADD THIS
 )
@@ -66,40 +64,195 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
-	token, err := servicesimpl.NewUserService().Login(request.Email, request.Password)
+	if !loginAllowed(c, models.AccountTypeStudent, request.Email) {
+		return
+	}
+
+	result, err := servicesimpl.NewUserService().Login(request.Email, request.Password)
 	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "verificationRequired": true})
+			return
+		}
+		if errors.Is(err, services.ErrAccountPendingDeletion) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			recordLoginFailure(c, models.AccountTypeStudent, request.Email)
+		}
 		c.JSON(401, gin.H{"error": err.Error()})
 		return
 	}
 
+	if result.MFARequired {
+		respondMFAChallenge(c, result)
+		return
+	}
+
+	recordLoginSuccess(c, models.AccountTypeStudent, request.Email)
+	tokens := result.Tokens
+	recordSessionDevice(c, tokens)
 	c.JSON(200, gin.H{
-		"message": "Login successfully",
-		"token":   token,
+		"message":      "Login successfully",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
 	})
 }
 
+type VerifyEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// VerifyEmail handles POST /verify-email
+func VerifyEmail(c *gin.Context) {
+	var request VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := servicesimpl.NewUserService().VerifyEmail(request.Email, request.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCode), errors.Is(err, services.ErrAlreadyVerified):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResendVerification handles POST /verify-email/resend
+func ResendVerification(c *gin.Context) {
+	var request ResendVerificationRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := servicesimpl.NewUserService().ResendVerification(request.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an unverified account exists for this email, a new code has been sent.",
+	})
+}
+
+// respondProfileError maps profile errors to HTTP status codes
+func respondProfileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIncorrectPassword):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong, please try again"})
+	}
+}
+
+// GetProfile handles GET /user/profile
 func GetProfile(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	email, _ := c.Get("email")
-	role, _ := c.Get("role")
+	user, err := servicesimpl.NewUserService().GetProfile(middleware.CurrentUserID(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user_id": userID,
-		"email":   email,
-		"role":    role,
+		"user": user,
+		"role": middleware.CurrentRole(c),
+	})
+}
+
+type UpdateProfileRequest struct {
+	Name        *string                 `json:"name"`
+	Avatar      *string                 `json:"avatar"`
+	ClassLevel  *string                 `json:"class_level"`
+	Language    *string                 `json:"language"`
+	Preferences *models.UserPreferences `json:"preferences"`
+}
+
+// UpdateProfile handles PATCH /user/profile - only the fields sent are changed
+func UpdateProfile(c *gin.Context) {
+	var request UpdateProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := servicesimpl.NewUserService().UpdateProfile(middleware.CurrentUserID(c), services.ProfileUpdate{
+		Name:        request.Name,
+		Avatar:      request.Avatar,
+		ClassLevel:  request.ClassLevel,
+		Language:    request.Language,
+		Preferences: request.Preferences,
+	})
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profile updated",
+		"user":    user,
+	})
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePassword handles PUT /user/password. Other sessions are signed out and
+// the caller gets a new token pair, since the old token stops working.
+func ChangePassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := servicesimpl.NewUserService().ChangePassword(middleware.CurrentUserID(c), request.CurrentPassword, request.NewPassword)
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	recordSessionDevice(c, tokens)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Password changed",
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
 	})
 }
 
 func AdminLogin(c *gin.Context) {
-	fmt.Println("🔧 AdminLogin called")
-	
 	var loginData struct {
 		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&loginData); err != nil {
-		fmt.Println("❌ Bind error:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "Invalid input format",
@@ -107,58 +260,20 @@ func AdminLogin(c *gin.Context) {
 		return
 	}
 
-	fmt.Println("📧 Attempting admin login for:", loginData.Email)
-
-	// Get database connection
-	client := database.Client
-	if client == nil {
-		fmt.Println("❌ Database client is nil")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Database not connected",
-		})
+	if !loginAllowed(c, models.AccountTypeAdmin, loginData.Email) {
 		return
 	}
-	
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	
-	// Use the database name from your .env
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "emmanuelabara265_db_user" // Default fallback
-	}
-	fmt.Printf("Using database: %s\n", dbName)
-	
-	db := client.Database(dbName)
-	adminsCollection := db.Collection("admins")
-	
 	// First, check if the admin exists
-	var admin bson.M
-	err := adminsCollection.FindOne(ctx, bson.M{"email": loginData.Email}).Decode(&admin)
+	admin, err := repository.Default().Admins.FindByEmail(ctx, loginData.Email)
 	if err != nil {
-		fmt.Printf("❌ Admin not found with email '%s': %v\n", loginData.Email, err)
-		
-		// Debug: Show all admins that exist
-		fmt.Println("\n📋 Checking all admins in database:")
-		cursor, err := adminsCollection.Find(ctx, bson.M{})
-		if err != nil {
-			fmt.Printf("❌ Error finding admins: %v\n", err)
-		} else {
-			defer cursor.Close(ctx)
-			foundAny := false
-			for cursor.Next(ctx) {
-				foundAny = true
-				var result bson.M
-				cursor.Decode(&result)
-				fmt.Printf("  - Email: %v, Name: %v\n", 
-					result["email"], result["name"])
-			}
-			if !foundAny {
-				fmt.Println("  No admins found in collection!")
-			}
+		if !errors.Is(err, services.ErrAdminNotFound) {
+			log.Printf("❌ Admin lookup failed: %v", err)
 		}
-		
+		recordLoginFailure(c, models.AccountTypeAdmin, loginData.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"error":   "Invalid email or password",
@@ -166,32 +281,11 @@ func AdminLogin(c *gin.Context) {
 		return
 	}
 	
-	fmt.Printf("✅ Admin found: %v\n", admin)
-	
-	// Check if admin is active
-	if isActive, ok := admin["isActive"].(bool); ok && !isActive {
-		fmt.Println("❌ Admin account is deactivated")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "Admin account is deactivated",
-		})
-		return
-	}
-	
-	// Verify password
-	passwordHash, ok := admin["password"].(string)
-	if !ok {
-		fmt.Println("❌ Password field missing or not string")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Invalid admin data",
-		})
-		return
-	}
-	
-	err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(loginData.Password))
-	if err != nil {
-		fmt.Println("❌ Password mismatch")
+	// Verify password. A deactivated admin gets the same answer as a wrong
+	// password, checked after it so the response doesn't confirm the password
+	err = bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(loginData.Password))
+	if err != nil || !admin.IsActive {
+		recordLoginFailure(c, models.AccountTypeAdmin, loginData.Email)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"error":   "Invalid email or password",
@@ -199,42 +293,25 @@ func AdminLogin(c *gin.Context) {
 		return
 	}
 	
-	fmt.Println("✅ Password verified")
-	
-	// Get admin ID - FIXED
-	var adminID primitive.ObjectID
-	if id, ok := admin["_id"].(primitive.ObjectID); ok {
-		adminID = id
-	} else {
-		fmt.Printf("❌ Cannot get admin ID, type: %T\n", admin["_id"])
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Invalid admin ID format",
-		})
-		return
-	}
-	
-	// Get admin email - FIXED
-	adminEmail, ok := admin["email"].(string)
-	if !ok {
-		fmt.Println("❌ Email field missing or not string")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Invalid admin data",
-		})
-		return
-	}
+	adminID := admin.ID
+	adminEmail := admin.Email
 	
 	// Get admin name
 	adminName := "Admin"
-	if name, ok := admin["name"].(string); ok && name != "" {
-		adminName = name
+	if admin.Name != "" {
+		adminName = admin.Name
 	}
 	
-	// Generate token - FIXED: Use adminID and adminEmail variables
-	token, err := utils.GenerateAdminJWT(adminID.Hex(), adminEmail)
+	// Get admin role (documents created before the role model have none)
+	adminRole := models.RoleSuperAdmin
+	if admin.Role != "" {
+		adminRole = models.NormalizeRole(admin.Role)
+	}
+
+	// Start a session, or a 2FA challenge
+	result, err := servicesimpl.NewMFAService().StartLogin(ctx, models.AccountTypeAdmin, adminID.Hex(), adminEmail, adminRole)
 	if err != nil {
-		fmt.Println("❌ Token generation failed:", err)
+		log.Printf("❌ Admin login failed to start a session: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to generate token",
@@ -242,30 +319,27 @@ func AdminLogin(c *gin.Context) {
 		return
 	}
 	
-	fmt.Println("✅ Token generated")
+	if result.MFARequired {
+		respondMFAChallenge(c, result)
+		return
+	}
+
+	recordLoginSuccess(c, models.AccountTypeAdmin, adminEmail)
+	tokens := result.Tokens
+	recordSessionDevice(c, tokens)
 	
 	// Return success
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"token":   token,
+		"success":      true,
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"tokenType":    tokens.TokenType,
+		"expiresIn":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":    adminID.Hex(),
 			"email": adminEmail,
 			"name":  adminName,
-			"role":  "admin",
+			"role":  adminRole,
 		},
 	})
-	
-	fmt.Println("🎉 Admin login successful!")
 }
-
-
-// Helper function
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
\ No newline at end of file
@@ -0,0 +1,106 @@
+package database
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// Indexes of every collection, applied at startup (see EnsureIndexes). Unique
+// indexes back the "already exists" checks, which are racy on their own.
+func init() {
+	// Accounts
+	RegisterIndexes("students",
+		IndexSpec{Name: "email_unique", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+		IndexSpec{Name: "identities", Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}}},
+		IndexSpec{Name: "deletion_scheduled_at", Keys: bson.D{{Key: "deletion_scheduled_at", Value: 1}}},
+	)
+	RegisterIndexes("admins",
+		IndexSpec{Name: "email_unique", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+	)
+	RegisterIndexes("tutors",
+		IndexSpec{Name: "email_unique", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+	)
+
+	// Courses and what students do with them
+	RegisterIndexes("courses",
+		IndexSpec{Name: "id_unique", Keys: bson.D{{Key: "id", Value: 1}}, Unique: true},
+		IndexSpec{Name: "tutor", Keys: bson.D{{Key: "tutorId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		IndexSpec{Name: "status_created", Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		IndexSpec{Name: "scheduled_publish", Keys: bson.D{{Key: "status", Value: 1}, {Key: "scheduledPublishAt", Value: 1}}},
+		IndexSpec{Name: "scheduled_unpublish", Keys: bson.D{{Key: "status", Value: 1}, {Key: "scheduledUnpublishAt", Value: 1}}},
+		IndexSpec{
+			Name: "text",
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "subject", Value: "text"},
+				{Key: "subjects", Value: "text"},
+				{Key: "category", Value: "text"},
+				{Key: "tags", Value: "text"},
+				{Key: "description", Value: "text"},
+				{Key: "longDescription", Value: "text"},
+			},
+			Weights: bson.D{
+				{Key: "title", Value: 10},
+				{Key: "subject", Value: 5},
+				{Key: "subjects", Value: 5},
+				{Key: "category", Value: 5},
+				{Key: "tags", Value: 5},
+				{Key: "description", Value: 2},
+			},
+		},
+	)
+	RegisterIndexes("course_comments",
+		IndexSpec{Name: "course_created", Keys: bson.D{{Key: "courseId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	)
+	RegisterIndexes("course_revisions",
+		IndexSpec{Name: "course_number_unique", Keys: bson.D{{Key: "courseId", Value: 1}, {Key: "number", Value: -1}}, Unique: true},
+	)
+	RegisterIndexes("enrollments",
+		IndexSpec{Name: "user_course_unique", Keys: bson.D{{Key: "userId", Value: 1}, {Key: "courseId", Value: 1}}, Unique: true},
+		IndexSpec{Name: "course", Keys: bson.D{{Key: "courseId", Value: 1}}},
+	)
+	RegisterIndexes("reviews",
+		// Reviews of purged students have no user_id and stay out of it
+		IndexSpec{
+			Name:    "user_course_unique",
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "course_id", Value: 1}},
+			Unique:  true,
+			Partial: bson.D{{Key: "user_id", Value: bson.D{{Key: "$exists", Value: true}}}},
+		},
+		IndexSpec{Name: "course_created", Keys: bson.D{{Key: "course_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		IndexSpec{Name: "text", Keys: bson.D{{Key: "comment", Value: "text"}}},
+	)
+
+	// Sessions and tokens; expired entries are removed by the server
+	RegisterIndexes("sessions",
+		IndexSpec{Name: "refresh_token", Keys: bson.D{{Key: "refreshTokenHash", Value: 1}}},
+		IndexSpec{Name: "rotated_tokens", Keys: bson.D{{Key: "rotatedTokenHashes", Value: 1}}},
+		IndexSpec{Name: "account", Keys: bson.D{{Key: "accountType", Value: 1}, {Key: "accountId", Value: 1}}},
+		IndexSpec{Name: "expires_ttl", Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAt: true},
+	)
+	RegisterIndexes("revoked_tokens",
+		IndexSpec{Name: "jti_unique", Keys: bson.D{{Key: "jti", Value: 1}}, Unique: true},
+		IndexSpec{Name: "expires_ttl", Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAt: true},
+	)
+	RegisterIndexes("password_resets",
+		IndexSpec{Name: "token_unique", Keys: bson.D{{Key: "tokenHash", Value: 1}}, Unique: true},
+		IndexSpec{Name: "expires_ttl", Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAt: true},
+	)
+	RegisterIndexes("oidc_states",
+		IndexSpec{Name: "state_unique", Keys: bson.D{{Key: "stateHash", Value: 1}}, Unique: true},
+		IndexSpec{Name: "expires_ttl", Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAt: true},
+	)
+	RegisterIndexes("login_attempts",
+		IndexSpec{Name: "key_unique", Keys: bson.D{{Key: "key", Value: 1}}, Unique: true},
+		IndexSpec{Name: "expires_ttl", Keys: bson.D{{Key: "expiresAt", Value: 1}}, ExpireAt: true},
+	)
+	RegisterIndexes("api_keys",
+		IndexSpec{Name: "prefix_unique", Keys: bson.D{{Key: "prefix", Value: 1}}, Unique: true},
+		IndexSpec{Name: "owner", Keys: bson.D{{Key: "ownerId", Value: 1}}},
+	)
+
+	// Audit trail, newest first for each filter of /admin/audit-logs
+	RegisterIndexes("audit_logs",
+		IndexSpec{Name: "created", Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		IndexSpec{Name: "action_created", Keys: bson.D{{Key: "action", Value: 1}, {Key: "createdAt", Value: -1}}},
+		IndexSpec{Name: "actor_created", Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		IndexSpec{Name: "subject_created", Keys: bson.D{{Key: "subjectId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	)
+}
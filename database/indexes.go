@@ -0,0 +1,350 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexSpec - One declared index. Name identifies it in diffs; an index that
+// already exists with the same keys and options under another name counts too.
+type IndexSpec struct {
+	Name   string
+	Keys   bson.D // {field: 1|-1} or {field: "text"}
+	Unique bool
+
+	// ExpireAt makes this a TTL index: documents are removed once the date in
+	// the (single) key field has passed
+	ExpireAt bool
+
+	// Weights of the fields of a text index (default 1)
+	Weights bson.D
+
+	// Partial limits the index to the documents matching this filter
+	Partial bson.D
+}
+
+// textLanguageOverride - Documents may carry their own "language" field (courses
+// do, in free form), which text indexes would otherwise read as the stemming
+// language and reject unknown values. Point the override at an unused field.
+const textLanguageOverride = "textLanguage"
+
+// Index actions in a diff
+const (
+	IndexCreate    = "create"
+	IndexRecreate  = "recreate"
+	IndexUnchanged = "unchanged"
+	IndexExtra     = "extra" // exists in the database but isn't declared; never dropped
+)
+
+// IndexChange - One line of the diff between declared and existing indexes
+type IndexChange struct {
+	Collection string
+	Action     string
+	Name       string     // Declared name, or the existing name for extras
+	Existing   string     // Name of the existing index being replaced or matched
+	Spec       *IndexSpec // nil for extras
+}
+
+func (c IndexChange) String() string {
+	symbol := map[string]string{IndexCreate: "+", IndexRecreate: "~", IndexUnchanged: "=", IndexExtra: "?"}[c.Action]
+	line := fmt.Sprintf("%s %s.%s", symbol, c.Collection, c.Name)
+	if c.Spec != nil {
+		line += " " + c.Spec.describe()
+	}
+	if c.Existing != "" && c.Existing != c.Name {
+		line += fmt.Sprintf(" (existing %q)", c.Existing)
+	}
+	return line
+}
+
+// rebuildSuffix - Added to the name of a rebuilt index while the old one
+// still holds the declared name
+const rebuildSuffix = "_rebuilt"
+
+var indexRegistry = map[string][]IndexSpec{}
+
+// RegisterIndexes - Declares indexes of a collection; names must be unique per collection
+func RegisterIndexes(collection string, specs ...IndexSpec) {
+	for _, spec := range specs {
+		for _, existing := range indexRegistry[collection] {
+			if existing.Name == spec.Name {
+				panic(fmt.Sprintf("database: index %s.%s declared twice", collection, spec.Name))
+			}
+		}
+		indexRegistry[collection] = append(indexRegistry[collection], spec)
+	}
+}
+
+// PlanIndexes - Diff of the declared indexes against the database, by collection
+func PlanIndexes(ctx context.Context, db *mongo.Database) ([]IndexChange, error) {
+	collections := make([]string, 0, len(indexRegistry))
+	for name := range indexRegistry {
+		collections = append(collections, name)
+	}
+	sort.Strings(collections)
+
+	plan := []IndexChange{}
+	for _, collection := range collections {
+		existing, err := listIndexes(ctx, db.Collection(collection))
+		if err != nil {
+			return nil, fmt.Errorf("listing indexes of %s: %w", collection, err)
+		}
+
+		matched := map[string]bool{}
+		for i := range indexRegistry[collection] {
+			spec := &indexRegistry[collection][i]
+			change := IndexChange{Collection: collection, Action: IndexCreate, Name: spec.Name, Spec: spec}
+
+			for _, index := range existing {
+				if index.Name != spec.Name && index.keys() != spec.keys() {
+					continue
+				}
+				change.Existing = index.Name
+				change.Action = IndexRecreate
+				if index.keys() == spec.keys() && index.options() == spec.options() {
+					change.Action = IndexUnchanged
+				}
+				matched[index.Name] = true
+				break
+			}
+			plan = append(plan, change)
+		}
+
+		for _, index := range existing {
+			if !matched[index.Name] && index.Name != "_id_" {
+				plan = append(plan, IndexChange{Collection: collection, Action: IndexExtra, Name: index.Name, Existing: index.Name})
+			}
+		}
+	}
+	return plan, nil
+}
+
+// ApplyIndexes - Creates missing indexes and rebuilds changed ones. A failing
+// index (e.g. duplicates under a new unique index) doesn't stop the others;
+// all failures are returned together.
+func ApplyIndexes(ctx context.Context, db *mongo.Database, plan []IndexChange) error {
+	var errs []error
+	for _, change := range plan {
+		var err error
+		switch change.Action {
+		case IndexCreate:
+			_, err = db.Collection(change.Collection).Indexes().CreateOne(ctx, change.Spec.model())
+			if err != nil {
+				err = fmt.Errorf("creating %s.%s: %w", change.Collection, change.Name, err)
+			}
+		case IndexRecreate:
+			err = rebuildIndex(ctx, db.Collection(change.Collection), change)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Printf("🗂️ Index %s", change)
+	}
+	return errors.Join(errs...)
+}
+
+// rebuildIndex - Builds the declared index next to the existing one and only
+// then drops the old one, so queries are never left without it. When the name
+// is taken by the old index the new one gets a temporary name, which later
+// plans accept as the same index. Indexes the server won't keep side by side
+// (same keys with other options, a second text index) fail here and keep the
+// old index; those need dropping by hand before they can be rebuilt.
+func rebuildIndex(ctx context.Context, collection *mongo.Collection, change IndexChange) error {
+	spec := *change.Spec
+	if change.Existing == spec.Name {
+		spec.Name += rebuildSuffix
+	}
+
+	indexes := collection.Indexes()
+	if _, err := indexes.CreateOne(ctx, spec.model()); err != nil {
+		return fmt.Errorf("rebuilding %s.%s (kept existing %q): %w", change.Collection, change.Name, change.Existing, err)
+	}
+	if _, err := indexes.DropOne(ctx, change.Existing); err != nil {
+		return fmt.Errorf("dropping %s.%s after rebuilding it as %q: %w", change.Collection, change.Existing, spec.Name, err)
+	}
+	return nil
+}
+
+// EnsureIndexes - Plans the declared indexes and, unless dryRun, applies them.
+// Returns the plan either way.
+func EnsureIndexes(ctx context.Context, db *mongo.Database, dryRun bool) ([]IndexChange, error) {
+	plan, err := PlanIndexes(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		for _, change := range plan {
+			if change.Action != IndexUnchanged {
+				log.Printf("🗂️ Index diff: %s", change)
+			}
+		}
+		return plan, nil
+	}
+	return plan, ApplyIndexes(ctx, db, plan)
+}
+
+func (s IndexSpec) isText() bool {
+	for _, key := range s.Keys {
+		if key.Value == "text" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s IndexSpec) model() mongo.IndexModel {
+	opts := options.Index().SetName(s.Name)
+	if s.Unique {
+		opts.SetUnique(true)
+	}
+	if s.ExpireAt {
+		opts.SetExpireAfterSeconds(0)
+	}
+	if s.isText() {
+		opts.SetLanguageOverride(textLanguageOverride)
+		if len(s.Weights) > 0 {
+			opts.SetWeights(s.Weights)
+		}
+	}
+	if len(s.Partial) > 0 {
+		opts.SetPartialFilterExpression(s.Partial)
+	}
+	return mongo.IndexModel{Keys: s.Keys, Options: opts}
+}
+
+// keys - Comparable key pattern. The server stores text indexes as
+// {_fts: "text", _ftsx: 1} with the fields in the weights, so any text index
+// has the same pattern and they differ by options.
+func (s IndexSpec) keys() string {
+	if s.isText() {
+		return "text"
+	}
+	return formatKeys(s.Keys)
+}
+
+// options - Comparable options, including text weights
+func (s IndexSpec) options() string {
+	var weights bson.D
+	if s.isText() {
+		for _, key := range s.Keys {
+			if key.Value == "text" {
+				weights = append(weights, bson.E{Key: key.Key, Value: 1})
+			}
+		}
+		for _, w := range s.Weights {
+			for i := range weights {
+				if weights[i].Key == w.Key {
+					weights[i].Value = w.Value
+				}
+			}
+		}
+	}
+	language := ""
+	if s.isText() {
+		language = textLanguageOverride
+	}
+	return formatOptions(s.Unique, s.ExpireAt, weights, language, s.Partial)
+}
+
+func (s IndexSpec) describe() string {
+	parts := []string{formatKeys(s.Keys)}
+	if s.Unique {
+		parts = append(parts, "unique")
+	}
+	if s.ExpireAt {
+		parts = append(parts, "ttl")
+	}
+	if len(s.Weights) > 0 {
+		parts = append(parts, "weights "+formatKeys(s.Weights))
+	}
+	if len(s.Partial) > 0 {
+		parts = append(parts, "partial "+formatFilter(s.Partial))
+	}
+	return strings.Join(parts, " ")
+}
+
+// existingIndex - An index as returned by listIndexes
+type existingIndex struct {
+	Name                    string `bson:"name"`
+	Key                     bson.D `bson:"key"`
+	Unique                  bool   `bson:"unique"`
+	ExpireAfterSeconds      *int32 `bson:"expireAfterSeconds"`
+	Weights                 bson.D `bson:"weights"`
+	LanguageOverride        string `bson:"language_override"`
+	PartialFilterExpression bson.D `bson:"partialFilterExpression"`
+}
+
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]existingIndex, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []existingIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+func (i existingIndex) keys() string {
+	for _, key := range i.Key {
+		if key.Key == "_fts" {
+			return "text"
+		}
+	}
+	return formatKeys(i.Key)
+}
+
+func (i existingIndex) options() string {
+	opts := formatOptions(i.Unique, i.ExpireAfterSeconds != nil, i.Weights, i.LanguageOverride, i.PartialFilterExpression)
+	if i.ExpireAfterSeconds != nil && *i.ExpireAfterSeconds != 0 {
+		// Declared TTL indexes always expire at the stored date
+		opts += fmt.Sprintf(" after=%ds", *i.ExpireAfterSeconds)
+	}
+	return opts
+}
+
+func formatOptions(unique, expireAt bool, weights bson.D, languageOverride string, partial bson.D) string {
+	sorted := append(bson.D(nil), weights...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Key < sorted[b].Key })
+	return fmt.Sprintf("unique=%t ttl=%t weights=%s language=%s partial=%s", unique, expireAt, formatKeys(sorted), languageOverride, formatFilter(partial))
+}
+
+// formatFilter - A partial filter as extended JSON; "" when there is none
+func formatFilter(filter bson.D) string {
+	if len(filter) == 0 {
+		return ""
+	}
+	data, err := bson.MarshalExtJSON(filter, false, false)
+	if err != nil {
+		return fmt.Sprint(filter)
+	}
+	return string(data)
+}
+
+// formatKeys - {a: 1, b: -1} with numbers of any BSON type written the same way
+func formatKeys(keys bson.D) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprint(key.Value)
+		switch v := key.Value.(type) {
+		case int32:
+			value = fmt.Sprint(int64(v))
+		case float64:
+			value = fmt.Sprint(int64(v))
+		}
+		parts = append(parts, key.Key+": "+value)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
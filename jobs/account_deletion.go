@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// Hard-deletes student accounts whose deletion grace period has ended
+func init() {
+	Register(Job{
+		Name:     "purge-deleted-accounts",
+		Interval: utils.GetEnvDuration("ACCOUNT_PURGE_INTERVAL", time.Hour),
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			purged, err := servicesimpl.NewAccountDataService().PurgeDue(ctx)
+			if purged > 0 {
+				log.Printf("🗑️ Purged %d deleted account(s)", purged)
+			}
+			return err
+		},
+	})
+}
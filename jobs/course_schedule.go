@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// Publishes and archives courses at the times admins scheduled
+func init() {
+	Register(Job{
+		Name:     "course-schedule",
+		Interval: utils.GetEnvDuration("COURSE_SCHEDULE_INTERVAL", time.Minute),
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			published, archived, err := servicesimpl.NewCourseAuthoringService().RunSchedule(ctx, time.Now())
+			if published > 0 || archived > 0 {
+				log.Printf("📅 Scheduled course changes: %d published, %d archived", published, archived)
+			}
+			return err
+		},
+	})
+}
@@ -0,0 +1,77 @@
+// Package jobs runs periodic background work inside the API process.
+//
+// Jobs must be safe to run on several instances at once (the API may be
+// scaled out), so each one is written to be idempotent. Set JOBS_ENABLED=false
+// on instances that should not run them.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// Job - A named task run every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+var registry []Job
+
+// Register - Adds a job; call before Start
+func Register(job Job) {
+	registry = append(registry, job)
+}
+
+// Start - Runs every registered job on its own ticker until ctx is cancelled
+func Start(ctx context.Context) {
+	if !utils.GetEnvBool("JOBS_ENABLED", true) {
+		log.Println("⏸️ Background jobs disabled (JOBS_ENABLED=false)")
+		return
+	}
+
+	for _, job := range registry {
+		go loop(ctx, job)
+	}
+	log.Printf("⏱️ Started %d background job(s)", len(registry))
+}
+
+func loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	runOnce(ctx, job)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, job)
+		}
+	}
+}
+
+func runOnce(ctx context.Context, job Job) {
+	timeout := job.Timeout
+	if timeout == 0 {
+		timeout = job.Interval
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// A panicking job must not take the API down with it
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("❌ Job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(runCtx); err != nil {
+		log.Printf("⚠️ Job %s failed: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,141 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// Message - A plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer - Anything that can deliver a Message
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	defaultMailer Mailer
+	defaultOnce   sync.Once
+)
+
+// Default - Returns the mailer selected by MAILER_DRIVER ("smtp" or "log").
+// There is no default: "log" writes codes and reset tokens out in plain text,
+// so it has to be chosen on purpose.
+func Default() Mailer {
+	defaultOnce.Do(func() {
+		defaultMailer = NewFromEnv()
+	})
+	return defaultMailer
+}
+
+// NewFromEnv - Build a mailer from environment variables
+func NewFromEnv() Mailer {
+	switch driver := strings.ToLower(strings.TrimSpace(os.Getenv("MAILER_DRIVER"))); driver {
+	case "smtp":
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     utils.GetEnv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     utils.GetEnv("MAIL_FROM", "no-reply@jaromind.com"),
+		}
+	case "log":
+		log.Print("⚠️ MAILER_DRIVER=log: emails, including verification codes and reset tokens, are written to the log instead of being sent")
+		return &LogMailer{Path: os.Getenv("MAIL_LOG_FILE")}
+	case "":
+		log.Print("⚠️ MAILER_DRIVER is not set, emails will not be sent")
+		return &unconfiguredMailer{err: fmt.Errorf("mailer: MAILER_DRIVER is not set (use smtp, or log for development)")}
+	default:
+		log.Printf("⚠️ Unknown MAILER_DRIVER %q, emails will not be sent", driver)
+		return &unconfiguredMailer{err: fmt.Errorf("mailer: unknown MAILER_DRIVER %q", driver)}
+	}
+}
+
+// unconfiguredMailer - Fails every send, so a missing configuration shows up
+// as an error instead of mail silently going nowhere
+type unconfiguredMailer struct {
+	err error
+}
+
+func (m *unconfiguredMailer) Send(msg Message) error {
+	return m.err
+}
+
+// ============================================
+// SMTP
+// ============================================
+
+// SMTPMailer - Sends mail through an SMTP relay (STARTTLS is negotiated by net/smtp when offered)
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	if m.Host == "" {
+		return fmt.Errorf("smtp mailer: SMTP_HOST is not configured")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	headers := []string{
+		"From: " + m.From,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + msg.Body
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{msg.To}, []byte(body))
+}
+
+// ============================================
+// LOG / FILE (DEVELOPMENT)
+// ============================================
+
+// LogMailer - Writes messages to a file (or the process log when Path is empty) instead of sending them
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	entry := fmt.Sprintf("=== %s ===\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+
+	if m.Path == "" {
+		log.Print("📧 [mailer] " + entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
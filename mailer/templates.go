@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"fmt"
+	"time"
+)
+
+// VerificationEmail - Email carrying the account verification code
+func VerificationEmail(to, name, code string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your JaroMind account",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour JaroMind verification code is:\n\n    %s\n\nThe code expires in %s. If you did not create an account you can ignore this email.\n\n- The JaroMind team",
+			greetingName(name), code, humanDuration(ttl),
+		),
+	}
+}
+
+func greetingName(name string) string {
+	if name == "" {
+		return "there"
+	}
+	return name
+}
+
+func humanDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	case d >= time.Minute:
+		minutes := int(d / time.Minute)
+		if minutes == 1 {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", minutes)
+	default:
+		return d.String()
+	}
+}
+
+// PasswordResetEmail - Email carrying a one-time password reset token
+func PasswordResetEmail(to, name, token, resetURL string, ttl time.Duration) Message {
+	link := ""
+	if resetURL != "" {
+		link = fmt.Sprintf("\n\nOr open this link:\n\n    %s?token=%s", resetURL, token)
+	}
+
+	return Message{
+		To:      to,
+		Subject: "Reset your JaroMind password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Use this token to choose a new one:\n\n    %s%s\n\nThe token can be used once and expires in %s. If you did not ask for a reset you can ignore this email; your password has not changed.\n\n- The JaroMind team",
+			greetingName(name), token, link, humanDuration(ttl),
+		),
+	}
+}
+
+// InvitationEmail - Invitation to set a password for a newly created account
+func InvitationEmail(to, name, invitedBy, token, setupURL string, ttl time.Duration) Message {
+	link := ""
+	if setupURL != "" {
+		link = fmt.Sprintf("\n\nOr open this link:\n\n    %s?token=%s", setupURL, token)
+	}
+	if invitedBy == "" {
+		invitedBy = "A JaroMind administrator"
+	}
+
+	return Message{
+		To:      to,
+		Subject: "You've been invited to JaroMind",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n%s has created an account for you. Use this token to choose your password:\n\n    %s%s\n\nThe token can be used once and expires in %s.\n\n- The JaroMind team",
+			greetingName(name), invitedBy, token, link, humanDuration(ttl),
+		),
+	}
+}
@@ -2,19 +2,60 @@
 package main
 
 import (
+    "context"
+    "log"
     "os"
+    "strings"
     "github.com/gin-gonic/gin"
     "github.com/AbaraEmmanuel/jaromind-backend/database"
+    "github.com/AbaraEmmanuel/jaromind-backend/jobs"
+    "github.com/AbaraEmmanuel/jaromind-backend/migrations"
     "github.com/AbaraEmmanuel/jaromind-backend/router"
+    "github.com/AbaraEmmanuel/jaromind-backend/utils"
 )
 
 func main() {
     // Initialize MongoDB
     database.InitDatabase()
 
+    // Apply pending schema migrations (disable with MIGRATE_ON_START=false and
+    // run `jaromindctl migrate up` instead)
+    if utils.GetEnvBool("MIGRATE_ON_START", true) {
+        if _, err := migrations.Up(context.Background(), database.GetDB(), 0); err != nil {
+            log.Fatal("❌ Failed to apply migrations: ", err)
+        }
+    }
+
+    // Create missing indexes (INDEXES_DRY_RUN=true only logs the diff). A
+    // failing index, e.g. duplicates under a unique one, doesn't stop the API.
+    if utils.GetEnvBool("INDEXES_ON_START", true) {
+        if _, err := database.EnsureIndexes(context.Background(), database.GetDB(), utils.GetEnvBool("INDEXES_DRY_RUN", false)); err != nil {
+            log.Printf("⚠️ Failed to apply indexes: %v", err)
+        }
+    }
+
+    // Load JWT signing keys (fail fast on a broken key directory)
+    if _, err := utils.LoadKeys(); err != nil {
+        log.Fatal("❌ Failed to load JWT keys: ", err)
+    }
+
+    // Background jobs (account purges, ...)
+    jobs.Start(context.Background())
+
     // Create router
     r := gin.Default()
 
+    // Only trust X-Forwarded-For from known proxies, so login lockouts can't be
+    // dodged by spoofing the client IP (comma-separated IPs/CIDRs). Without
+    // TRUSTED_PROXIES no proxy is trusted and the client IP is the peer address.
+    var trustedProxies []string
+    if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+        trustedProxies = strings.Split(proxies, ",")
+    }
+    if err := r.SetTrustedProxies(trustedProxies); err != nil {
+        log.Fatal("❌ Invalid TRUSTED_PROXIES: ", err)
+    }
+
     // Register routes (CORS is already in router.RegisterRoutes)
     router.RegisterRoutes(r)
 
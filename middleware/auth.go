@@ -1,204 +1,341 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-)
 
-// Add this above JWTAuthMiddleware
-func getJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// ⚠️ MUST MATCH THE FALLBACK IN utils/jwt.go!
-		return "your-jwt-secret-key-change-in-production"
-	}
-	return secret
-}
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	servicesimpl "github.com/AbaraEmmanuel/jaromind-backend/services_impl"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
 
-// And update the jwtSecret variable:
-var jwtSecret = []byte(getJWTSecret())
-// var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
+// JWTAuthMiddleware - Any authenticated account (student, tutor or admin).
+// API keys are not accepted here; they only work on admin routes.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing Authorization header"})
-			c.Abort()
+		if apiKeyFromRequest(c) != "" {
+			abortWithError(c, http.StatusUnauthorized, "API keys can only be used on admin routes")
 			return
 		}
-
-		// Expected format: "Bearer <token>"
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
-			c.Abort()
+		if !authenticate(c) {
 			return
 		}
 
-		tokenString := parts[1]
-
-		// ✅ Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		if IsImpersonating(c) {
+			// Support staff may look around, not change anything
+			if !impersonationAllowed(c) {
+				abortWithError(c, http.StatusForbidden, "This action can't be performed while impersonating")
+				return
 			}
-			return jwtSecret, nil
-		})
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token: " + err.Error()})
-			c.Abort()
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
-		}
-
-		// Token expiry check (extra layer)
-		if exp, ok := claims["exp"].(float64); ok {
-			if time.Unix(int64(exp), 0).Before(time.Now()) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has expired"})
-				c.Abort()
+			entryID, ok := auditImpersonatedRequest(c)
+			if !ok {
+				abortWithError(c, http.StatusServiceUnavailable, "Could not record this request, please try again")
 				return
 			}
-		}
-
-		// ✅ FIX: Handle BOTH token formats
-		// 1. Try "user_id" (new format - lowercase, used by admins)
-		// 2. Try "user_Id" (old format - capital I, used by existing students)
-		var userID interface{}
-		
-		if uid, ok := claims["user_id"]; ok {
-			// New format (admins and new student tokens)
-			userID = uid
-		} else if uid, ok := claims["user_Id"]; ok {
-			// Old format (existing student tokens - backward compatibility)
-			userID = uid
-		} else {
-			// No user ID found in token
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token missing user ID"})
-			c.Abort()
+			c.Next()
+			setImpersonatedRequestStatus(c, entryID)
 			return
 		}
 
-		// ✅ Get email
-		email, _ := claims["email"].(string)
-		
-		// ✅ Get role with default
-		role := "user"
-		if r, ok := claims["role"].(string); ok {
-			role = r
-		}
-
-		// ✅ Save user info in Gin context
-		c.Set("userID", userID)  // Change from "user_id" to "userID"
-		c.Set("userEmail", email)
-		c.Set("userRole", role)
 		c.Next()
 	}
 }
 
-// ✅ AdminAuthMiddleware - Checks if user has admin role
+// impersonationWrites - The only requests besides GET and HEAD allowed while
+// impersonating, by method and route
+var impersonationWrites = map[string]bool{
+	http.MethodPost + " /logout": true,
+}
+
+// impersonationAllowed - Impersonation is read-only apart from impersonationWrites
+func impersonationAllowed(c *gin.Context) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	return impersonationWrites[c.Request.Method+" "+c.FullPath()]
+}
+
+// ✅ AdminAuthMiddleware - Authenticated account with an admin role, by access
+// token or API key. Individual routes narrow this down further with RequirePermission.
 func AdminAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// First, check if user is authenticated (call JWTAuthMiddleware logic)
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Missing Authorization header",
-			})
-			c.Abort()
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			if !authenticateAPIKey(c, rawKey) {
+				return
+			}
+		} else if !authenticate(c) {
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid Authorization header format",
-			})
-			c.Abort()
+		role := CurrentRole(c)
+		if !models.IsAdminRole(role) {
+			abortWithError(c, http.StatusForbidden, "Admin access required")
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid or expired token",
-			})
-			c.Abort()
-			return
+		c.Next()
+	}
+}
+
+// authenticate validates the bearer token and fills the shared context keys.
+// On failure it writes the response, aborts and returns false.
+func authenticate(c *gin.Context) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		abortWithError(c, http.StatusUnauthorized, "Missing Authorization header")
+		return false
+	}
+
+	// Expected format: "Bearer <token>"
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		abortWithError(c, http.StatusUnauthorized, "Invalid Authorization header format")
+		return false
+	}
+
+	tokenString := parts[1]
+
+	// ✅ Parse and validate token (signature, kid, issuer, expiry)
+	claims, err := utils.ValidateToken(tokenString)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "Invalid or expired token: "+err.Error())
+		return false
+	}
+
+	// Challenge tokens and other special-purpose tokens are not access tokens
+	if typ, ok := claims["typ"].(string); ok && typ != utils.TokenTypeAccess {
+		abortWithError(c, http.StatusUnauthorized, "Invalid token type")
+		return false
+	}
+
+	// Token expiry check (extra layer)
+	if exp, ok := claims["exp"].(float64); ok {
+		if time.Unix(int64(exp), 0).Before(time.Now()) {
+			abortWithError(c, http.StatusUnauthorized, "Token has expired")
+			return false
 		}
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid token claims",
-			})
-			c.Abort()
-			return
+	// ✅ FIX: Handle BOTH token formats
+	// 1. Try "user_id" (new format - lowercase, used by admins)
+	// 2. Try "user_Id" (old format - capital I, used by existing students)
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		userID, _ = claims["user_Id"].(string)
+	}
+	if userID == "" {
+		abortWithError(c, http.StatusUnauthorized, "Token missing user ID")
+		return false
+	}
+
+	// ✅ Get email
+	email, _ := claims["email"].(string)
+
+	// ✅ Role, with legacy "user"/"admin" mapped onto the role model
+	rawRole, _ := claims["role"].(string)
+	role := models.NormalizeRole(rawRole)
+
+	// The checks below need the database. If it can't be reached the token is
+	// refused rather than accepted unchecked.
+
+	// ✅ Tokens of deactivated accounts, or issued before the last password change, are dead
+	invalid, err := tokenInvalidForAccount(c, claims, models.AccountTypeForRole(role), userID)
+	if err != nil {
+		abortUnverified(c, "account state for "+userID, err)
+		return false
+	}
+	if invalid {
+		abortWithError(c, http.StatusUnauthorized, "Token is no longer valid, please log in again")
+		return false
+	}
+
+	// ✅ Impersonation tokens name the admin in "act"; they die with the admin's access
+	actorID, actorEmail := impersonator(claims)
+	if actorID != "" {
+		invalid, err := tokenInvalidForAccount(c, claims, models.AccountTypeAdmin, actorID)
+		if err != nil {
+			abortUnverified(c, "account state for "+actorID, err)
+			return false
 		}
+		if invalid {
+			abortWithError(c, http.StatusUnauthorized, "Token is no longer valid, please log in again")
+			return false
+		}
+	}
 
-		// ✅ Check if user has admin role
-		role, ok := claims["role"].(string)
-		if !ok || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"error":   "Admin access required",
-				"hint":    "Your role: " + role,
-			})
-			c.Abort()
-			return
+	// ✅ Revocation list (logout, stolen tokens)
+	revoked, err := tokenRevoked(c, claims)
+	if err != nil {
+		abortUnverified(c, "revocation list", err)
+		return false
+	}
+	if revoked {
+		abortWithError(c, http.StatusUnauthorized, "Token has been revoked")
+		return false
+	}
+
+	// ✅ Signed-out sessions (logout, "sign out other devices") end their tokens too
+	sid, _ := claims["sid"].(string)
+	if sid != "" {
+		terminated, err := sessionTerminated(c, sid)
+		if err != nil {
+			abortUnverified(c, "session "+sid, err)
+			return false
+		}
+		if terminated {
+			abortWithError(c, http.StatusUnauthorized, services.ErrSessionTerminated.Error())
+			return false
 		}
+	}
 
-		// ✅ Set user info in context (same as JWTAuthMiddleware)
-		c.Set("user_id", claims["user_id"])
-		c.Set("email", claims["email"])
-		c.Set("role", role)
+	// ✅ Save user info in Gin context
+	c.Set(ContextUserID, userID)
+	c.Set(ContextUserEmail, email)
+	c.Set(ContextUserRole, role)
+	if jti, ok := claims["jti"].(string); ok {
+		c.Set(ContextTokenID, jti)
+	}
+	if sid != "" {
+		c.Set(ContextSessionID, sid)
+	}
+	if exp, ok := claims["exp"].(float64); ok {
+		c.Set(ContextTokenExpiresAt, time.Unix(int64(exp), 0))
+	}
+	if actorID != "" {
+		c.Set(ContextImpersonatorID, actorID)
+		c.Set(ContextImpersonatorEmail, actorEmail)
+	}
+	return true
+}
 
-		c.Next()
+// impersonator - The admin named in the token's "act" claim, if any
+func impersonator(claims jwt.MapClaims) (string, string) {
+	act, ok := claims["act"].(map[string]interface{})
+	if !ok {
+		return "", ""
 	}
+	id, _ := act["sub"].(string)
+	email, _ := act["email"].(string)
+	return id, email
 }
 
-// ✅ Optional: Combined middleware that does both
-func JWTAuthWithAdminCheck() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// First validate JWT
-		JWTAuthMiddleware()(c)
-		
-		// If context was aborted by JWTAuthMiddleware, return
-		if c.IsAborted() {
-			return
-		}
-		
-		// Then check for admin role
-		role, exists := c.Get("role")
-		if !exists || role.(string) != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"error":   "Admin privileges required",
-			})
-			c.Abort()
-			return
+// auditImpersonatedRequest - Records the request in the audit log before it
+// is handled, so nothing is done on someone's behalf without a trace. Returns
+// false if the entry couldn't be written.
+func auditImpersonatedRequest(c *gin.Context) (primitive.ObjectID, bool) {
+	entry := &models.AuditLog{
+		Action:      models.AuditImpersonationRequest,
+		ActorID:     CurrentImpersonatorID(c),
+		ActorEmail:  CurrentImpersonatorEmail(c),
+		SubjectType: models.AccountTypeForRole(CurrentRole(c)),
+		SubjectID:   CurrentUserID(c),
+		TokenID:     CurrentTokenID(c),
+		Method:      c.Request.Method,
+		Path:        c.Request.URL.Path,
+		IP:          c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+	}
+	if err := servicesimpl.NewAuditService().Record(c.Request.Context(), entry); err != nil {
+		log.Printf("❌ Could not audit impersonated request %s %s, refusing it: %v", c.Request.Method, c.Request.URL.Path, err)
+		return primitive.NilObjectID, false
+	}
+	return entry.ID, true
+}
+
+// setImpersonatedRequestStatus - Fills in the outcome of an audited request.
+// Runs after the handler, so it uses its own context.
+func setImpersonatedRequestStatus(c *gin.Context, entryID primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := servicesimpl.NewAuditService().SetStatus(ctx, entryID, c.Writer.Status()); err != nil {
+		log.Printf("⚠️ Could not record the status of impersonated request %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+}
+
+// apiKeyFromRequest - The API key from X-API-Key or "Bearer jmk_...", if any
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		return key
+	}
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") && strings.HasPrefix(parts[1], models.APIKeyPrefix) {
+		return parts[1]
+	}
+	return ""
+}
+
+// authenticateAPIKey resolves the key to its owner and fills the context like
+// authenticate does, plus the key ID and scopes for RequirePermission
+func authenticateAPIKey(c *gin.Context, rawKey string) bool {
+	principal, err := servicesimpl.NewAPIKeyService().Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+	if err != nil {
+		if !errors.Is(err, services.ErrInvalidAPIKey) {
+			log.Printf("⚠️ Could not check API key: %v", err)
 		}
-		
-		c.Next()
+		abortWithError(c, http.StatusUnauthorized, services.ErrInvalidAPIKey.Error())
+		return false
+	}
+
+	c.Set(ContextUserID, principal.Key.OwnerID)
+	c.Set(ContextUserEmail, principal.Email)
+	c.Set(ContextUserRole, principal.Role)
+	c.Set(ContextAPIKeyID, principal.Key.ID.Hex())
+	c.Set(ContextAPIKeyScopes, principal.Key.Scopes)
+	return true
+}
+
+func abortWithError(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   message,
+	})
+}
+
+// abortUnverified - 503 when a token can't be checked against the database
+func abortUnverified(c *gin.Context, what string, err error) {
+	log.Printf("⚠️ Could not check %s, refusing the token: %v", what, err)
+	abortWithError(c, http.StatusServiceUnavailable, "Could not verify your session, please try again")
+}
+
+// tokenInvalidForAccount - True when the account was deactivated or removed,
+// or the token was issued before its most recent password change
+func tokenInvalidForAccount(c *gin.Context, claims jwt.MapClaims, accountType, userID string) (bool, error) {
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		// Tokens without iat can't be compared; treat them as revoked
+		return true, nil
+	}
+	return servicesimpl.TokenInvalidForAccount(c.Request.Context(), accountType, userID, time.Unix(int64(iat), 0))
+}
+
+// tokenRevoked - True when the token's jti is on the revocation list.
+// Legacy tokens without a jti can't be revoked individually and pass.
+func tokenRevoked(c *gin.Context, claims jwt.MapClaims) (bool, error) {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return false, nil
+	}
+	return servicesimpl.NewSessionService().IsTokenRevoked(c.Request.Context(), jti)
+}
+
+// sessionTerminated - True when the token's session was revoked or deleted.
+// Also records the device's user agent, IP and last-seen time.
+func sessionTerminated(c *gin.Context, sessionID string) (bool, error) {
+	err := servicesimpl.NewSessionService().Touch(c.Request.Context(), sessionID, c.Request.UserAgent(), c.ClientIP())
+	if errors.Is(err, services.ErrSessionTerminated) {
+		return true, nil
 	}
-}
\ No newline at end of file
+	return false, err
+}
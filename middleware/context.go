@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// Gin context keys set by the auth middleware. Handlers should read them
+// through the helpers below rather than guessing key names.
+const (
+	ContextUserID         = "userID"
+	ContextUserEmail      = "userEmail"
+	ContextUserRole       = "userRole"
+	ContextTokenID        = "tokenID"
+	ContextSessionID      = "sessionID"
+	ContextTokenExpiresAt = "tokenExpiresAt"
+	ContextAPIKeyID       = "apiKeyID"
+	ContextAPIKeyScopes   = "apiKeyScopes"
+
+	ContextImpersonatorID    = "impersonatorID"
+	ContextImpersonatorEmail = "impersonatorEmail"
+)
+
+// CurrentUserID - ID of the authenticated account ("" if unauthenticated)
+func CurrentUserID(c *gin.Context) string {
+	return c.GetString(ContextUserID)
+}
+
+// CurrentUserEmail - Email claim of the authenticated account
+func CurrentUserEmail(c *gin.Context) string {
+	return c.GetString(ContextUserEmail)
+}
+
+// CurrentRole - Normalized role of the authenticated account
+func CurrentRole(c *gin.Context) string {
+	return c.GetString(ContextUserRole)
+}
+
+// CurrentTokenID - jti of the access token used for this request
+func CurrentTokenID(c *gin.Context) string {
+	return c.GetString(ContextTokenID)
+}
+
+// CurrentSessionID - Session the access token belongs to ("" for legacy tokens)
+func CurrentSessionID(c *gin.Context) string {
+	return c.GetString(ContextSessionID)
+}
+
+// CurrentTokenExpiresAt - Expiry of the access token used for this request
+func CurrentTokenExpiresAt(c *gin.Context) time.Time {
+	return c.GetTime(ContextTokenExpiresAt)
+}
+
+// CurrentAPIKeyID - API key used for this request ("" for token logins)
+func CurrentAPIKeyID(c *gin.Context) string {
+	return c.GetString(ContextAPIKeyID)
+}
+
+// CurrentImpersonatorID - Admin acting as the user ("" unless impersonating)
+func CurrentImpersonatorID(c *gin.Context) string {
+	return c.GetString(ContextImpersonatorID)
+}
+
+// CurrentImpersonatorEmail - Email of the admin acting as the user
+func CurrentImpersonatorEmail(c *gin.Context) string {
+	return c.GetString(ContextImpersonatorEmail)
+}
+
+// IsImpersonating - True when an admin is using an impersonation token
+func IsImpersonating(c *gin.Context) bool {
+	return CurrentImpersonatorID(c) != ""
+}
+
+// currentAPIKeyScopes - Scopes of the API key used for this request
+func currentAPIKeyScopes(c *gin.Context) []models.Permission {
+	scopes, _ := c.Get(ContextAPIKeyScopes)
+	list, _ := scopes.([]models.Permission)
+	return list
+}
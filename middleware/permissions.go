@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// RequirePermission - Per-route permission check. Must run after
+// JWTAuthMiddleware or AdminAuthMiddleware; every listed permission is required.
+//
+//	admin.POST("/courses", middleware.RequirePermission(models.PermCourseCreate), controllers.CreateCourse)
+func RequirePermission(perms ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		if role == "" {
+			abortWithError(c, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		apiKey := CurrentAPIKeyID(c) != ""
+		for _, perm := range perms {
+			// API keys need the permission in their scopes as well as in the owner's role
+			if !models.HasPermission(role, perm) || (apiKey && !hasScope(currentAPIKeyScopes(c), perm)) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"success":    false,
+					"error":      "You don't have permission to perform this action",
+					"permission": perm,
+				})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireInteractive - Rejects API keys and impersonation tokens, for routes
+// that manage the account itself (password, 2FA, API keys) and need a real login
+func RequireInteractive() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentAPIKeyID(c) != "" {
+			abortWithError(c, http.StatusForbidden, "This action can't be performed with an API key")
+			return
+		}
+		if IsImpersonating(c) {
+			abortWithError(c, http.StatusForbidden, "This action can't be performed while impersonating")
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasScope(scopes []models.Permission, perm models.Permission) bool {
+	for _, scope := range scopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Courses created before public ids existed get the hex of their _id as id
+func init() {
+	Register(Migration{
+		Version: 1,
+		Name:    "course_public_ids",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := repository.NewMongoStore(db).Courses.BackfillIDs(ctx)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			// Backfilled ids are exactly the ones equal to the _id hex
+			_, err := db.Collection("courses").UpdateMany(ctx,
+				bson.M{"$expr": bson.M{"$eq": bson.A{"$id", bson.M{"$toString": "$_id"}}}},
+				bson.M{"$unset": bson.M{"id": ""}},
+			)
+			return err
+		},
+	})
+}
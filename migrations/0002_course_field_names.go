@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Courses are camelCase, but the review service used to write review_count
+// and updated_at onto them. review_count is the live counter, so it wins;
+// for the timestamps the later one wins.
+func init() {
+	Register(Migration{
+		Version: 2,
+		Name:    "course_camel_case_fields",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			courses := db.Collection("courses")
+
+			_, err := courses.UpdateMany(ctx, bson.M{"review_count": bson.M{"$exists": true}}, mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{"reviewCount": "$review_count"}}},
+				{{Key: "$unset", Value: "review_count"}},
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = courses.UpdateMany(ctx, bson.M{"updated_at": bson.M{"$exists": true}}, mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{"updatedAt": bson.M{"$max": bson.A{"$updatedAt", "$updated_at"}}}}},
+				{{Key: "$unset", Value: "updated_at"}},
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			// Older code reads either spelling, so restore both
+			_, err := db.Collection("courses").UpdateMany(ctx, bson.M{}, mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{
+					"review_count": "$reviewCount",
+					"updated_at":   "$updatedAt",
+				}}},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Reviews are snake_case and keyed by course_id; move any camelCase courseId
+// written by older clients onto it
+func init() {
+	Register(Migration{
+		Version: 3,
+		Name:    "review_course_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("reviews").UpdateMany(ctx, bson.M{"courseId": bson.M{"$exists": true}}, mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{"course_id": bson.M{"$ifNull": bson.A{"$course_id", "$courseId"}}}}},
+				{{Key: "$unset", Value: "courseId"}},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Courses move through draft -> in_review -> published -> archived and only
+// published ones are listed. Courses written before that have a free-form
+// status or none: active ones become published, the rest archived. isActive
+// then follows the status.
+func init() {
+	Register(Migration{
+		Version: 4,
+		Name:    "course_status",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			courses := db.Collection("courses")
+			known := bson.A{"draft", "in_review", "published", "archived"}
+
+			_, err := courses.UpdateMany(ctx, bson.M{"status": bson.M{"$nin": known}, "isActive": true},
+				bson.M{"$set": bson.M{"status": "published"}})
+			if err != nil {
+				return err
+			}
+			_, err = courses.UpdateMany(ctx, bson.M{"status": bson.M{"$nin": known}},
+				bson.M{"$set": bson.M{"status": "archived"}})
+			if err != nil {
+				return err
+			}
+
+			_, err = courses.UpdateMany(ctx, bson.M{}, mongo.Pipeline{
+				{{Key: "$set", Value: bson.M{"isActive": bson.M{"$eq": bson.A{"$status", "published"}}}}},
+			})
+			return err
+		},
+		// The old statuses didn't include in_review
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("courses").UpdateMany(ctx, bson.M{"status": "in_review"},
+				bson.M{"$set": bson.M{"status": "draft"}, "$unset": bson.M{"scheduledPublishAt": ""}})
+			return err
+		},
+	})
+}
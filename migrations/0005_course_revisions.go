@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Every course change is kept in course_revisions. Courses that existed
+// before get a baseline revision of how they are now, so the first change
+// afterwards shows only what it changed.
+func init() {
+	Register(Migration{
+		Version: 5,
+		Name:    "course_revisions",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Indexes are only ensured after migrations, and revision numbers
+			// must be unique from the start (see database/index_definitions.go)
+			_, err := db.Collection("course_revisions").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "courseId", Value: 1}, {Key: "number", Value: -1}},
+				Options: options.Index().SetName("course_number_unique").SetUnique(true),
+			})
+			if err != nil {
+				return err
+			}
+
+			store := repository.NewMongoStore(db)
+
+			var courses []bson.M
+			if err := store.Courses.List(ctx, repository.Fields{models.CourseRevisionField: nil}, repository.Sort{}, &courses); err != nil {
+				return err
+			}
+			for _, course := range courses {
+				courseID, _ := course["id"].(string)
+				if courseID == "" {
+					continue
+				}
+
+				// Courses changed meanwhile already have revisions of their own
+				err := store.Courses.Update(ctx, courseID, repository.Fields{models.CourseRevisionField: nil}, bson.M{models.CourseRevisionField: 1})
+				if errors.Is(err, services.ErrCourseNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				err = store.Revisions.Insert(ctx, &models.CourseRevision{
+					CourseID:   courseID,
+					Number:     1,
+					Action:     models.CourseRevisionBaseline,
+					AuthorID:   "migration",
+					AuthorRole: "system",
+					Fields:     []string{},
+					Snapshot:   models.CourseSnapshot(course),
+					CreatedAt:  time.Now(),
+					Changes:    []models.CourseFieldChange{},
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			revisions := db.Collection("course_revisions")
+			baselined, err := revisions.Distinct(ctx, "courseId", bson.M{"action": models.CourseRevisionBaseline})
+			if err != nil {
+				return err
+			}
+
+			// Counters of courses that haven't changed since go with their baseline
+			_, err = db.Collection("courses").UpdateMany(ctx,
+				bson.M{"id": bson.M{"$in": baselined}, models.CourseRevisionField: 1},
+				bson.M{"$unset": bson.M{models.CourseRevisionField: ""}},
+			)
+			if err != nil {
+				return err
+			}
+			_, err = revisions.DeleteMany(ctx, bson.M{"action": models.CourseRevisionBaseline})
+			return err
+		},
+	})
+}
@@ -0,0 +1,171 @@
+// Package migrations applies versioned schema changes to MongoDB.
+//
+// Each migration registers itself from an init function in its own file
+// (0001_name.go, ...). Applied versions are recorded in the schema_migrations
+// collection. Migrations must be idempotent: the API applies pending ones at
+// startup (MIGRATE_ON_START) and several instances may start at once.
+//
+//	go run ./cmd/jaromindctl migrate status
+//	go run ./cmd/jaromindctl migrate up
+//	go run ./cmd/jaromindctl migrate down --to 2
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection - Where applied migrations are recorded
+const Collection = "schema_migrations"
+
+// Migration - A versioned change. Down undoes Up; a nil Down means there is
+// nothing to undo (the data Up leaves behind is valid for older code too).
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status - A migration and when it was applied (nil if pending)
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+var registry = map[int]Migration{}
+
+// Register - Adds a migration; versions must be unique
+func Register(m Migration) {
+	if m.Version <= 0 || m.Up == nil {
+		panic(fmt.Sprintf("migrations: invalid migration %d %q", m.Version, m.Name))
+	}
+	if existing, ok := registry[m.Version]; ok {
+		panic(fmt.Sprintf("migrations: version %d used by both %q and %q", m.Version, existing.Name, m.Name))
+	}
+	registry[m.Version] = m
+}
+
+// All - Every registered migration, oldest first
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all
+}
+
+// List - Every registered migration with its applied time
+func List(ctx context.Context, db *mongo.Database) ([]Status, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+	for _, m := range All() {
+		status := Status{Migration: m}
+		if r, ok := applied[m.Version]; ok {
+			appliedAt := r.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up - Applies pending migrations up to and including target (0 = all), oldest
+// first, stopping at the first failure. Returns the migrations applied.
+func Up(ctx context.Context, db *mongo.Database, target int) ([]Migration, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, m := range All() {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		if err := m.Up(ctx, db); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		_, err := db.Collection(Collection).InsertOne(ctx, record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return done, fmt.Errorf("recording migration %d: %w", m.Version, err)
+		}
+
+		log.Printf("🔧 Applied migration %d %s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down - Reverts applied migrations newer than target, newest first, stopping
+// at the first failure. Returns the migrations reverted.
+func Down(ctx context.Context, db *mongo.Database, target int) ([]Migration, error) {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	all := All()
+	done := []Migration{}
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if m.Version <= target {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		if m.Down != nil {
+			if err := m.Down(ctx, db); err != nil {
+				return done, fmt.Errorf("reverting migration %d %s: %w", m.Version, m.Name, err)
+			}
+		}
+		if _, err := db.Collection(Collection).DeleteOne(ctx, bson.M{"_id": m.Version}); err != nil {
+			return done, fmt.Errorf("unrecording migration %d: %w", m.Version, err)
+		}
+
+		log.Printf("↩️ Reverted migration %d %s", m.Version, m.Name)
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func appliedVersions(ctx context.Context, db *mongo.Database) (map[int]record, error) {
+	cursor, err := db.Collection(Collection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AccountExport is the archive a student downloads from GET /user/export:
+// everything stored about them, in one JSON document
+type AccountExport struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	Profile     *User              `json:"profile"`
+	Enrollments []EnrollmentExport `json:"enrollments"`
+	Reviews     []Review           `json:"reviews"`
+	Sessions    []Session          `json:"sessions"`
+}
+
+// EnrollmentExport is an enrollment (including progress) with the course title
+type EnrollmentExport struct {
+	Enrollment
+	CourseTitle string `json:"courseTitle,omitempty"`
+}
+
+// DeletedUserName replaces the author name on reviews of deleted accounts
+const DeletedUserName = "Deleted user"
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to spot and the
+// middleware can tell them apart from JWTs
+const APIKeyPrefix = "jmk_"
+
+// APIKeyScopes - Permissions that may be granted to an API key. Managing
+// admins and API keys always needs an interactive login.
+var APIKeyScopes = []Permission{
+	PermCourseCreate,
+	PermCourseUpdate,
+	PermCourseDelete,
+	PermCoursePublish,
+	PermReviewModerate,
+	PermTutorManage,
+	PermUserManage,
+}
+
+// IsValidAPIKeyScope reports whether a key may carry the permission
+func IsValidAPIKeyScope(perm Permission) bool {
+	for _, scope := range APIKeyScopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey is a long-lived credential owned by an admin, for server-to-server
+// integrations. The key acts as its owner, limited to its scopes. Only the
+// SHA-256 hash is stored; Prefix identifies the key in lists and logs.
+type APIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	KeyHash    string             `bson:"keyHash" json:"-"`
+	OwnerID    string             `bson:"ownerId" json:"ownerId"`
+	Scopes     []Permission       `bson:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	LastUsedIP string             `bson:"lastUsedIp,omitempty" json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit log actions
+const (
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationRequest = "impersonation.request"
+)
+
+// AuditLog records who did what on whose behalf. Entries are append-only,
+// except that an impersonated request gets its status once it has been handled.
+type AuditLog struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Action      string             `bson:"action" json:"action"`
+	ActorID     string             `bson:"actorId" json:"actorId"`
+	ActorEmail  string             `bson:"actorEmail,omitempty" json:"actorEmail,omitempty"`
+	SubjectType string             `bson:"subjectType,omitempty" json:"subjectType,omitempty"`
+	SubjectID   string             `bson:"subjectId,omitempty" json:"subjectId,omitempty"`
+	Reason      string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	TokenID     string             `bson:"tokenId,omitempty" json:"tokenId,omitempty"`
+	Method      string             `bson:"method,omitempty" json:"method,omitempty"`
+	Path        string             `bson:"path,omitempty" json:"path,omitempty"`
+	Status      int                `bson:"status,omitempty" json:"status,omitempty"`
+	IP          string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent   string             `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// ImpersonationToken is returned to the admin who starts impersonating
+type ImpersonationToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"tokenType"`
+	ExpiresIn int64     `json:"expiresIn"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	UserID    string    `json:"userId"`
+	UserEmail string    `json:"userEmail"`
+}
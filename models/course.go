@@ -6,7 +6,8 @@ import (
 )
 
 type Course struct {
-	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`  // Changed to ObjectID
+	MongoID         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	ID              string             `json:"id" bson:"id"` // Public id: a UUID, or the _id hex for older courses
 	Title           string             `json:"title" bson:"title"`
 	Description     string             `json:"description" bson:"description"`
 	LongDescription string             `json:"longDescription" bson:"longDescription"`
@@ -29,6 +30,7 @@ type Course struct {
 	Prerequisites   []string           `json:"prerequisites" bson:"prerequisites"`
 	LearningGoals   []string           `json:"learningGoals" bson:"learningGoals"`
 	Tutor           *Tutor             `json:"tutor" bson:"tutor"`
+	TutorID         string             `json:"tutorId,omitempty" bson:"tutorId,omitempty"` // Tutor account; Tutor holds a copy of its display fields
 	Curriculum      []CurriculumItem   `json:"curriculum" bson:"curriculum"`
 	Certificate     bool               `json:"certificate" bson:"certificate"`
 	Language        string             `json:"language" bson:"language"`
@@ -37,6 +39,11 @@ type Course struct {
 	Metadata        *CourseMetadata    `json:"metadata" bson:"metadata"`
 	CreatedAt       time.Time          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt       time.Time          `json:"updatedAt" bson:"updatedAt"`
+	PublishedAt     *time.Time         `json:"publishedAt,omitempty" bson:"publishedAt,omitempty"`
+
+	// Set by admins; the course-schedule job publishes or archives the course then
+	ScheduledPublishAt   *time.Time `json:"scheduledPublishAt,omitempty" bson:"scheduledPublishAt,omitempty"`
+	ScheduledUnpublishAt *time.Time `json:"scheduledUnpublishAt,omitempty" bson:"scheduledUnpublishAt,omitempty"`
 }
 
 // Other structs remain the same...
@@ -71,6 +78,16 @@ type AccessRestrictions struct {
 	Device       bool `json:"device" bson:"device"`
 }
 
+// Course statuses. Courses start as drafts, go through review and only
+// published courses are in the public catalog (see CourseTransitions).
+const (
+	CourseStatusDraft     = "draft"
+	CourseStatusInReview  = "in_review"
+	CourseStatusPublished = "published"
+	CourseStatusArchived  = "archived"
+)
+
+// Tutor - Display fields of the course's tutor, embedded in the course
 type Tutor struct {
 	Name        string `json:"name" bson:"name"`
 	Bio         string `json:"bio" bson:"bio"`
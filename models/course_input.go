@@ -0,0 +1,296 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Known values for the course enums. Values are stored in lower case.
+var (
+	CourseLevels   = []string{"beginner", "intermediate", "advanced", "all-levels"}
+	CourseTypes    = []string{"academic", "professional", "skill"}
+	CourseStatuses = []string{CourseStatusDraft, CourseStatusInReview, CourseStatusPublished, CourseStatusArchived}
+)
+
+const (
+	maxCourseTitleLength       = 200
+	maxCourseDescriptionLength = 1000
+	maxCourseListItems         = 100
+)
+
+// FieldErrors maps a JSON field path ("price", "curriculum[2].title") to what
+// is wrong with it
+type FieldErrors map[string]string
+
+func (f FieldErrors) Error() string {
+	fields := make([]string, 0, len(f))
+	for field := range f {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + " " + f[field]
+	}
+	return "invalid fields: " + strings.Join(parts, "; ")
+}
+
+// CourseInput - The fields that may be written when creating or updating a
+// course. nil means "not provided"; anything else (counters, ratings, ids,
+// the workflow status) is managed by the server and can't be written here.
+type CourseInput struct {
+	Title           *string           `json:"title"`
+	Description     *string           `json:"description"`
+	LongDescription *string           `json:"longDescription"`
+	Type            *string           `json:"type"`
+	ClassLevel      *string           `json:"classLevel"`
+	Subject         *string           `json:"subject"`
+	Subjects        *[]string         `json:"subjects"`
+	ImageUrl        *string           `json:"imageUrl"`
+	Price           *float64          `json:"price"`
+	LessonCount     *int              `json:"lessonCount"`
+	Duration        *string           `json:"duration"`
+	Level           *string           `json:"level"`
+	IsFeatured      *bool             `json:"isFeatured"`
+	Features        *[]string         `json:"features"`
+	Prerequisites   *[]string         `json:"prerequisites"`
+	LearningGoals   *[]string         `json:"learningGoals"`
+	Tutor           *Tutor            `json:"tutor"`
+	TutorID         *string           `json:"tutorId"`
+	Curriculum      *[]CurriculumItem `json:"curriculum"`
+	Certificate     *bool             `json:"certificate"`
+	Language        *string           `json:"language"`
+	Category        *string           `json:"category"`
+	Tags            *[]string         `json:"tags"`
+	Metadata        *CourseMetadata   `json:"metadata"`
+}
+
+// Validate checks and normalizes the provided fields. creating additionally
+// requires the fields every course must have.
+func (in *CourseInput) Validate(creating bool) FieldErrors {
+	errs := FieldErrors{}
+
+	trim := func(value *string) {
+		if value != nil {
+			*value = strings.TrimSpace(*value)
+		}
+	}
+	for _, value := range []*string{
+		in.Title, in.Description, in.LongDescription, in.Type, in.ClassLevel, in.Subject,
+		in.ImageUrl, in.Duration, in.Level, in.TutorID, in.Language, in.Category,
+	} {
+		trim(value)
+	}
+
+	switch {
+	case in.Title == nil && creating, in.Title != nil && *in.Title == "":
+		errs["title"] = "is required"
+	case in.Title != nil && len(*in.Title) > maxCourseTitleLength:
+		errs["title"] = fmt.Sprintf("must be at most %d characters", maxCourseTitleLength)
+	}
+	if in.Description != nil && len(*in.Description) > maxCourseDescriptionLength {
+		errs["description"] = fmt.Sprintf("must be at most %d characters", maxCourseDescriptionLength)
+	}
+
+	checkEnum(errs, "type", in.Type, CourseTypes)
+	checkEnum(errs, "level", in.Level, CourseLevels)
+
+	if in.Price != nil && *in.Price < 0 {
+		errs["price"] = "must be 0 or more"
+	}
+	if in.LessonCount != nil && *in.LessonCount < 0 {
+		errs["lessonCount"] = "must be 0 or more"
+	}
+	if in.ImageUrl != nil && *in.ImageUrl != "" && !isHTTPURL(*in.ImageUrl) {
+		errs["imageUrl"] = "must be an http(s) URL"
+	}
+
+	checkList(errs, "subjects", in.Subjects)
+	checkList(errs, "features", in.Features)
+	checkList(errs, "prerequisites", in.Prerequisites)
+	checkList(errs, "learningGoals", in.LearningGoals)
+	checkList(errs, "tags", in.Tags)
+
+	if in.Tutor != nil {
+		if in.Tutor.YearsExp < 0 {
+			errs["tutor.yearsExp"] = "must be 0 or more"
+		}
+		if in.Tutor.Avatar != "" && !isHTTPURL(in.Tutor.Avatar) {
+			errs["tutor.avatar"] = "must be an http(s) URL"
+		}
+	}
+
+	if in.Curriculum != nil {
+		if len(*in.Curriculum) > maxCourseListItems {
+			errs["curriculum"] = fmt.Sprintf("must have at most %d items", maxCourseListItems)
+		}
+		for i := range *in.Curriculum {
+			item := &(*in.Curriculum)[i]
+			prefix := fmt.Sprintf("curriculum[%d].", i)
+			item.Title = strings.TrimSpace(item.Title)
+			if item.Title == "" {
+				errs[prefix+"title"] = "is required"
+			}
+			if item.Week < 0 {
+				errs[prefix+"week"] = "must be 0 or more"
+			}
+			if item.Topics == nil {
+				item.Topics = []string{}
+			}
+			for j, topic := range item.Topics {
+				if strings.TrimSpace(topic) == "" {
+					errs[fmt.Sprintf("%stopics[%d]", prefix, j)] = "must not be empty"
+				}
+			}
+		}
+	}
+
+	if m := in.Metadata; m != nil {
+		nonNegative := map[string]int{
+			"metadata.quizCount":       m.QuizCount,
+			"metadata.assignmentCount": m.AssignmentCount,
+			"metadata.maxCapacity":     m.MaxCapacity,
+		}
+		for field, value := range nonNegative {
+			if value < 0 {
+				errs[field] = "must be 0 or more"
+			}
+		}
+		if m.PassingGrade < 0 || m.PassingGrade > 100 {
+			errs["metadata.passingGrade"] = "must be between 0 and 100"
+		}
+		if m.PromoVideoUrl != "" && !isHTTPURL(m.PromoVideoUrl) {
+			errs["metadata.promoVideoUrl"] = "must be an http(s) URL"
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// TutorRestricted - Errors for fields only admins may set, for tutor drafts
+func (in *CourseInput) TutorRestricted() FieldErrors {
+	errs := FieldErrors{}
+	restricted := map[string]bool{
+		"isFeatured": in.IsFeatured != nil,
+		"tutor":      in.Tutor != nil,
+		"tutorId":    in.TutorID != nil,
+	}
+	for field, set := range restricted {
+		if set {
+			errs[field] = "can only be set by an admin"
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Fields - The provided fields as a $set document, keyed by their bson names
+func (in *CourseInput) Fields() bson.M {
+	fields := bson.M{}
+	for name, value := range map[string]*string{
+		"title":           in.Title,
+		"description":     in.Description,
+		"longDescription": in.LongDescription,
+		"type":            in.Type,
+		"classLevel":      in.ClassLevel,
+		"subject":         in.Subject,
+		"imageUrl":        in.ImageUrl,
+		"duration":        in.Duration,
+		"level":           in.Level,
+		"tutorId":         in.TutorID,
+		"language":        in.Language,
+		"category":        in.Category,
+	} {
+		if value != nil {
+			fields[name] = *value
+		}
+	}
+	if in.Price != nil {
+		fields["price"] = *in.Price
+	}
+	if in.LessonCount != nil {
+		fields["lessonCount"] = *in.LessonCount
+	}
+	if in.IsFeatured != nil {
+		fields["isFeatured"] = *in.IsFeatured
+	}
+	if in.Certificate != nil {
+		fields["certificate"] = *in.Certificate
+	}
+	for name, list := range map[string]*[]string{
+		"subjects":      in.Subjects,
+		"features":      in.Features,
+		"prerequisites": in.Prerequisites,
+		"learningGoals": in.LearningGoals,
+		"tags":          in.Tags,
+	} {
+		if list != nil {
+			fields[name] = nonNilStrings(*list)
+		}
+	}
+	if in.Curriculum != nil {
+		curriculum := *in.Curriculum
+		if curriculum == nil {
+			curriculum = []CurriculumItem{}
+		}
+		fields["curriculum"] = curriculum
+	}
+	if in.Tutor != nil {
+		fields["tutor"] = in.Tutor
+	}
+	if in.Metadata != nil {
+		fields["metadata"] = in.Metadata
+	}
+	return fields
+}
+
+func checkEnum(errs FieldErrors, field string, value *string, allowed []string) {
+	if value == nil {
+		return
+	}
+	*value = strings.ToLower(*value)
+	for _, candidate := range allowed {
+		if *value == candidate {
+			return
+		}
+	}
+	errs[field] = "must be one of " + strings.Join(allowed, ", ")
+}
+
+func checkList(errs FieldErrors, field string, list *[]string) {
+	if list == nil {
+		return
+	}
+	if len(*list) > maxCourseListItems {
+		errs[field] = fmt.Sprintf("must have at most %d items", maxCourseListItems)
+		return
+	}
+	for i, item := range *list {
+		(*list)[i] = strings.TrimSpace(item)
+		if (*list)[i] == "" {
+			errs[fmt.Sprintf("%s[%d]", field, i)] = "must not be empty"
+		}
+	}
+}
+
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func decodeCourseInput(t *testing.T, payload string) *CourseInput {
+	t.Helper()
+	var input CourseInput
+	if err := json.Unmarshal([]byte(payload), &input); err != nil {
+		t.Fatal(err)
+	}
+	return &input
+}
+
+func errorFields(errs FieldErrors) []string {
+	fields := []string{}
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestCourseInputValidate(t *testing.T) {
+	long := strings.Repeat("a", 201)
+
+	tests := []struct {
+		name     string
+		payload  string
+		creating bool
+		want     []string // fields with errors
+	}{
+		{"minimal course", `{"title": "Go"}`, true, []string{}},
+		{"title required on create", `{"price": 10}`, true, []string{"title"}},
+		{"title optional on update", `{"price": 10}`, false, []string{}},
+		{"blank title", `{"title": "   "}`, false, []string{"title"}},
+		{"long title", `{"title": "` + long + `"}`, false, []string{"title"}},
+
+		{"free course", `{"price": 0}`, false, []string{}},
+		{"negative price", `{"price": -0.01}`, false, []string{"price"}},
+		{"negative lesson count", `{"lessonCount": -1}`, false, []string{"lessonCount"}},
+
+		{"known level", `{"level": "advanced"}`, false, []string{}},
+		{"level in other case", `{"level": " All-Levels "}`, false, []string{}},
+		{"unknown level", `{"level": "expert"}`, false, []string{"level"}},
+		{"known type", `{"type": "Professional"}`, false, []string{}},
+		{"unknown type", `{"type": "hobby"}`, false, []string{"type"}},
+
+		{"http image", `{"imageUrl": "https://cdn.example.com/go.png"}`, false, []string{}},
+		{"image without scheme", `{"imageUrl": "cdn.example.com/go.png"}`, false, []string{"imageUrl"}},
+		{"javascript image", `{"imageUrl": "javascript:alert(1)"}`, false, []string{"imageUrl"}},
+
+		{"tags", `{"tags": ["go", " web "]}`, false, []string{}},
+		{"empty tag", `{"tags": ["go", " "]}`, false, []string{"tags[1]"}},
+
+		{"curriculum", `{"curriculum": [{"week": 1, "title": "Basics", "topics": ["types"]}]}`, false, []string{}},
+		{"curriculum item without title", `{"curriculum": [{"week": 1}, {"title": " "}]}`, false, []string{"curriculum[0].title", "curriculum[1].title"}},
+		{"curriculum negative week", `{"curriculum": [{"week": -1, "title": "Basics"}]}`, false, []string{"curriculum[0].week"}},
+		{"curriculum empty topic", `{"curriculum": [{"title": "Basics", "topics": ["types", ""]}]}`, false, []string{"curriculum[0].topics[1]"}},
+
+		{"tutor", `{"tutor": {"name": "Ada", "yearsExp": 3, "avatar": "https://example.com/a.png"}}`, false, []string{}},
+		{"tutor negative experience", `{"tutor": {"yearsExp": -1, "avatar": "ftp://example.com/a.png"}}`, false, []string{"tutor.avatar", "tutor.yearsExp"}},
+
+		{"metadata", `{"metadata": {"passingGrade": 100, "maxCapacity": 30}}`, false, []string{}},
+		{"metadata out of range", `{"metadata": {"passingGrade": 101, "quizCount": -1}}`, false, []string{"metadata.passingGrade", "metadata.quizCount"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(decodeCourseInput(t, tt.payload).Validate(tt.creating))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%s) errors on %v, want %v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCourseInputValidateNormalizes(t *testing.T) {
+	input := decodeCourseInput(t, `{
+		"title": "  Go  ",
+		"level": "BEGINNER",
+		"tags": [" go "],
+		"curriculum": [{"title": " Basics "}]
+	}`)
+	if errs := input.Validate(true); errs != nil {
+		t.Fatal(errs)
+	}
+
+	if *input.Title != "Go" || *input.Level != "beginner" || (*input.Tags)[0] != "go" {
+		t.Errorf("Validate left title %q, level %q, tags %q", *input.Title, *input.Level, *input.Tags)
+	}
+	item := (*input.Curriculum)[0]
+	if item.Title != "Basics" || item.Topics == nil {
+		t.Errorf("Validate left curriculum item %+v, want a trimmed title and empty topics", item)
+	}
+}
+
+func TestIsCourseStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{CourseStatusDraft, true},
+		{CourseStatusInReview, true},
+		{CourseStatusPublished, true},
+		{CourseStatusArchived, true},
+		{"Published", false}, // callers lower-case first
+		{"deleted", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCourseStatus(tt.status); got != tt.want {
+			t.Errorf("IsCourseStatus(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestCourseInputTutorRestricted(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{"tutor fields", `{"title": "Go", "price": 10, "curriculum": []}`, []string{}},
+		{"featured", `{"isFeatured": false}`, []string{"isFeatured"}},
+		{"other tutor", `{"tutor": {"name": "Ada"}, "tutorId": "t-1"}`, []string{"tutor", "tutorId"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(decodeCourseInput(t, tt.payload).TutorRestricted())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TutorRestricted(%s) errors on %v, want %v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCourseInputFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bson.M
+	}{
+		{"nothing provided", `{}`, bson.M{}},
+		{"only provided fields", `{"title": "Go", "price": 0}`, bson.M{"title": "Go", "price": 0.0}},
+		{"false and zero are provided", `{"isFeatured": false, "certificate": false, "lessonCount": 0}`,
+			bson.M{"isFeatured": false, "certificate": false, "lessonCount": 0}},
+		{"empty lists stay lists", `{"tags": [], "curriculum": []}`, bson.M{"tags": []string{}, "curriculum": []CurriculumItem{}}},
+		{"null is not provided", `{"tags": null, "title": null}`, bson.M{}},
+		{"bson names", `{"imageUrl": "https://example.com/go.png", "learningGoals": ["x"]}`,
+			bson.M{"imageUrl": "https://example.com/go.png", "learningGoals": []string{"x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decodeCourseInput(t, tt.payload).Fields()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields(%s) = %v, want %v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+// Fields is the update allowlist: every field it can write is one CourseInput
+// declares, and none of them is managed by the server
+func TestCourseInputFieldsAllowlist(t *testing.T) {
+	managed := map[string]bool{
+		"id": true, "_id": true, "status": true, "isActive": true, "createdAt": true, "updatedAt": true,
+		"enrollmentCount": true, "rating": true, "reviewCount": true, CourseRevisionField: true,
+	}
+
+	var all CourseInput
+	inputType := reflect.TypeOf(all)
+	payload := map[string]interface{}{}
+	for i := 0; i < inputType.NumField(); i++ {
+		name := strings.Split(inputType.Field(i).Tag.Get("json"), ",")[0]
+		if managed[name] {
+			t.Errorf("CourseInput accepts the server-managed field %q", name)
+		}
+		// A zero value of each type; lists must be empty rather than null to count as provided
+		value := reflect.New(inputType.Field(i).Type.Elem()).Elem()
+		if value.Kind() == reflect.Slice {
+			value = reflect.MakeSlice(value.Type(), 0, 0)
+		}
+		payload[name] = value.Interface()
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := decodeCourseInput(t, string(data)).Fields()
+	if len(fields) != inputType.NumField() {
+		t.Errorf("Fields wrote %d fields for %d provided", len(fields), inputType.NumField())
+	}
+	for name := range fields {
+		if _, ok := payload[name]; !ok {
+			t.Errorf("Fields wrote %q, which CourseInput doesn't declare", name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// What caused a course revision
+const (
+	CourseRevisionBaseline     = "baseline" // The course as it was when revisions were introduced
+	CourseRevisionCreate       = "create"
+	CourseRevisionUpdate       = "update"
+	CourseRevisionStatus       = "status"
+	CourseRevisionSchedule     = "schedule"
+	CourseRevisionDelete       = "delete"
+	CourseRevisionRollback     = "rollback"
+	CourseRevisionTutorProfile = "tutor_profile" // The tutor's profile was copied onto the course
+)
+
+// CourseRevisionField - The counter on each course that numbers its
+// revisions. It is bumped by the same write that changes the course, so
+// numbers follow the order in which changes were made.
+const CourseRevisionField = "revision"
+
+// CourseRevision - One change to a course, stored in course_revisions and
+// never modified. Revisions are numbered per course; a change that touches
+// no tracked field leaves a gap. Each keeps the whole course as it was after
+// the change, so any revision can be restored.
+type CourseRevision struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CourseID     string             `bson:"courseId" json:"courseId"`
+	Number       int                `bson:"number" json:"number"`
+	Action       string             `bson:"action" json:"action"`
+	AuthorID     string             `bson:"authorId" json:"authorId"`
+	AuthorEmail  string             `bson:"authorEmail,omitempty" json:"authorEmail,omitempty"`
+	AuthorRole   string             `bson:"authorRole" json:"authorRole"`
+	RestoredFrom int                `bson:"restoredFrom,omitempty" json:"restoredFrom,omitempty"`
+	Fields       []string           `bson:"fields" json:"fields"`
+	Snapshot     bson.M             `bson:"snapshot" json:"-"`
+	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
+
+	// The tracked fields before and after, as read by the write itself
+	Changes []CourseFieldChange `bson:"changes" json:"changes"`
+}
+
+// CourseFieldChange - A field before and after a revision; null when the
+// field wasn't set
+type CourseFieldChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+// CourseChange - A stored course right before and after one write, and the
+// revision number the write was given
+type CourseChange struct {
+	Revision int
+	Before   bson.M // nil for a new course
+	After    bson.M
+}
+
+// courseUntrackedFields - Fields kept out of revisions: the internal id, the
+// revision counter, and what changes on its own as students enroll and review
+var courseUntrackedFields = []string{"_id", CourseRevisionField, "updatedAt", "enrollmentCount", "rating", "reviewCount"}
+
+// CourseWorkflowFields - Fields only the review workflow changes, which a
+// rollback leaves as they are
+var CourseWorkflowFields = []string{
+	"id", "createdAt", "status", "isActive", "publishedAt", "archivedAt",
+	"scheduledPublishAt", "scheduledUnpublishAt",
+}
+
+// CourseSnapshot - The tracked fields of a stored course
+func CourseSnapshot(course bson.M) bson.M {
+	snapshot := bson.M{}
+	for field, value := range course {
+		snapshot[field] = value
+	}
+	for _, field := range courseUntrackedFields {
+		delete(snapshot, field)
+	}
+	return snapshot
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CourseTransitions - The statuses a course may move to from each status:
+// tutors submit drafts for review, reviewers publish them or send them back
+// with comments, and published courses are eventually archived. An archived
+// course goes back through review before it is published again.
+var CourseTransitions = map[string][]string{
+	CourseStatusDraft:     {CourseStatusInReview},
+	CourseStatusInReview:  {CourseStatusDraft, CourseStatusPublished},
+	CourseStatusPublished: {CourseStatusArchived},
+	CourseStatusArchived:  {CourseStatusDraft},
+}
+
+// CanTransition reports whether a course in status from may move to status to
+func CanTransition(from, to string) bool {
+	for _, allowed := range CourseTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCourseStatus reports whether status is one of CourseStatuses
+func IsCourseStatus(status string) bool {
+	for _, candidate := range CourseStatuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CourseComment - A note on a course from a reviewer or its tutor, stored in
+// course_comments. Status changes are recorded as comments too, with the
+// status before and after.
+type CourseComment struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CourseID    string             `bson:"courseId" json:"courseId"`
+	AuthorID    string             `bson:"authorId" json:"authorId"`
+	AuthorEmail string             `bson:"authorEmail,omitempty" json:"authorEmail,omitempty"`
+	AuthorRole  string             `bson:"authorRole" json:"authorRole"`
+	Comment     string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	FromStatus  string             `bson:"fromStatus,omitempty" json:"fromStatus,omitempty"`
+	ToStatus    string             `bson:"toStatus,omitempty" json:"toStatus,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
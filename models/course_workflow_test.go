@@ -0,0 +1,58 @@
+package models
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{CourseStatusDraft, CourseStatusInReview, true},
+		{CourseStatusDraft, CourseStatusPublished, false},
+		{CourseStatusDraft, CourseStatusArchived, false},
+		{CourseStatusDraft, CourseStatusDraft, false},
+
+		{CourseStatusInReview, CourseStatusDraft, true},
+		{CourseStatusInReview, CourseStatusPublished, true},
+		{CourseStatusInReview, CourseStatusArchived, false},
+		{CourseStatusInReview, CourseStatusInReview, false},
+
+		{CourseStatusPublished, CourseStatusArchived, true},
+		{CourseStatusPublished, CourseStatusDraft, false},
+		{CourseStatusPublished, CourseStatusInReview, false},
+		{CourseStatusPublished, CourseStatusPublished, false},
+
+		// Archived courses go back through review
+		{CourseStatusArchived, CourseStatusDraft, true},
+		{CourseStatusArchived, CourseStatusPublished, false},
+		{CourseStatusArchived, CourseStatusInReview, false},
+
+		{"", CourseStatusDraft, false},
+		{"deleted", CourseStatusDraft, false},
+		{CourseStatusDraft, "deleted", false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCourseTransitionsUseKnownStatuses(t *testing.T) {
+	for from, targets := range CourseTransitions {
+		if !IsCourseStatus(from) {
+			t.Errorf("transitions from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !IsCourseStatus(to) {
+				t.Errorf("transition %q -> unknown status %q", from, to)
+			}
+		}
+	}
+	for _, status := range CourseStatuses {
+		if len(CourseTransitions[status]) == 0 {
+			t.Errorf("status %q can't move anywhere", status)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of login attempt counters
+const (
+	LoginAttemptAccount = "account"
+	LoginAttemptIP      = "ip"
+)
+
+// LoginAttempt counts recent failed logins for one account or one client IP.
+// Documents are shared by every API instance; expiresAt marks when the entry
+// is stale and can be removed.
+type LoginAttempt struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key           string             `bson:"key" json:"key"`
+	Kind          string             `bson:"kind" json:"kind"`
+	AccountType   string             `bson:"accountType,omitempty" json:"accountType,omitempty"`
+	Identifier    string             `bson:"identifier" json:"identifier"`
+	Failures      int                `bson:"failures" json:"failures"`
+	Lockouts      int                `bson:"lockouts" json:"lockouts"`
+	LastFailureAt time.Time          `bson:"lastFailureAt" json:"lastFailureAt"`
+	LockedUntil   *time.Time         `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"`
+	ExpiresAt     time.Time          `bson:"expiresAt" json:"expiresAt"`
+}
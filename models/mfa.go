@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// MFASettings is stored under "mfa" on both student and admin documents.
+// Recovery codes are stored as SHA-256 hashes and removed when used.
+type MFASettings struct {
+	Enabled            bool       `bson:"enabled" json:"enabled"`
+	Secret             string     `bson:"secret,omitempty" json:"-"`
+	RecoveryCodeHashes []string   `bson:"recoveryCodeHashes,omitempty" json:"-"`
+	LastUsedStep       int64      `bson:"lastUsedStep,omitempty" json:"-"`
+	EnabledAt          *time.Time `bson:"enabledAt,omitempty" json:"enabledAt,omitempty"`
+
+	// Set by enrollment and promoted to Secret/RecoveryCodeHashes on confirmation
+	PendingSecret             string   `bson:"pendingSecret,omitempty" json:"-"`
+	PendingRecoveryCodeHashes []string `bson:"pendingRecoveryCodeHashes,omitempty" json:"-"`
+}
+
+// MFAEnrollment is shown to the user once, when they start enrolling
+type MFAEnrollment struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauthUri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// LoginResult is either a token pair, or a challenge that must be completed
+// at POST /login/mfa before tokens are issued
+type LoginResult struct {
+	Tokens            *TokenPair `json:"tokens,omitempty"`
+	MFARequired       bool       `json:"mfaRequired"`
+	MFAEnrollRequired bool       `json:"mfaEnrollRequired,omitempty"`
+	MFAToken          string     `json:"mfaToken,omitempty"`
+}
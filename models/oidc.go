@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExternalIdentity links a student to an account at an OIDC provider. The
+// provider + subject pair is what identifies them on later logins.
+type ExternalIdentity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"-"`
+	Email    string    `bson:"email" json:"email"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// OIDCState is a pending authorization request, stored between the redirect
+// to the provider and its callback. Only the hashes of the state and of the
+// browser nonce are stored; it is deleted when the callback uses it.
+type OIDCState struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	StateHash        string             `bson:"stateHash"`
+	BrowserNonceHash string             `bson:"browserNonceHash"`
+	Provider         string             `bson:"provider"`
+	Nonce            string             `bson:"nonce"`
+	CodeVerifier     string             `bson:"codeVerifier"`
+	ExpiresAt        time.Time          `bson:"expiresAt"`
+	CreatedAt        time.Time          `bson:"createdAt"`
+}
+
+// OIDCAuthorization is a started social login: where to send the browser,
+// and the nonce the browser must bring back to the callback (in a cookie) so
+// a callback URL can't be replayed in someone else's browser.
+type OIDCAuthorization struct {
+	URL          string
+	BrowserNonce string
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Account types that can own a password reset token
+const (
+	AccountTypeStudent = "student"
+	AccountTypeAdmin   = "admin"
+	AccountTypeTutor   = "tutor"
+)
+
+// PasswordReset is a single-use reset token. Only the SHA-256 hash of the
+// token is stored; the plain token only ever exists in the email.
+type PasswordReset struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash   string             `bson:"tokenHash" json:"-"`
+	AccountType string             `bson:"accountType" json:"accountType"`
+	AccountID   primitive.ObjectID `bson:"accountId" json:"accountId"`
+	Email       string             `bson:"email" json:"email"`
+	ExpiresAt   time.Time          `bson:"expiresAt" json:"expiresAt"`
+	UsedAt      *time.Time         `bson:"usedAt,omitempty" json:"usedAt,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
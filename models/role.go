@@ -0,0 +1,106 @@
+package models
+
+// Roles carried in the JWT "role" claim
+const (
+	RoleStudent      = "student"
+	RoleTutor        = "tutor"
+	RoleContentAdmin = "content-admin"
+	RoleSuperAdmin   = "super-admin"
+)
+
+// Legacy role names still found in older tokens and admin documents
+const (
+	legacyRoleUser  = "user"
+	legacyRoleAdmin = "admin"
+)
+
+// Permission is a single capability checked by middleware.RequirePermission
+type Permission string
+
+const (
+	PermCourseCreate    Permission = "courses:create"
+	PermCourseUpdate    Permission = "courses:update"
+	PermCourseDelete    Permission = "courses:delete"
+	PermReviewModerate  Permission = "reviews:moderate"
+	PermAdminManage     Permission = "admins:manage"
+	PermUserManage      Permission = "users:manage"
+	PermUserImpersonate Permission = "users:impersonate"
+	PermAuditView       Permission = "audit:view"
+	PermCoursePublish   Permission = "courses:publish"
+	PermTutorManage     Permission = "tutors:manage"
+	PermCourseAuthor    Permission = "courses:author"
+)
+
+// RolePermissions - What each role is allowed to do. Students act on their own
+// data through /user routes, which only need authentication. Tutors author
+// their own draft courses through /tutor routes.
+var RolePermissions = map[string][]Permission{
+	RoleStudent: {},
+	RoleTutor: {
+		PermCourseAuthor,
+	},
+	RoleContentAdmin: {
+		PermCourseCreate,
+		PermCourseUpdate,
+		PermCourseDelete,
+		PermCoursePublish,
+		PermReviewModerate,
+		PermTutorManage,
+	},
+	RoleSuperAdmin: {
+		PermCourseCreate,
+		PermCourseUpdate,
+		PermCourseDelete,
+		PermCoursePublish,
+		PermReviewModerate,
+		PermTutorManage,
+		PermAdminManage,
+		PermUserManage,
+		PermUserImpersonate,
+		PermAuditView,
+	},
+}
+
+// NormalizeRole maps legacy role names ("user", "admin", empty) onto the
+// current role model
+func NormalizeRole(role string) string {
+	switch role {
+	case "", legacyRoleUser:
+		return RoleStudent
+	case legacyRoleAdmin:
+		return RoleSuperAdmin
+	}
+	return role
+}
+
+// IsAdminRole reports whether the role belongs to an account in the admins collection
+func IsAdminRole(role string) bool {
+	role = NormalizeRole(role)
+	return role == RoleContentAdmin || role == RoleSuperAdmin
+}
+
+// IsValidAdminRole reports whether an admin account may be given this role
+func IsValidAdminRole(role string) bool {
+	return role == RoleContentAdmin || role == RoleSuperAdmin
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role string, perm Permission) bool {
+	for _, p := range RolePermissions[NormalizeRole(role)] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountTypeForRole - Which collection an account with this role lives in
+func AccountTypeForRole(role string) string {
+	if IsAdminRole(role) {
+		return AccountTypeAdmin
+	}
+	if NormalizeRole(role) == RoleTutor {
+		return AccountTypeTutor
+	}
+	return AccountTypeStudent
+}
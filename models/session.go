@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session is a login on one device. It owns the rotating refresh token; only
+// hashes of refresh tokens are stored.
+type Session struct {
+	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AccountType        string             `bson:"accountType" json:"accountType"`
+	AccountID          string             `bson:"accountId" json:"accountId"`
+	Email              string             `bson:"email" json:"email"`
+	Role               string             `bson:"role" json:"role"`
+	RefreshTokenHash   string             `bson:"refreshTokenHash" json:"-"`
+	RotatedTokenHashes []string           `bson:"rotatedTokenHashes,omitempty" json:"-"`
+	ExpiresAt          time.Time          `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt          time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt          time.Time          `bson:"updatedAt" json:"updatedAt"`
+	RevokedAt          *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	RevokedReason      string             `bson:"revokedReason,omitempty" json:"revokedReason,omitempty"`
+
+	// Device info, refreshed at most once a minute while the session is used
+	UserAgent  string     `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	IP         string     `bson:"ip,omitempty" json:"ip,omitempty"`
+	LastSeenAt *time.Time `bson:"lastSeenAt,omitempty" json:"lastSeenAt,omitempty"`
+
+	// Current marks the session of the request listing them; not stored
+	Current bool `bson:"-" json:"current"`
+}
+
+// RevokedToken is an entry in the access token revocation list, keyed by the
+// JWT "jti" claim. Entries can be dropped once the token would have expired.
+type RevokedToken struct {
+	JTI       string    `bson:"jti" json:"jti"`
+	Reason    string    `bson:"reason" json:"reason"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+	RevokedAt time.Time `bson:"revokedAt" json:"revokedAt"`
+}
+
+// TokenPair is returned by login and refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+	SessionID    string `json:"sessionId"`
+}
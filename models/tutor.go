@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TutorAccount is a tutor who can log in and author courses. Stored in the
+// tutors collection; courses link to it by TutorID and embed a copy of the
+// display fields (see Tutor), kept in sync when the profile changes.
+type TutorAccount struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Email       string             `bson:"email" json:"email"`
+	Password    string             `bson:"password" json:"-"`
+	Name        string             `bson:"name" json:"name"`
+	Bio         string             `bson:"bio,omitempty" json:"bio,omitempty"`
+	Avatar      string             `bson:"avatar,omitempty" json:"avatar,omitempty"`
+	Expertise   string             `bson:"expertise,omitempty" json:"expertise,omitempty"`
+	YearsExp    int                `bson:"yearsExp,omitempty" json:"yearsExp,omitempty"`
+	Credentials string             `bson:"credentials,omitempty" json:"credentials,omitempty"`
+	IsActive    bool               `bson:"isActive" json:"isActive"`
+	InvitedBy   string             `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt" json:"updatedAt"`
+
+	// Tokens issued before this moment are rejected by the auth middleware
+	PasswordChangedAt *time.Time `bson:"passwordChangedAt,omitempty" json:"-"`
+
+	// Optional two-factor authentication
+	MFA *MFASettings `bson:"mfa,omitempty" json:"mfa,omitempty"`
+}
+
+// DisplayProfile - The fields embedded in courses as Course.Tutor
+func (t *TutorAccount) DisplayProfile() *Tutor {
+	return &Tutor{
+		Name:        t.Name,
+		Bio:         t.Bio,
+		Avatar:      t.Avatar,
+		Email:       t.Email,
+		Expertise:   t.Expertise,
+		YearsExp:    t.YearsExp,
+		Credentials: t.Credentials,
+	}
+}
@@ -10,18 +10,59 @@ ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 Name      string             `bson:"name" json:"name"`
 Email     string             `bson:"email" json:"email"`
 Password  string             `bson:"password" json:"-"`
-Code      string             `bson:"code,omitempty" json:"code,omitempty"`
+Code      string             `bson:"code,omitempty" json:"-"`
 Verified  bool               `bson:"verified" json:"verified"`
 CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+
+// Email verification
+CodeExpiresAt *time.Time `bson:"code_expires_at,omitempty" json:"-"`
+CodeSentAt    *time.Time `bson:"code_sent_at,omitempty" json:"-"`
+VerifiedAt    *time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+
+// Tokens issued before this moment are rejected by the auth middleware
+PasswordChangedAt *time.Time `bson:"password_changed_at,omitempty" json:"-"`
+
+// Two-factor authentication (optional for students)
+MFA *MFASettings `bson:"mfa,omitempty" json:"mfa,omitempty"`
+
+// Profile
+Avatar      string           `bson:"avatar,omitempty" json:"avatar,omitempty"`
+ClassLevel  string           `bson:"class_level,omitempty" json:"class_level,omitempty"`
+Language    string           `bson:"language,omitempty" json:"language,omitempty"`
+Preferences *UserPreferences `bson:"preferences,omitempty" json:"preferences,omitempty"`
+
+// Social login (Google, Microsoft, ...) identities linked by verified email
+Identities []ExternalIdentity `bson:"identities,omitempty" json:"identities,omitempty"`
+
+// Account deletion: the student can't sign in once requested, and the
+// record is removed for good at deletion_scheduled_at
+DeletionRequestedAt *time.Time `bson:"deletion_requested_at,omitempty" json:"deletion_requested_at,omitempty"`
+DeletionScheduledAt *time.Time `bson:"deletion_scheduled_at,omitempty" json:"deletion_scheduled_at,omitempty"`
+}
+
+// UserPreferences - Student settings stored with the profile
+type UserPreferences struct {
+EmailNotifications bool   `bson:"email_notifications" json:"email_notifications"`
+Theme              string `bson:"theme,omitempty" json:"theme,omitempty"` // light, dark or system
 }
 
 // Add this Admin struct - keep it separate from User
 type Admin struct {
     ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
     Email     string             `bson:"email" json:"email"`
-    Password  string             `bson:"password" json:"password"`
+    Password  string             `bson:"password" json:"-"`
     Name      string             `json:"name" bson:"name"`
     CreatedAt primitive.DateTime `bson:"createdAt" json:"createdAt"`
     IsActive  bool               `bson:"isActive" json:"isActive"`
+    Role      string             `bson:"role,omitempty" json:"role"` // content-admin or super-admin; empty means super-admin
+
+    UpdatedAt time.Time          `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+    InvitedBy string             `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+
+    // Tokens issued before this moment are rejected by the auth middleware
+    PasswordChangedAt *time.Time `bson:"passwordChangedAt,omitempty" json:"-"`
+
+    // Two-factor authentication (mandatory when REQUIRE_ADMIN_MFA is on)
+    MFA *MFASettings `bson:"mfa,omitempty" json:"mfa,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,101 @@
+package oidc
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// Config - One OpenID Connect provider, read from the environment.
+//
+// OIDC_PROVIDERS lists the enabled provider names (e.g. "google,microsoft").
+// Each name is then configured with upper-cased variables:
+//
+//	OIDC_GOOGLE_ISSUER=https://accounts.google.com
+//	OIDC_GOOGLE_CLIENT_ID=...
+//	OIDC_GOOGLE_CLIENT_SECRET=...
+//	OIDC_GOOGLE_SCOPES=openid email profile          (optional)
+//	OIDC_GOOGLE_REDIRECT_URL=https://api/.../callback (optional, see RedirectURL)
+//	OIDC_GOOGLE_TRUST_EMAIL=false                     (optional)
+//
+// Any issuer that serves /.well-known/openid-configuration works, including a
+// mock issuer on http://localhost for local testing.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	RedirectURL  string
+
+	// TrustEmail treats the email claim as verified even without
+	// email_verified. Only for providers that never send that claim (e.g.
+	// Microsoft Entra work/school accounts) and whose tenants own their domains.
+	TrustEmail bool
+}
+
+var (
+	providers     map[string]*Provider
+	providersOnce sync.Once
+)
+
+// loadConfigs - Every provider named in OIDC_PROVIDERS that has an issuer and client ID
+func loadConfigs() []Config {
+	var configs []Config
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		config := Config{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(utils.GetEnv(prefix+"SCOPES", "openid email profile")),
+			RedirectURL:  utils.GetEnv(prefix+"REDIRECT_URL", defaultRedirectURL(name)),
+			TrustEmail:   utils.GetEnvBool(prefix+"TRUST_EMAIL", false),
+		}
+		if config.Issuer == "" || config.ClientID == "" {
+			continue
+		}
+		configs = append(configs, config)
+	}
+	return configs
+}
+
+// defaultRedirectURL - <OIDC_REDIRECT_BASE_URL>/auth/oidc/<name>/callback
+func defaultRedirectURL(name string) string {
+	base := strings.TrimRight(utils.GetEnv("OIDC_REDIRECT_BASE_URL", "http://localhost:8080"), "/")
+	return base + "/auth/oidc/" + name + "/callback"
+}
+
+func loadProviders() {
+	providers = map[string]*Provider{}
+	for _, config := range loadConfigs() {
+		providers[config.Name] = NewProvider(config)
+	}
+}
+
+// Get - The configured provider with this name
+func Get(name string) (*Provider, bool) {
+	providersOnce.Do(loadProviders)
+	provider, ok := providers[strings.ToLower(name)]
+	return provider, ok
+}
+
+// Names - Configured provider names, sorted
+func Names() []string {
+	providersOnce.Do(loadProviders)
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,24 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+// NewCodeVerifier - PKCE code verifier (RFC 7636), 43 URL-safe characters
+func NewCodeVerifier() (string, error) {
+	return utils.GenerateSecureToken(32)
+}
+
+// CodeChallenge - S256 challenge for a verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewState - Opaque value for the state and nonce parameters
+func NewState() (string, error) {
+	return utils.GenerateSecureToken(32)
+}
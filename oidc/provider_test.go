@@ -0,0 +1,185 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testRedirectURL = "http://api.test/auth/oidc/mock/callback"
+
+var testIdentity = oidctest.Identity{
+	Subject:       "user-1",
+	Email:         "Ada@Example.com",
+	EmailVerified: true,
+	Name:          "Ada",
+}
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Issuer) {
+	t.Helper()
+	issuer := oidctest.NewIssuer("client-1")
+	t.Cleanup(issuer.Close)
+	issuer.SignIn(testIdentity)
+
+	return NewProvider(Config{
+		Name:        "mock",
+		Issuer:      issuer.URL,
+		ClientID:    issuer.ClientID,
+		Scopes:      []string{"openid", "email"},
+		RedirectURL: testRedirectURL,
+	}), issuer
+}
+
+// authorize - Starts a login and returns the code the issuer sent back
+func authorize(t *testing.T, provider *Provider, issuer *oidctest.Issuer, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback, err := issuer.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("callback state = %q, want %q", got, state)
+	}
+	return callback.Query().Get("code")
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636, appendix B
+	got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallenge = %q, want %q", got, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             issuer.ClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := parsed.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if parsed.Query().Has("code_verifier") {
+		t.Error("the authorization URL must not carry the code verifier")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name         string
+		verifier     string // sent with the code; "" = the one used to authorize
+		nonce        string // expected by the client; "" = the one used to authorize
+		modifyClaims func(jwt.MapClaims)
+		redeemTwice  bool
+		wantErr      bool
+	}{
+		{name: "valid"},
+		{name: "wrong code verifier", verifier: "another-verifier", wantErr: true},
+		{name: "code redeemed twice", redeemTwice: true, wantErr: true},
+		{name: "nonce mismatch", nonce: "another-nonce", wantErr: true},
+		{name: "token without nonce", modifyClaims: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: true},
+		{name: "other audience", modifyClaims: func(c jwt.MapClaims) { c["aud"] = "client-2" }, wantErr: true},
+		{name: "other issuer", modifyClaims: func(c jwt.MapClaims) { c["iss"] = "https://evil.test" }, wantErr: true},
+		{name: "expired token", modifyClaims: func(c jwt.MapClaims) { c["exp"] = 1 }, wantErr: true},
+		{name: "missing subject", modifyClaims: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, issuer := newTestProvider(t)
+			issuer.ModifyClaims = tt.modifyClaims
+
+			verifier, err := NewCodeVerifier()
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce, err := NewState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			code := authorize(t, provider, issuer, "state-1", nonce, verifier)
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+			if tt.redeemTwice {
+				if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err != nil {
+					t.Fatalf("first Exchange: %v", err)
+				}
+			}
+
+			identity, err := provider.Exchange(context.Background(), code, verifier, nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Exchange = %+v, want an error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Identity{Subject: "user-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada"}
+			if *identity != want {
+				t.Errorf("Exchange = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+}
+
+func TestTrustEmail(t *testing.T) {
+	provider, issuer := newTestProvider(t)
+	issuer.ModifyClaims = func(c jwt.MapClaims) { delete(c, "email_verified") }
+
+	rawIDToken, err := issuer.SignIDToken(testIdentity, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.EmailVerified {
+		t.Error("email without email_verified must not count as verified")
+	}
+
+	provider.Config.TrustEmail = true
+	identity, err = provider.VerifyIDToken(context.Background(), rawIDToken, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !identity.EmailVerified {
+		t.Error("TrustEmail must treat the email as verified")
+	}
+}
@@ -0,0 +1,510 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserRepository - Student accounts (the students collection, snake_case)
+type UserRepository interface {
+	// FindByID and FindByEmail return services.ErrUserNotFound when there is no such student
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+
+	// Insert returns services.ErrUserExists when the email is taken
+	Insert(ctx context.Context, user *models.User) error
+
+	// Update sets and unsets top-level fields. Returns services.ErrUserNotFound
+	// when there is no such student.
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M, unset ...string) error
+
+	// FindByIdentity returns the student linked to the provider's subject, or
+	// services.ErrUserNotFound
+	FindByIdentity(ctx context.Context, provider, subject string) (*models.User, error)
+
+	// FindByEmailFold is FindByEmail ignoring case
+	FindByEmailFold(ctx context.Context, email string) (*models.User, error)
+
+	// AddIdentity links an external identity and updates fields like Update
+	AddIdentity(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity, set bson.M, unset ...string) error
+
+	// ListDueForDeletion returns the students whose deletion was scheduled for now or earlier
+	ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error)
+
+	// Delete returns services.ErrUserNotFound when there is no such student
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// AdminRepository - Admin accounts (the admins collection, camelCase)
+type AdminRepository interface {
+	// FindByID and FindByEmail return services.ErrAdminNotFound when there is
+	// no such admin. Admins created before isActive existed come back active.
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admin, error)
+	FindByEmail(ctx context.Context, email string) (*models.Admin, error)
+
+	// List returns every admin, newest first
+	List(ctx context.Context) ([]models.Admin, error)
+
+	// Insert returns services.ErrAdminExists when the email is taken
+	Insert(ctx context.Context, admin *models.Admin) error
+
+	// Update sets top-level fields. Returns services.ErrAdminNotFound when
+	// there is no such admin.
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+
+	// CountActiveSuperAdmins counts the active admins acting as super-admins
+	// (see superAdminRoles), leaving out exclude
+	CountActiveSuperAdmins(ctx context.Context, exclude primitive.ObjectID) (int64, error)
+}
+
+// superAdminRoles - Stored roles that act as super-admin; admins created
+// before roles existed have "admin", an empty role or none at all
+var superAdminRoles = []interface{}{models.RoleSuperAdmin, "admin", "", nil}
+
+// TutorRepository - Tutor accounts (the tutors collection, camelCase)
+type TutorRepository interface {
+	// List returns every tutor, newest first
+	List(ctx context.Context) ([]models.TutorAccount, error)
+
+	// FindByID and FindByEmail return services.ErrTutorNotFound when there is no such tutor
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.TutorAccount, error)
+	FindByEmail(ctx context.Context, email string) (*models.TutorAccount, error)
+
+	// Insert returns services.ErrTutorExists when the email is taken
+	Insert(ctx context.Context, tutor *models.TutorAccount) error
+
+	// Update sets top-level fields. Returns services.ErrTutorNotFound when
+	// there is no such tutor.
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+}
+
+// Account - What every kind of account has, whatever its collection calls
+// the fields
+type Account struct {
+	ID    primitive.ObjectID
+	Name  string
+	Email string
+
+	// False for deactivated accounts and students awaiting deletion. Admins
+	// created before isActive existed are active.
+	IsActive bool
+
+	// Tokens issued before this are no longer accepted
+	PasswordChangedAt *time.Time
+
+	MFA *models.MFASettings // nil until two-factor enrollment starts
+}
+
+// AccountRepository - Passwords and two-factor settings of one kind of
+// account. Lookups and updates return services.ErrAccountNotFound when there
+// is no such account.
+type AccountRepository interface {
+	FindByID(ctx context.Context, id primitive.ObjectID) (*Account, error)
+	FindByEmail(ctx context.Context, email string) (*Account, error)
+
+	// SetPassword stores a new password hash and moves the password-changed marker
+	SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string, now time.Time) error
+
+	// BeginMFA keeps a secret and recovery codes until the enrollment is confirmed
+	BeginMFA(ctx context.Context, id primitive.ObjectID, secret string, recoveryCodeHashes []string) error
+
+	// SetMFA replaces the two-factor settings; nil removes them
+	SetMFA(ctx context.Context, id primitive.ObjectID, settings *models.MFASettings, now time.Time) error
+
+	// UseMFAStep records a TOTP time step as used; false if it or a later
+	// step was used already
+	UseMFAStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error)
+
+	// UseRecoveryCode removes a recovery code hash; false if the account doesn't have it
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error)
+}
+
+// Accounts - The AccountRepository of each account type
+type Accounts struct {
+	Students AccountRepository
+	Admins   AccountRepository
+	Tutors   AccountRepository
+}
+
+// For - The repository of a models.AccountType*; services.ErrUnknownAccountType for anything else
+func (a Accounts) For(accountType string) (AccountRepository, error) {
+	switch accountType {
+	case models.AccountTypeStudent:
+		return a.Students, nil
+	case models.AccountTypeAdmin:
+		return a.Admins, nil
+	case models.AccountTypeTutor:
+		return a.Tutors, nil
+	}
+	return nil, services.ErrUnknownAccountType
+}
+
+// accountFields - Names of the fields that differ between the students
+// collection (snake_case) and the admins and tutors collections (camelCase)
+type accountFields struct {
+	passwordChanged string
+	updated         string
+}
+
+var (
+	studentAccountFields = accountFields{passwordChanged: "password_changed_at", updated: "updated_at"}
+	staffAccountFields   = accountFields{passwordChanged: "passwordChangedAt", updated: "updatedAt"}
+)
+
+// deletionRequestedField is set on students awaiting deletion
+const deletionRequestedField = "deletion_requested_at"
+
+func decodeAccount(raw bson.Raw, fields accountFields) (*Account, error) {
+	var doc struct {
+		ID    primitive.ObjectID  `bson:"_id"`
+		Name  string              `bson:"name"`
+		Email string              `bson:"email"`
+		MFA   *models.MFASettings `bson:"mfa"`
+	}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	account := &Account{ID: doc.ID, Name: doc.Name, Email: doc.Email, IsActive: true, MFA: doc.MFA}
+	if active, ok := raw.Lookup("isActive").BooleanOK(); ok {
+		account.IsActive = active
+	}
+	if _, err := raw.LookupErr(deletionRequestedField); err == nil {
+		account.IsActive = false
+	}
+	if changed, ok := raw.Lookup(fields.passwordChanged).DateTimeOK(); ok {
+		t := time.UnixMilli(changed)
+		account.PasswordChangedAt = &t
+	}
+	return account, nil
+}
+
+type mongoUsers struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoUsers) findOne(ctx context.Context, filter bson.M) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *mongoUsers) Insert(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrUserExists
+	}
+	return err
+}
+
+func (r *mongoUsers) Update(ctx context.Context, id primitive.ObjectID, set bson.M, unset ...string) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, updateDocument(set, unset))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) FindByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}},
+	})
+}
+
+func (r *mongoUsers) FindByEmailFold(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(ctx, bson.M{"email": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(email) + "$", Options: "i"}})
+}
+
+func (r *mongoUsers) AddIdentity(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity, set bson.M, unset ...string) error {
+	update := updateDocument(set, unset)
+	update["$push"] = bson.M{"identities": identity}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"deletion_scheduled_at": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *mongoUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+type mongoAdmins struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAdmins) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admin, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoAdmins) FindByEmail(ctx context.Context, email string) (*models.Admin, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoAdmins) Insert(ctx context.Context, admin *models.Admin) error {
+	if admin.ID.IsZero() {
+		admin.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, admin)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrAdminExists
+	}
+	return err
+}
+
+func (r *mongoAdmins) List(ctx context.Context) ([]models.Admin, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	admins := []models.Admin{}
+	for cursor.Next(ctx) {
+		admin, err := decodeAdmin(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, *admin)
+	}
+	return admins, cursor.Err()
+}
+
+func (r *mongoAdmins) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrAdminNotFound
+	}
+	return nil
+}
+
+func (r *mongoAdmins) CountActiveSuperAdmins(ctx context.Context, exclude primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"role":     bson.M{"$in": superAdminRoles},
+		"_id":      bson.M{"$ne": exclude},
+		"isActive": bson.M{"$ne": false},
+	})
+}
+
+func (r *mongoAdmins) findOne(ctx context.Context, filter bson.M) (*models.Admin, error) {
+	raw, err := r.collection.FindOne(ctx, filter).Raw()
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrAdminNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeAdmin(raw)
+}
+
+// decodeAdmin - An admin document, active unless isActive says otherwise
+func decodeAdmin(raw bson.Raw) (*models.Admin, error) {
+	var admin models.Admin
+	if err := bson.Unmarshal(raw, &admin); err != nil {
+		return nil, err
+	}
+	if _, err := raw.LookupErr("isActive"); err != nil {
+		admin.IsActive = true
+	}
+	return &admin, nil
+}
+
+type mongoTutors struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoTutors) List(ctx context.Context) ([]models.TutorAccount, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	tutors := []models.TutorAccount{}
+	if err := cursor.All(ctx, &tutors); err != nil {
+		return nil, err
+	}
+	return tutors, nil
+}
+
+func (r *mongoTutors) FindByID(ctx context.Context, id primitive.ObjectID) (*models.TutorAccount, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoTutors) FindByEmail(ctx context.Context, email string) (*models.TutorAccount, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoTutors) findOne(ctx context.Context, filter bson.M) (*models.TutorAccount, error) {
+	var tutor models.TutorAccount
+	err := r.collection.FindOne(ctx, filter).Decode(&tutor)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrTutorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tutor, nil
+}
+
+func (r *mongoTutors) Insert(ctx context.Context, tutor *models.TutorAccount) error {
+	if tutor.ID.IsZero() {
+		tutor.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, tutor)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrTutorExists
+	}
+	return err
+}
+
+func (r *mongoTutors) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrTutorNotFound
+	}
+	return nil
+}
+
+type mongoAccounts struct {
+	collection *mongo.Collection
+	fields     accountFields
+}
+
+func (r *mongoAccounts) FindByID(ctx context.Context, id primitive.ObjectID) (*Account, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoAccounts) FindByEmail(ctx context.Context, email string) (*Account, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *mongoAccounts) findOne(ctx context.Context, filter bson.M) (*Account, error) {
+	raw, err := r.collection.FindOne(ctx, filter).Raw()
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeAccount(raw, r.fields)
+}
+
+func (r *mongoAccounts) SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string, now time.Time) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{
+		"password":               hashedPassword,
+		r.fields.passwordChanged: now,
+		r.fields.updated:         now,
+	}})
+}
+
+func (r *mongoAccounts) BeginMFA(ctx context.Context, id primitive.ObjectID, secret string, recoveryCodeHashes []string) error {
+	return r.update(ctx, id, bson.M{"$set": bson.M{
+		"mfa.pendingSecret":             secret,
+		"mfa.pendingRecoveryCodeHashes": recoveryCodeHashes,
+	}})
+}
+
+func (r *mongoAccounts) SetMFA(ctx context.Context, id primitive.ObjectID, settings *models.MFASettings, now time.Time) error {
+	if settings == nil {
+		return r.update(ctx, id, bson.M{
+			"$unset": bson.M{"mfa": ""},
+			"$set":   bson.M{r.fields.updated: now},
+		})
+	}
+	return r.update(ctx, id, bson.M{"$set": bson.M{"mfa": settings, r.fields.updated: now}})
+}
+
+func (r *mongoAccounts) update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrAccountNotFound
+	}
+	return nil
+}
+
+func (r *mongoAccounts) UseMFAStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"mfa.lastUsedStep": bson.M{"$lt": step}},
+			{"mfa.lastUsedStep": bson.M{"$exists": false}},
+		},
+	}, bson.M{"$set": bson.M{"mfa.lastUsedStep": step}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *mongoAccounts) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id":                    id,
+		"mfa.recoveryCodeHashes": hash,
+	}, bson.M{"$pull": bson.M{"mfa.recoveryCodeHashes": hash}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// APIKeyRepository - Admin API keys, stored by hash and found by their prefix
+type APIKeyRepository interface {
+	// Insert assigns an id if the key has none
+	Insert(ctx context.Context, key *models.APIKey) error
+
+	// List returns the keys of ownerID ("" = every key), newest first
+	List(ctx context.Context, ownerID string) ([]models.APIKey, error)
+
+	// FindByPrefix returns services.ErrAPIKeyNotFound when no key has the prefix
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+
+	// Revoke revokes the key of ownerID ("" = any owner). Returns
+	// services.ErrAPIKeyNotFound when there is no such key or it was already revoked.
+	Revoke(ctx context.Context, id primitive.ObjectID, ownerID string, now time.Time) error
+
+	// MarkUsed records when and from where the key was last used
+	MarkUsed(ctx context.Context, id primitive.ObjectID, ip string, now time.Time) error
+}
+
+type mongoAPIKeys struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAPIKeys) Insert(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *mongoAPIKeys) List(ctx context.Context, ownerID string) ([]models.APIKey, error) {
+	filter := bson.M{}
+	if ownerID != "" {
+		filter["ownerId"] = ownerID
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *mongoAPIKeys) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *mongoAPIKeys) Revoke(ctx context.Context, id primitive.ObjectID, ownerID string, now time.Time) error {
+	filter := bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}}
+	if ownerID != "" {
+		filter["ownerId"] = ownerID
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revokedAt": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *mongoAPIKeys) MarkUsed(ctx context.Context, id primitive.ObjectID, ip string, now time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"lastUsedAt": now,
+		"lastUsedIp": ip,
+	}})
+	return err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditLogRepository - The audit trail. Entries are only inserted, apart
+// from the response status of an impersonated request.
+type AuditLogRepository interface {
+	// Insert assigns an id if the entry has none
+	Insert(ctx context.Context, entry *models.AuditLog) error
+
+	SetStatus(ctx context.Context, id primitive.ObjectID, status int) error
+
+	// List returns the entries matching match, newest first, at most limit
+	List(ctx context.Context, match Fields, limit int64) ([]models.AuditLog, error)
+}
+
+type mongoAuditLogs struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoAuditLogs) Insert(ctx context.Context, entry *models.AuditLog) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *mongoAuditLogs) SetStatus(ctx context.Context, id primitive.ObjectID, status int) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+func (r *mongoAuditLogs) List(ctx context.Context, match Fields, limit int64) ([]models.AuditLog, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, match.filter(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.AuditLog{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CourseCommentRepository - Reviewer and tutor notes on courses, including
+// the recorded status changes
+type CourseCommentRepository interface {
+	// Insert assigns an id if the comment has none
+	Insert(ctx context.Context, comment *models.CourseComment) error
+
+	// ListByCourse returns the comments of a course, newest first
+	ListByCourse(ctx context.Context, courseID string) ([]models.CourseComment, error)
+}
+
+type mongoCourseComments struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoCourseComments) Insert(ctx context.Context, comment *models.CourseComment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, comment)
+	return err
+}
+
+func (r *mongoCourseComments) ListByCourse(ctx context.Context, courseID string) ([]models.CourseComment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"courseId": courseID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []models.CourseComment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PriceFacet - The facet name price bands are counted under
+const PriceFacet = "price"
+
+// CourseFacets - What to count alongside a catalog query. Each facet counts
+// the courses matching every filter except its own, so the counts show what
+// choosing another value would give.
+type CourseFacets struct {
+	Fields     []string    // Count courses per value of each of these top-level fields
+	PriceBands []PriceBand // Count courses per band, under PriceFacet
+}
+
+// PriceBand - Courses priced above Above and up to and including UpTo; a nil
+// bound is open
+type PriceBand struct {
+	Key   string
+	Above *float64
+	UpTo  *float64
+}
+
+// FacetCount - How many courses have a value
+type FacetCount struct {
+	Value string `json:"value" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// without - The query minus its filter on a facet
+func (q CourseQuery) without(facet string) CourseQuery {
+	if facet == PriceFacet {
+		q.MinPrice, q.MaxPrice = nil, nil
+		return q
+	}
+
+	match := Fields{}
+	for key, value := range q.Match {
+		if key != facet {
+			match[key] = value
+		}
+	}
+	q.Match = match
+	return q
+}
+
+// facetNames - Every facet asked for, price last
+func (f CourseFacets) facetNames() []string {
+	names := append([]string{}, f.Fields...)
+	if len(f.PriceBands) > 0 {
+		names = append(names, PriceFacet)
+	}
+	return names
+}
+
+// contains - Whether price falls in the band
+func (b PriceBand) contains(price float64) bool {
+	return (b.Above == nil || price > *b.Above) && (b.UpTo == nil || price <= *b.UpTo)
+}
+
+// expression - Aggregation expression that is true for courses in the band
+func (b PriceBand) expression() bson.M {
+	conditions := []interface{}{bson.M{"$isNumber": "$price"}}
+	if b.Above != nil {
+		conditions = append(conditions, bson.M{"$gt": bson.A{"$price", *b.Above}})
+	}
+	if b.UpTo != nil {
+		conditions = append(conditions, bson.M{"$lte": bson.A{"$price", *b.UpTo}})
+	}
+	return bson.M{"$and": conditions}
+}
+
+// bandCounts - Counts for every band in order, including empty ones
+func bandCounts(bands []PriceBand, counted map[string]int64) []FacetCount {
+	counts := make([]FacetCount, len(bands))
+	for i, band := range bands {
+		counts[i] = FacetCount{Value: band.Key, Count: counted[band.Key]}
+	}
+	return counts
+}
+
+// sortFacetCounts - Most common value first, then alphabetically
+func sortFacetCounts(counts []FacetCount) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
+
+func (r *mongoCourses) QueryFacets(ctx context.Context, query CourseQuery, facets CourseFacets, out interface{}) (int64, map[string][]FacetCount, error) {
+	if err := query.check(); err != nil {
+		return 0, nil, err
+	}
+
+	// The filters every facet shares narrow the collection first, using indexes
+	shared := query
+	for _, name := range facets.facetNames() {
+		shared = shared.without(name)
+	}
+
+	page := bson.A{bson.M{"$match": query.filter()}}
+	if query.After != nil {
+		page = append(page, bson.M{"$match": query.After.afterFilter()})
+	}
+	page = append(page, bson.M{"$sort": query.sortDocument()})
+	if query.Skip > 0 {
+		page = append(page, bson.M{"$skip": query.Skip})
+	}
+	if query.Limit > 0 {
+		page = append(page, bson.M{"$limit": query.Limit})
+	}
+
+	stages := bson.M{
+		"courses": page,
+		"total":   bson.A{bson.M{"$match": query.filter()}, bson.M{"$count": "count"}},
+	}
+	for i, field := range facets.Fields {
+		stages["facet"+strconv.Itoa(i)] = bson.A{
+			bson.M{"$match": query.without(field).filter()},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			// Values that aren't strings (missing, null, arrays) aren't choices
+			bson.M{"$match": bson.M{"_id": bson.M{"$type": "string", "$ne": ""}}},
+		}
+	}
+	if len(facets.PriceBands) > 0 {
+		branches := bson.A{}
+		for _, band := range facets.PriceBands {
+			branches = append(branches, bson.M{"case": band.expression(), "then": band.Key})
+		}
+		stages[PriceFacet] = bson.A{
+			bson.M{"$match": query.without(PriceFacet).filter()},
+			bson.M{"$group": bson.M{
+				"_id":   bson.M{"$switch": bson.M{"branches": branches, "default": nil}},
+				"count": bson.M{"$sum": 1},
+			}},
+		}
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: shared.filter()}},
+		{{Key: "$facet", Value: stages}},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Courses []bson.Raw `bson:"courses"`
+		Total   []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	var raw bson.Raw
+	if cursor.Next(ctx) {
+		raw = cursor.Current
+		if err := bson.Unmarshal(raw, &result); err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	counts := map[string][]FacetCount{}
+	for i, field := range facets.Fields {
+		var values []FacetCount
+		if raw != nil {
+			if err := raw.Lookup("facet" + strconv.Itoa(i)).Unmarshal(&values); err != nil {
+				return 0, nil, err
+			}
+		}
+		sortFacetCounts(values)
+		counts[field] = append([]FacetCount{}, values...)
+	}
+	if len(facets.PriceBands) > 0 {
+		var bands []struct {
+			Key   *string `bson:"_id"`
+			Count int64   `bson:"count"`
+		}
+		if raw != nil {
+			if err := raw.Lookup(PriceFacet).Unmarshal(&bands); err != nil {
+				return 0, nil, err
+			}
+		}
+		counted := map[string]int64{}
+		for _, band := range bands {
+			if band.Key != nil {
+				counted[*band.Key] = band.Count
+			}
+		}
+		counts[PriceFacet] = bandCounts(facets.PriceBands, counted)
+	}
+
+	var total int64
+	if len(result.Total) > 0 {
+		total = result.Total[0].Count
+	}
+	return total, counts, decodeAll(result.Courses, out)
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"encoding/base64"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CourseQuery - A filtered, sorted slice of the course catalog
+type CourseQuery struct {
+	Match     Fields   // Top-level fields the course must be equal to
+	Tags      []string // The course must have every one of these tags
+	MinPrice  *float64
+	MaxPrice  *float64
+	MinRating *float64
+
+	Sort  Sort          // Courses with the same value are ordered by id
+	After *CourseCursor // Only courses after this position (keyset pagination)
+	Skip  int64
+	Limit int64 // 0 = no limit
+}
+
+// CourseCursor - The position of a course in a listing sorted by Field, so the
+// next page starts right after it even if courses were added meanwhile
+type CourseCursor struct {
+	Field      string      `bson:"f"`
+	Descending bool        `bson:"d"`
+	Value      interface{} `bson:"v"`
+	ID         string      `bson:"id"`
+}
+
+// CursorAfter - The cursor pointing just past course in a listing sorted by sort
+func CursorAfter(course bson.M, sort Sort) *CourseCursor {
+	id, _ := course["id"].(string)
+	return &CourseCursor{
+		Field:      sort.Field,
+		Descending: sort.Descending,
+		Value:      course[sort.Field],
+		ID:         id,
+	}
+}
+
+// Encode - The cursor as an opaque, URL-safe token
+func (c *CourseCursor) Encode() string {
+	raw, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCourseCursor - Parses a token made by Encode. Returns
+// services.ErrInvalidCursor for anything else.
+func DecodeCourseCursor(token string) (*CourseCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, services.ErrInvalidCursor
+	}
+
+	var cursor CourseCursor
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.Field == "" || cursor.ID == "" {
+		return nil, services.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// check - A cursor only makes sense for the order it was made in
+func (q CourseQuery) check() error {
+	if q.After != nil && (q.After.Field != q.Sort.Field || q.After.Descending != q.Sort.Descending) {
+		return services.ErrInvalidCursor
+	}
+	return nil
+}
+
+// filter - Everything but the cursor as a Mongo filter
+func (q CourseQuery) filter() bson.M {
+	filter := q.Match.filter()
+	if len(q.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": q.Tags}
+	}
+
+	price := bson.M{}
+	if q.MinPrice != nil {
+		price["$gte"] = *q.MinPrice
+	}
+	if q.MaxPrice != nil {
+		price["$lte"] = *q.MaxPrice
+	}
+	if len(price) > 0 {
+		filter["price"] = price
+	}
+
+	if q.MinRating != nil {
+		filter["rating"] = bson.M{"$gte": *q.MinRating}
+	}
+	return filter
+}
+
+// afterFilter - Courses past the cursor. Missing values sort before
+// everything else, like MongoDB orders them.
+func (c *CourseCursor) afterFilter() bson.M {
+	next := "$gt"
+	if c.Descending {
+		next = "$lt"
+	}
+
+	sameValue := bson.M{c.Field: c.Value, "id": bson.M{next: c.ID}}
+	if c.Value == nil {
+		if c.Descending {
+			return sameValue
+		}
+		return bson.M{"$or": []bson.M{sameValue, {c.Field: bson.M{"$ne": nil}}}}
+	}
+
+	alternatives := []bson.M{{c.Field: bson.M{next: c.Value}}, sameValue}
+	if c.Descending {
+		alternatives = append(alternatives, bson.M{c.Field: nil})
+	}
+	return bson.M{"$or": alternatives}
+}
+
+// sortDocument - The sort with id as a tie-breaker
+func (q CourseQuery) sortDocument() bson.D {
+	order := 1
+	if q.Sort.Descending {
+		order = -1
+	}
+	if q.Sort.Field == "" || q.Sort.Field == "id" {
+		return bson.D{{Key: "id", Value: order}}
+	}
+	return bson.D{{Key: q.Sort.Field, Value: order}, {Key: "id", Value: order}}
+}
+
+// accepts - The filter (without the cursor) evaluated in Go, for the in-memory store
+func (q CourseQuery) accepts(doc bson.Raw) bool {
+	if !matches(doc, q.Match) {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !hasTag(doc.Lookup("tags"), tag) {
+			return false
+		}
+	}
+
+	if q.MinPrice != nil || q.MaxPrice != nil {
+		price, ok := numberValue(doc.Lookup("price"))
+		if !ok || (q.MinPrice != nil && price < *q.MinPrice) || (q.MaxPrice != nil && price > *q.MaxPrice) {
+			return false
+		}
+	}
+	if q.MinRating != nil {
+		rating, ok := numberValue(doc.Lookup("rating"))
+		if !ok || rating < *q.MinRating {
+			return false
+		}
+	}
+	return true
+}
+
+// position - The cursor as the two values courses are ordered by
+func (c *CourseCursor) position() (value bson.RawValue, id string) {
+	if c.Value != nil {
+		if typ, data, err := bson.MarshalValue(c.Value); err == nil {
+			value = bson.RawValue{Type: typ, Value: data}
+		}
+	}
+	return value, c.ID
+}
+
+// compareCourses - Where doc sorts relative to a position, in listing order
+func compareCourses(doc bson.Raw, value bson.RawValue, id string, order Sort) int {
+	cmp := 0
+	if order.Field != "" && order.Field != "id" {
+		cmp = compareValues(doc.Lookup(order.Field), value)
+	}
+	if cmp == 0 {
+		docID, _ := doc.Lookup("id").StringValueOK()
+		cmp = compareOrdered(docID, id)
+	}
+	if order.Descending {
+		return -cmp
+	}
+	return cmp
+}
+
+func hasTag(tags bson.RawValue, tag string) bool {
+	values, ok := tags.ArrayOK()
+	if !ok {
+		return false
+	}
+	elements, err := values.Values()
+	if err != nil {
+		return false
+	}
+	for _, value := range elements {
+		if s, ok := value.StringValueOK(); ok && s == tag {
+			return true
+		}
+	}
+	return false
+}
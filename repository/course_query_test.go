@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCourseCursorRoundTrip(t *testing.T) {
+	published := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor CourseCursor
+	}{
+		{"double", CourseCursor{Field: "price", Value: 49.99, ID: "c1"}},
+		{"int32", CourseCursor{Field: "enrollmentCount", Value: int32(12), ID: "c2"}},
+		{"int64", CourseCursor{Field: "enrollmentCount", Value: int64(1) << 40, ID: "c3"}},
+		{"string", CourseCursor{Field: "title", Value: "Intro to Go", ID: "c4"}},
+		{"date", CourseCursor{Field: "publishedAt", Value: published, ID: "c5"}},
+		{"missing value", CourseCursor{Field: "rating", Value: nil, ID: "c6"}},
+		{"descending", CourseCursor{Field: "rating", Descending: true, Value: 4.5, ID: "c7"}},
+		{"id only", CourseCursor{Field: "id", Value: "c8", ID: "c8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.cursor.Encode()
+			if token == "" {
+				t.Fatal("Encode returned no token")
+			}
+
+			decoded, err := DecodeCourseCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeCourseCursor(%q): %v", token, err)
+			}
+			if decoded.Field != tt.cursor.Field || decoded.Descending != tt.cursor.Descending || decoded.ID != tt.cursor.ID {
+				t.Errorf("decoded %+v, want %+v", *decoded, tt.cursor)
+			}
+
+			// The value must keep its BSON type, or the next page starts elsewhere
+			wantValue, _ := tt.cursor.position()
+			gotValue, _ := decoded.position()
+			if gotValue.Type != wantValue.Type || compareValues(gotValue, wantValue) != 0 {
+				t.Errorf("decoded value %v (%v), want %v (%v)", gotValue, gotValue.Type, wantValue, wantValue.Type)
+			}
+		})
+	}
+}
+
+func TestDecodeCourseCursorInvalid(t *testing.T) {
+	encode := func(v interface{}) string {
+		raw, err := bson.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"not base64", "not a cursor!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("ab"))},
+		{"not bson", base64.RawURLEncoding.EncodeToString([]byte("hello world"))},
+		{"no field", encode(bson.M{"v": 1, "id": "c1"})},
+		{"no id", encode(bson.M{"f": "price", "v": 1})},
+		{"wrong types", encode(bson.M{"f": 1, "d": "yes", "id": 2})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCourseCursor(tt.token)
+			if !errors.Is(err, services.ErrInvalidCursor) {
+				t.Errorf("DecodeCourseCursor(%q) = %+v, %v, want ErrInvalidCursor", tt.token, cursor, err)
+			}
+		})
+	}
+}
+
+func TestCoursePagination(t *testing.T) {
+	courses := NewMemoryStore().Courses
+	ctx := context.Background()
+
+	// Ties and missing values are where keyset pagination goes wrong
+	prices := []interface{}{10.0, nil, 25.5, 10.0, int32(5), nil, 10, 99.0}
+	for i, price := range prices {
+		course := bson.M{"id": fmt.Sprintf("c%d", i), "title": fmt.Sprintf("Course %d", len(prices)-i)}
+		if price != nil {
+			course["price"] = price
+		}
+		if err := courses.Insert(ctx, course); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		sort Sort
+		want []string
+	}{
+		{Sort{Field: "price"}, []string{"c1", "c5", "c4", "c0", "c3", "c6", "c2", "c7"}},
+		{Sort{Field: "price", Descending: true}, []string{"c7", "c2", "c6", "c3", "c0", "c4", "c5", "c1"}},
+		{Sort{Field: "title"}, []string{"c7", "c6", "c5", "c4", "c3", "c2", "c1", "c0"}},
+		{Sort{Field: "id", Descending: true}, []string{"c7", "c6", "c5", "c4", "c3", "c2", "c1", "c0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%+v", tt.sort), func(t *testing.T) {
+			var got []string
+			var after *CourseCursor
+			for page := 0; page < len(prices); page++ {
+				var found []bson.M
+				total, err := courses.Query(ctx, CourseQuery{Sort: tt.sort, After: after, Limit: 3}, &found)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != int64(len(prices)) {
+					t.Fatalf("total = %d, want %d", total, len(prices))
+				}
+				if len(found) == 0 {
+					break
+				}
+				for _, course := range found {
+					got = append(got, course["id"].(string))
+				}
+
+				// Through a token, as clients page
+				after, err = DecodeCourseCursor(CursorAfter(found[len(found)-1], tt.sort).Encode())
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCourseCursorOtherSort(t *testing.T) {
+	courses := NewMemoryStore().Courses
+	cursor := &CourseCursor{Field: "price", ID: "c1", Value: 10.0}
+
+	tests := []Sort{
+		{Field: "title"},
+		{Field: "price", Descending: true},
+	}
+	for _, sort := range tests {
+		var found []bson.M
+		_, err := courses.Query(context.Background(), CourseQuery{Sort: sort, After: cursor}, &found)
+		if !errors.Is(err, services.ErrInvalidCursor) {
+			t.Errorf("cursor for price ascending with sort %+v: %v, want ErrInvalidCursor", sort, err)
+		}
+	}
+}
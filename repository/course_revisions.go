@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CourseRevisionRepository - The change history of courses, one numbered
+// revision per change. Revisions are only ever inserted.
+type CourseRevisionRepository interface {
+	// Latest returns services.ErrRevisionNotFound when the course has no revisions
+	Latest(ctx context.Context, courseID string) (*models.CourseRevision, error)
+
+	// Get returns services.ErrRevisionNotFound when there is no such revision
+	Get(ctx context.Context, courseID string, number int) (*models.CourseRevision, error)
+
+	// List returns the revisions numbered below before (0 = from the latest),
+	// newest first; limit 0 means all
+	List(ctx context.Context, courseID string, before int, limit int64) ([]models.CourseRevision, error)
+
+	// Insert assigns an id if the revision has none. Returns
+	// services.ErrRevisionExists when the number is already taken.
+	Insert(ctx context.Context, revision *models.CourseRevision) error
+}
+
+type mongoCourseRevisions struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoCourseRevisions) Latest(ctx context.Context, courseID string) (*models.CourseRevision, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}})
+	return r.findOne(ctx, bson.M{"courseId": courseID}, opts)
+}
+
+func (r *mongoCourseRevisions) Get(ctx context.Context, courseID string, number int) (*models.CourseRevision, error) {
+	return r.findOne(ctx, bson.M{"courseId": courseID, "number": number})
+}
+
+func (r *mongoCourseRevisions) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*models.CourseRevision, error) {
+	var revision models.CourseRevision
+	if err := r.collection.FindOne(ctx, filter, opts...).Decode(&revision); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, services.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *mongoCourseRevisions) List(ctx context.Context, courseID string, before int, limit int64) ([]models.CourseRevision, error) {
+	filter := bson.M{"courseId": courseID}
+	if before > 0 {
+		filter["number"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []models.CourseRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *mongoCourseRevisions) Insert(ctx context.Context, revision *models.CourseRevision) error {
+	if revision.ID.IsZero() {
+		revision.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, revision)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrRevisionExists
+	}
+	return err
+}
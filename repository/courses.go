@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CourseRepository - Courses by their public id. Courses are kept as
+// documents (bson.M) because they carry fields models.Course doesn't know;
+// reads decode into whatever out points at (*bson.M, *models.Course, ...).
+type CourseRepository interface {
+	// Find decodes the course with this id that also matches match into out.
+	// Returns services.ErrCourseNotFound when there is none.
+	Find(ctx context.Context, courseID string, match Fields, out interface{}) error
+
+	// List decodes every course matching match, in order, into out (a pointer to a slice)
+	List(ctx context.Context, match Fields, sort Sort, out interface{}) error
+
+	// Query decodes one page of the courses matching query into out and
+	// returns how many courses match in total, regardless of the page.
+	// Returns services.ErrInvalidCursor if query.After is for another order.
+	Query(ctx context.Context, query CourseQuery, out interface{}) (int64, error)
+
+	// QueryFacets is Query that also counts the matching courses per facet
+	// value, keyed by field name (and PriceFacet for price bands)
+	QueryFacets(ctx context.Context, query CourseQuery, facets CourseFacets, out interface{}) (int64, map[string][]FacetCount, error)
+
+	Insert(ctx context.Context, course bson.M) error
+
+	// Update sets and unsets top-level fields of the course if it also matches
+	// match. Returns services.ErrCourseNotFound when nothing matched.
+	Update(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) error
+
+	// Change is Update that also bumps the revision counter
+	// (models.CourseRevisionField) and returns the course as it was right
+	// before and after, read by the same write
+	Change(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) (*models.CourseChange, error)
+
+	// Increment adds delta to a numeric field (a missing field counts as 0)
+	Increment(ctx context.Context, courseID, field string, delta int) error
+
+	// Decrement takes 1 off a counter that is above 0, and does nothing
+	// otherwise (also when there is no such course)
+	Decrement(ctx context.Context, courseID, field string) error
+
+	// ListDue decodes the courses in status whose time field is at or before
+	// now, earliest first, into out
+	ListDue(ctx context.Context, status, field string, now time.Time, out interface{}) error
+
+	// BackfillIDs gives courses created before public ids existed their _id
+	// in hex as id, which is what clients already use for them. Safe to run
+	// repeatedly; returns how many courses were updated.
+	BackfillIDs(ctx context.Context) (int64, error)
+}
+
+type mongoCourses struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoCourses) Find(ctx context.Context, courseID string, match Fields, out interface{}) error {
+	if courseID == "" {
+		return services.ErrCourseNotFound
+	}
+
+	filter := match.filter()
+	filter["id"] = courseID
+
+	err := r.collection.FindOne(ctx, filter).Decode(out)
+	if err == mongo.ErrNoDocuments {
+		return services.ErrCourseNotFound
+	}
+	return err
+}
+
+func (r *mongoCourses) List(ctx context.Context, match Fields, sort Sort, out interface{}) error {
+	cursor, err := r.collection.Find(ctx, match.filter(), options.Find().SetSort(sort.document()))
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, out)
+}
+
+func (r *mongoCourses) Query(ctx context.Context, query CourseQuery, out interface{}) (int64, error) {
+	if err := query.check(); err != nil {
+		return 0, err
+	}
+
+	filter := query.filter()
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if query.After != nil {
+		filter = bson.M{"$and": []bson.M{filter, query.After.afterFilter()}}
+	}
+	opts := options.Find().SetSort(query.sortDocument()).SetSkip(query.Skip)
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	return total, cursor.All(ctx, out)
+}
+
+func (r *mongoCourses) Insert(ctx context.Context, course bson.M) error {
+	_, err := r.collection.InsertOne(ctx, course)
+	return err
+}
+
+func (r *mongoCourses) Update(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) error {
+	filter := match.filter()
+	filter["id"] = courseID
+
+	result, err := r.collection.UpdateOne(ctx, filter, updateDocument(set, unset))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrCourseNotFound
+	}
+	return nil
+}
+
+func (r *mongoCourses) Change(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) (*models.CourseChange, error) {
+	filter := match.filter()
+	filter["id"] = courseID
+
+	update := bson.M{"$set": set, "$inc": bson.M{models.CourseRevisionField: 1}}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		update["$unset"] = fields
+	}
+
+	var before bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrCourseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return courseChange(before, set, unset)
+}
+
+// courseChange - The change made by setting and unsetting fields of before
+// and bumping its revision counter
+func courseChange(before, set bson.M, unset []string) (*models.CourseChange, error) {
+	after := bson.M{}
+	for field, value := range before {
+		after[field] = value
+	}
+	for field, value := range set {
+		after[field] = value
+	}
+	for _, field := range unset {
+		delete(after, field)
+	}
+	revision := revisionNumber(before) + 1
+	after[models.CourseRevisionField] = int32(revision)
+
+	// Round trip the new values so they compare like values read back
+	raw, err := bson.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	after = bson.M{}
+	if err := bson.Unmarshal(raw, &after); err != nil {
+		return nil, err
+	}
+	return &models.CourseChange{Revision: revision, Before: before, After: after}, nil
+}
+
+// revisionNumber - The revision counter of a stored course (0 if it has none)
+func revisionNumber(course bson.M) int {
+	switch n := course[models.CourseRevisionField].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+func (r *mongoCourses) Increment(ctx context.Context, courseID, field string, delta int) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"id": courseID}, bson.M{"$inc": bson.M{field: delta}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrCourseNotFound
+	}
+	return nil
+}
+
+func (r *mongoCourses) Decrement(ctx context.Context, courseID, field string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"id": courseID, field: bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{field: -1}})
+	return err
+}
+
+func (r *mongoCourses) ListDue(ctx context.Context, status, field string, now time.Time, out interface{}) error {
+	filter := bson.M{"status": status, field: bson.M{"$lte": now}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: field, Value: 1}}))
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, out)
+}
+
+// updateDocument - {$set: set, $unset: unset}, leaving out empty parts
+func updateDocument(set bson.M, unset []string) bson.M {
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		update["$unset"] = fields
+	}
+	return update
+}
+
+func (r *mongoCourses) BackfillIDs(ctx context.Context) (int64, error) {
+	missing := bson.M{"$or": []bson.M{
+		{"id": bson.M{"$exists": false}},
+		{"id": nil},
+		{"id": ""},
+	}}
+	backfill := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"id": bson.M{"$toString": "$_id"}}}},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, missing, backfill)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnrollmentRepository - A student's enrollment in a course, one per pair
+type EnrollmentRepository interface {
+	// Find returns services.ErrNotEnrolled when the student isn't enrolled
+	Find(ctx context.Context, userID, courseID string) (*models.Enrollment, error)
+
+	// ListByUser returns the student's enrollments, most recently accessed first
+	ListByUser(ctx context.Context, userID string) ([]models.Enrollment, error)
+
+	// Insert returns services.ErrAlreadyEnrolled for a second enrollment
+	Insert(ctx context.Context, enrollment *models.Enrollment) error
+
+	// Update sets top-level fields. Returns services.ErrNotEnrolled when
+	// there is no such enrollment.
+	Update(ctx context.Context, userID, courseID string, set bson.M) error
+
+	// Delete returns services.ErrNotEnrolled when there is no such enrollment
+	Delete(ctx context.Context, userID, courseID string) error
+
+	// CountCompleted - How many students completed the course
+	CountCompleted(ctx context.Context, courseID string) (int64, error)
+}
+
+type mongoEnrollments struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoEnrollments) Find(ctx context.Context, userID, courseID string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := r.collection.FindOne(ctx, bson.M{"userId": userID, "courseId": courseID}).Decode(&enrollment)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+func (r *mongoEnrollments) ListByUser(ctx context.Context, userID string) ([]models.Enrollment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "lastAccessedAt", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments := []models.Enrollment{}
+	if err := cursor.All(ctx, &enrollments); err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func (r *mongoEnrollments) Insert(ctx context.Context, enrollment *models.Enrollment) error {
+	_, err := r.collection.InsertOne(ctx, enrollment)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrAlreadyEnrolled
+	}
+	return err
+}
+
+func (r *mongoEnrollments) Update(ctx context.Context, userID, courseID string, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"userId": userID, "courseId": courseID}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrNotEnrolled
+	}
+	return nil
+}
+
+func (r *mongoEnrollments) Delete(ctx context.Context, userID, courseID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"userId": userID, "courseId": courseID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return services.ErrNotEnrolled
+	}
+	return nil
+}
+
+func (r *mongoEnrollments) CountCompleted(ctx context.Context, courseID string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"courseId":    courseID,
+		"completedAt": bson.M{"$ne": nil},
+	})
+}
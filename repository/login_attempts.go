@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepository - Failed login counters, one per key (an account or
+// an IP). A counter is forgotten once it expires.
+type LoginAttemptRepository interface {
+	// ListLocked returns the counters among keys that are locked out at now
+	ListLocked(ctx context.Context, keys []string, now time.Time) ([]models.LoginAttempt, error)
+
+	// AddFailure counts one failure on attempt.Key and returns the counter.
+	// An expired counter starts over; Kind, AccountType and Identifier of
+	// attempt are only used for a new one. The counter lives at least until
+	// expiresAt.
+	AddFailure(ctx context.Context, attempt *models.LoginAttempt, now, expiresAt time.Time) (*models.LoginAttempt, error)
+
+	// Lock locks the counter out until lockedUntil and resets its failures,
+	// unless its failures changed since it was read (someone else locked it)
+	Lock(ctx context.Context, attempt *models.LoginAttempt, lockedUntil, expiresAt time.Time) error
+
+	// List returns the counters locked out at now, or with all every counter
+	// that hasn't expired, latest failure first
+	List(ctx context.Context, all bool, now time.Time) ([]models.LoginAttempt, error)
+
+	// DeleteByKey forgets a counter, if there is one
+	DeleteByKey(ctx context.Context, key string) error
+
+	// Delete returns services.ErrLockoutNotFound when there is no such counter
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type mongoLoginAttempts struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoLoginAttempts) ListLocked(ctx context.Context, keys []string, now time.Time) ([]models.LoginAttempt, error) {
+	return r.list(ctx, bson.M{
+		"key":         bson.M{"$in": keys},
+		"lockedUntil": bson.M{"$gt": now},
+	})
+}
+
+func (r *mongoLoginAttempts) AddFailure(ctx context.Context, attempt *models.LoginAttempt, now, expiresAt time.Time) (*models.LoginAttempt, error) {
+	// Forget counters that have gone quiet, so old failures don't add up forever
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"key": attempt.Key, "expiresAt": bson.M{"$lte": now}}); err != nil {
+		return nil, err
+	}
+
+	// $inc is atomic, so concurrent failures on different instances are all counted
+	var counted models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"key": attempt.Key},
+		bson.M{
+			"$inc": bson.M{"failures": 1},
+			"$set": bson.M{"lastFailureAt": now},
+			"$max": bson.M{"expiresAt": expiresAt},
+			"$setOnInsert": bson.M{
+				"kind":        attempt.Kind,
+				"accountType": attempt.AccountType,
+				"identifier":  attempt.Identifier,
+				"lockouts":    0,
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counted)
+	if err != nil {
+		return nil, err
+	}
+	return &counted, nil
+}
+
+func (r *mongoLoginAttempts) Lock(ctx context.Context, attempt *models.LoginAttempt, lockedUntil, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": attempt.ID, "failures": attempt.Failures},
+		bson.M{
+			"$set": bson.M{
+				"failures":    0,
+				"lockedUntil": lockedUntil,
+				"expiresAt":   expiresAt,
+			},
+			"$inc": bson.M{"lockouts": 1},
+		},
+	)
+	return err
+}
+
+func (r *mongoLoginAttempts) List(ctx context.Context, all bool, now time.Time) ([]models.LoginAttempt, error) {
+	filter := bson.M{"lockedUntil": bson.M{"$gt": now}}
+	if all {
+		filter = bson.M{"expiresAt": bson.M{"$gt": now}}
+	}
+	return r.list(ctx, filter, options.Find().SetSort(bson.D{{Key: "lastFailureAt", Value: -1}}))
+}
+
+func (r *mongoLoginAttempts) list(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]models.LoginAttempt, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := []models.LoginAttempt{}
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *mongoLoginAttempts) DeleteByKey(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
+
+func (r *mongoLoginAttempts) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return services.ErrLockoutNotFound
+	}
+	return nil
+}
@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// NewMemoryStore - Repositories that keep everything in memory, for tests.
+// Documents go through the same BSON encoding as with MongoDB, so field
+// names, omitempty and decoding behave the same.
+func NewMemoryStore() *Store {
+	students, admins, tutors := newMemoryCollection("email"), newMemoryCollection("email"), newMemoryCollection("email")
+	return &Store{
+		Courses:     &memoryCourses{docs: newMemoryCollection("id")},
+		Enrollments: &memoryEnrollments{docs: newMemoryCollection("userId", "courseId")},
+		Users:       &memoryUsers{docs: students},
+		Admins:      &memoryAdmins{docs: admins},
+		Tutors:      &memoryTutors{docs: tutors},
+		Accounts: Accounts{
+			Students: &memoryAccounts{docs: students, fields: studentAccountFields},
+			Admins:   &memoryAccounts{docs: admins, fields: staffAccountFields},
+			Tutors:   &memoryAccounts{docs: tutors, fields: staffAccountFields},
+		},
+		Reviews:   &memoryReviews{docs: newMemoryCollection("user_id", "course_id")},
+		Comments:  &memoryCourseComments{docs: newMemoryCollection()},
+		Revisions: &memoryCourseRevisions{docs: newMemoryCollection("courseId", "number")},
+
+		Sessions:       &memorySessions{docs: newMemoryCollection()},
+		RevokedTokens:  &memoryRevokedTokens{docs: newMemoryCollection("jti")},
+		LoginAttempts:  &memoryLoginAttempts{docs: newMemoryCollection("key")},
+		PasswordResets: &memoryPasswordResets{docs: newMemoryCollection("tokenHash")},
+		OIDCStates:     &memoryOIDCStates{docs: newMemoryCollection("stateHash")},
+		APIKeys:        &memoryAPIKeys{docs: newMemoryCollection("prefix")},
+		AuditLogs:      &memoryAuditLogs{docs: newMemoryCollection()},
+	}
+}
+
+// errDuplicate - A write would break the unique key of a memoryCollection
+var errDuplicate = errors.New("duplicate key")
+
+// memoryCollection - Documents in insertion order, with one (possibly
+// compound) unique key like the unique indexes of the real collections.
+// Documents missing a key field aren't held to it, as under a partial index.
+type memoryCollection struct {
+	mu     sync.RWMutex
+	docs   []bson.Raw
+	unique []string
+}
+
+func newMemoryCollection(unique ...string) *memoryCollection {
+	return &memoryCollection{unique: unique}
+}
+
+func (m *memoryCollection) insert(doc interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.duplicate(raw, -1) {
+		return errDuplicate
+	}
+	m.docs = append(m.docs, raw)
+	return nil
+}
+
+// duplicate - Whether another document than m.docs[skip] has the unique key
+// of doc. Callers hold the lock.
+func (m *memoryCollection) duplicate(doc bson.Raw, skip int) bool {
+	if len(m.unique) == 0 {
+		return false
+	}
+
+	key := Fields{}
+	for _, field := range m.unique {
+		value, err := doc.LookupErr(field)
+		if err != nil {
+			return false
+		}
+		key[field] = value
+	}
+	for i, existing := range m.docs {
+		if i != skip && matches(existing, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// findOne - Decodes the first match into out; false if nothing matched
+func (m *memoryCollection) findOne(match Fields, out interface{}) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, doc := range m.docs {
+		if matches(doc, match) {
+			return true, bson.Unmarshal(doc, out)
+		}
+	}
+	return false, nil
+}
+
+// find - Decodes every match, ordered by sort (insertion order without a
+// field) and cut to limit (0 = all), into out, a pointer to a slice
+func (m *memoryCollection) find(match Fields, order Sort, limit int, out interface{}) error {
+	found := m.filter(func(doc bson.Raw) bool { return matches(doc, match) })
+
+	if order.Field != "" {
+		sort.SliceStable(found, func(i, j int) bool {
+			cmp := compareValues(found[i].Lookup(order.Field), found[j].Lookup(order.Field))
+			if order.Descending {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return decodeAll(found, out)
+}
+
+// filter - The documents keep accepts, in insertion order
+func (m *memoryCollection) filter(keep func(bson.Raw) bool) []bson.Raw {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found []bson.Raw
+	for _, doc := range m.docs {
+		if keep(doc) {
+			found = append(found, doc)
+		}
+	}
+	return found
+}
+
+// count - How many documents match and pass keep (nil keeps all)
+func (m *memoryCollection) count(match Fields, keep func(bson.Raw) bool) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n := 0
+	for _, doc := range m.docs {
+		if matches(doc, match) && (keep == nil || keep(doc)) {
+			n++
+		}
+	}
+	return n
+}
+
+// update - Applies change to the first match; false if nothing matched
+func (m *memoryCollection) update(match Fields, change func(doc bson.D) (bson.D, error)) (bool, error) {
+	n, err := m.updateWhere(func(doc bson.Raw) bool { return matches(doc, match) }, 1, change)
+	return n > 0, err
+}
+
+// updateWhere - Applies change to the documents keep accepts, at most limit
+// of them (0 = all); returns how many were changed
+func (m *memoryCollection) updateWhere(keep func(bson.Raw) bool, limit int, change func(doc bson.D) (bson.D, error)) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for i, doc := range m.docs {
+		if !keep(doc) {
+			continue
+		}
+
+		var decoded bson.D
+		if err := bson.Unmarshal(doc, &decoded); err != nil {
+			return n, err
+		}
+		changed, err := change(decoded)
+		if err != nil {
+			return n, err
+		}
+		raw, err := bson.Marshal(changed)
+		if err != nil {
+			return n, err
+		}
+		if m.duplicate(raw, i) {
+			return n, errDuplicate
+		}
+		m.docs[i] = raw
+
+		if n++; n == limit {
+			break
+		}
+	}
+	return n, nil
+}
+
+// set - update that sets and unsets top-level fields
+func (m *memoryCollection) set(match Fields, set bson.M, unset ...string) (bool, error) {
+	return m.update(match, setFields(set, unset...))
+}
+
+// setFields - A change that sets and unsets top-level fields
+func setFields(set bson.M, unset ...string) func(doc bson.D) (bson.D, error) {
+	return func(doc bson.D) (bson.D, error) {
+		for key, value := range set {
+			doc = setField(doc, key, value)
+		}
+		for _, key := range unset {
+			doc = unsetField(doc, key)
+		}
+		return doc, nil
+	}
+}
+
+// remove - Deletes the first match; false if nothing matched
+func (m *memoryCollection) remove(match Fields) bool {
+	return m.removeWhere(func(doc bson.Raw) bool { return matches(doc, match) }, 1) > 0
+}
+
+// removeWhere - Deletes the documents keep accepts, at most limit of them
+// (0 = all); returns how many were deleted
+func (m *memoryCollection) removeWhere(keep func(bson.Raw) bool, limit int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.docs[:0]
+	n := 0
+	for _, doc := range m.docs {
+		if (limit == 0 || n < limit) && keep(doc) {
+			n++
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	m.docs = kept
+	return n
+}
+
+// decodeDocument - doc decoded into out, for changes that are easier to
+// make on a model
+func decodeDocument(doc bson.D, out interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, out)
+}
+
+// encodeDocument - The document of a model, the inverse of decodeDocument
+func encodeDocument(v interface{}) (bson.D, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	return doc, bson.Unmarshal(raw, &doc)
+}
+
+// after - Whether field holds a date later than t
+func after(doc bson.Raw, field string, t time.Time) bool {
+	at, ok := doc.Lookup(field).DateTimeOK()
+	return ok && at > t.UnixMilli()
+}
+
+// atOrBefore - Whether field holds a date no later than t
+func atOrBefore(doc bson.Raw, field string, t time.Time) bool {
+	at, ok := doc.Lookup(field).DateTimeOK()
+	return ok && at <= t.UnixMilli()
+}
+
+func setField(doc bson.D, key string, value interface{}) bson.D {
+	for i := range doc {
+		if doc[i].Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+	return append(doc, bson.E{Key: key, Value: value})
+}
+
+func unsetField(doc bson.D, key string) bson.D {
+	for i := range doc {
+		if doc[i].Key == key {
+			return append(doc[:i], doc[i+1:]...)
+		}
+	}
+	return doc
+}
+
+// matches - Whether doc equals every field of match, comparing values the
+// way MongoDB does for equality: numbers by value whatever their BSON type,
+// nil matching null or a missing field, and a value matching an array that
+// holds it
+func matches(doc bson.Raw, match Fields) bool {
+	for key, expected := range match {
+		actual, err := doc.LookupErr(key)
+		missing := err != nil || actual.Type == bsontype.Null
+
+		if expected == nil {
+			if !missing {
+				return false
+			}
+			continue
+		}
+		if missing {
+			return false
+		}
+
+		value, ok := expected.(bson.RawValue)
+		if !ok {
+			typ, data, err := bson.MarshalValue(expected)
+			if err != nil {
+				return false
+			}
+			value = bson.RawValue{Type: typ, Value: data}
+		}
+		if !valuesEqual(actual, value) && !arrayHolds(actual, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func arrayHolds(array, value bson.RawValue) bool {
+	elements, ok := array.ArrayOK()
+	if !ok || value.Type == bsontype.Array {
+		return false
+	}
+	values, err := elements.Values()
+	if err != nil {
+		return false
+	}
+	for _, element := range values {
+		if valuesEqual(element, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b bson.RawValue) bool {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		return ok && x == y
+	}
+	return a.Type == b.Type && bytes.Equal(a.Value, b.Value)
+}
+
+func numberValue(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bsontype.Int32:
+		return float64(v.Int32()), true
+	case bsontype.Int64:
+		return float64(v.Int64()), true
+	case bsontype.Double:
+		return v.Double(), true
+	}
+	return 0, false
+}
+
+// compareValues - Sort order of two values: missing and null first, then
+// numbers, strings, booleans and dates; other types keep their order
+func compareValues(a, b bson.RawValue) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+
+	switch rankA {
+	case 1:
+		x, _ := numberValue(a)
+		y, _ := numberValue(b)
+		return compareOrdered(x, y)
+	case 2:
+		return compareOrdered(a.StringValue(), b.StringValue())
+	case 3:
+		x, y := 0, 0
+		if a.Boolean() {
+			x = 1
+		}
+		if b.Boolean() {
+			y = 1
+		}
+		return x - y
+	case 4:
+		return compareOrdered(a.DateTime(), b.DateTime())
+	}
+	return 0
+}
+
+func typeRank(v bson.RawValue) int {
+	switch v.Type {
+	case bsontype.Int32, bsontype.Int64, bsontype.Double:
+		return 1
+	case bsontype.String:
+		return 2
+	case bsontype.Boolean:
+		return 3
+	case bsontype.DateTime:
+		return 4
+	case bsontype.Null, 0:
+		return 0
+	}
+	return 5
+}
+
+func compareOrdered[T int64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,1270 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ============================================
+// COURSES
+// ============================================
+
+type memoryCourses struct {
+	docs *memoryCollection
+}
+
+func (r *memoryCourses) Find(ctx context.Context, courseID string, match Fields, out interface{}) error {
+	if courseID == "" {
+		return services.ErrCourseNotFound
+	}
+
+	found, err := r.docs.findOne(withCourseID(match, courseID), out)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrCourseNotFound
+	}
+	return nil
+}
+
+func (r *memoryCourses) List(ctx context.Context, match Fields, sort Sort, out interface{}) error {
+	return r.docs.find(match, sort, 0, out)
+}
+
+func (r *memoryCourses) Query(ctx context.Context, query CourseQuery, out interface{}) (int64, error) {
+	if err := query.check(); err != nil {
+		return 0, err
+	}
+
+	found := r.docs.filter(query.accepts)
+	total := int64(len(found))
+
+	sort.SliceStable(found, func(i, j int) bool {
+		id, _ := found[j].Lookup("id").StringValueOK()
+		return compareCourses(found[i], found[j].Lookup(query.Sort.Field), id, query.Sort) < 0
+	})
+
+	if query.After != nil {
+		value, id := query.After.position()
+		start := sort.Search(len(found), func(i int) bool {
+			return compareCourses(found[i], value, id, query.Sort) > 0
+		})
+		found = found[start:]
+	}
+	found = found[min(query.Skip, int64(len(found))):]
+	if query.Limit > 0 && int64(len(found)) > query.Limit {
+		found = found[:query.Limit]
+	}
+	return total, decodeAll(found, out)
+}
+
+func (r *memoryCourses) QueryFacets(ctx context.Context, query CourseQuery, facets CourseFacets, out interface{}) (int64, map[string][]FacetCount, error) {
+	total, err := r.Query(ctx, query, out)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	counts := map[string][]FacetCount{}
+	for _, field := range facets.Fields {
+		counted := map[string]int64{}
+		for _, doc := range r.docs.filter(query.without(field).accepts) {
+			if value, ok := doc.Lookup(field).StringValueOK(); ok && value != "" {
+				counted[value]++
+			}
+		}
+
+		values := []FacetCount{}
+		for value, count := range counted {
+			values = append(values, FacetCount{Value: value, Count: count})
+		}
+		sortFacetCounts(values)
+		counts[field] = values
+	}
+
+	if len(facets.PriceBands) > 0 {
+		counted := map[string]int64{}
+		for _, doc := range r.docs.filter(query.without(PriceFacet).accepts) {
+			price, ok := numberValue(doc.Lookup("price"))
+			if !ok {
+				continue
+			}
+			for _, band := range facets.PriceBands {
+				if band.contains(price) {
+					counted[band.Key]++
+					break
+				}
+			}
+		}
+		counts[PriceFacet] = bandCounts(facets.PriceBands, counted)
+	}
+	return total, counts, nil
+}
+
+func (r *memoryCourses) Insert(ctx context.Context, course bson.M) error {
+	return r.docs.insert(course)
+}
+
+func (r *memoryCourses) Update(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) error {
+	found, err := r.docs.set(withCourseID(match, courseID), set, unset...)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrCourseNotFound
+	}
+	return nil
+}
+
+func (r *memoryCourses) Change(ctx context.Context, courseID string, match Fields, set bson.M, unset ...string) (*models.CourseChange, error) {
+	var change *models.CourseChange
+	found, err := r.docs.update(withCourseID(match, courseID), func(doc bson.D) (bson.D, error) {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		var before bson.M
+		if err := bson.Unmarshal(raw, &before); err != nil {
+			return nil, err
+		}
+		if change, err = courseChange(before, set, unset); err != nil {
+			return nil, err
+		}
+
+		for key, value := range set {
+			doc = setField(doc, key, value)
+		}
+		for _, key := range unset {
+			doc = unsetField(doc, key)
+		}
+		return setField(doc, models.CourseRevisionField, int32(change.Revision)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrCourseNotFound
+	}
+	return change, nil
+}
+
+func (r *memoryCourses) Increment(ctx context.Context, courseID, field string, delta int) error {
+	found, err := r.docs.update(Fields{"id": courseID}, func(doc bson.D) (bson.D, error) {
+		var total interface{} = int32(delta)
+		for _, e := range doc {
+			if e.Key != field {
+				continue
+			}
+			switch v := e.Value.(type) {
+			case int32:
+				total = v + int32(delta)
+			case int64:
+				total = v + int64(delta)
+			case float64:
+				total = v + float64(delta)
+			}
+		}
+		return setField(doc, field, total), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrCourseNotFound
+	}
+	return nil
+}
+
+func (r *memoryCourses) Decrement(ctx context.Context, courseID, field string) error {
+	positive := func(doc bson.Raw) bool {
+		count, ok := numberValue(doc.Lookup(field))
+		return ok && count > 0 && matches(doc, Fields{"id": courseID})
+	}
+	_, err := r.docs.updateWhere(positive, 1, func(doc bson.D) (bson.D, error) {
+		for _, e := range doc {
+			if e.Key != field {
+				continue
+			}
+			switch v := e.Value.(type) {
+			case int32:
+				return setField(doc, field, v-1), nil
+			case int64:
+				return setField(doc, field, v-1), nil
+			case float64:
+				return setField(doc, field, v-1), nil
+			}
+		}
+		return doc, nil
+	})
+	return err
+}
+
+func (r *memoryCourses) ListDue(ctx context.Context, status, field string, now time.Time, out interface{}) error {
+	due := r.docs.filter(func(doc bson.Raw) bool {
+		at, ok := doc.Lookup(field).DateTimeOK()
+		return ok && matches(doc, Fields{"status": status}) && at <= now.UnixMilli()
+	})
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Lookup(field).DateTime() < due[j].Lookup(field).DateTime()
+	})
+	return decodeAll(due, out)
+}
+
+func (r *memoryCourses) BackfillIDs(ctx context.Context) (int64, error) {
+	var updated int64
+	for _, missing := range []Fields{{"id": nil}, {"id": ""}} {
+		for {
+			found, err := r.docs.update(missing, func(doc bson.D) (bson.D, error) {
+				for _, e := range doc {
+					if id, ok := e.Value.(primitive.ObjectID); ok && e.Key == "_id" {
+						return setField(doc, "id", id.Hex()), nil
+					}
+				}
+				return nil, errors.New("course has no _id to take the id from")
+			})
+			if err != nil {
+				return updated, err
+			}
+			if !found {
+				break
+			}
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+// withCourseID - match narrowed to one course
+func withCourseID(match Fields, courseID string) Fields {
+	filter := Fields{"id": courseID}
+	for key, value := range match {
+		if key != "id" {
+			filter[key] = value
+		}
+	}
+	return filter
+}
+
+// ============================================
+// ENROLLMENTS
+// ============================================
+
+type memoryEnrollments struct {
+	docs *memoryCollection
+}
+
+func (r *memoryEnrollments) Find(ctx context.Context, userID, courseID string) (*models.Enrollment, error) {
+	var enrollment models.Enrollment
+	found, err := r.docs.findOne(Fields{"userId": userID, "courseId": courseID}, &enrollment)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrNotEnrolled
+	}
+	return &enrollment, nil
+}
+
+func (r *memoryEnrollments) ListByUser(ctx context.Context, userID string) ([]models.Enrollment, error) {
+	enrollments := []models.Enrollment{}
+	err := r.docs.find(Fields{"userId": userID}, Sort{Field: "lastAccessedAt", Descending: true}, 0, &enrollments)
+	return enrollments, err
+}
+
+func (r *memoryEnrollments) Insert(ctx context.Context, enrollment *models.Enrollment) error {
+	if err := r.docs.insert(enrollment); err == errDuplicate {
+		return services.ErrAlreadyEnrolled
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *memoryEnrollments) Update(ctx context.Context, userID, courseID string, set bson.M) error {
+	found, err := r.docs.set(Fields{"userId": userID, "courseId": courseID}, set)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrNotEnrolled
+	}
+	return nil
+}
+
+func (r *memoryEnrollments) Delete(ctx context.Context, userID, courseID string) error {
+	if !r.docs.remove(Fields{"userId": userID, "courseId": courseID}) {
+		return services.ErrNotEnrolled
+	}
+	return nil
+}
+
+func (r *memoryEnrollments) CountCompleted(ctx context.Context, courseID string) (int64, error) {
+	completed := func(doc bson.Raw) bool {
+		value, err := doc.LookupErr("completedAt")
+		return err == nil && value.Type != bsontype.Null
+	}
+	return int64(r.docs.count(Fields{"courseId": courseID}, completed)), nil
+}
+
+// ============================================
+// REVIEWS
+// ============================================
+
+type memoryReviews struct {
+	docs *memoryCollection
+}
+
+func (r *memoryReviews) Get(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	return r.findOne(Fields{"_id": id})
+}
+
+func (r *memoryReviews) FindByUserAndCourse(ctx context.Context, userID primitive.ObjectID, courseID string) (*models.Review, error) {
+	return r.findOne(Fields{"user_id": userID, "course_id": courseID})
+}
+
+func (r *memoryReviews) findOne(match Fields) (*models.Review, error) {
+	var review models.Review
+	found, err := r.docs.findOne(match, &review)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrReviewNotFound
+	}
+	return &review, nil
+}
+
+func (r *memoryReviews) ListByCourse(ctx context.Context, courseID string, limit int64) ([]models.Review, error) {
+	reviews := []models.Review{}
+	err := r.docs.find(Fields{"course_id": courseID}, Sort{Field: "created_at", Descending: true}, int(limit), &reviews)
+	return reviews, err
+}
+
+func (r *memoryReviews) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Review, error) {
+	reviews := []models.Review{}
+	err := r.docs.find(Fields{"user_id": userID}, Sort{}, 0, &reviews)
+	return reviews, err
+}
+
+func (r *memoryReviews) Insert(ctx context.Context, review *models.Review) error {
+	if review.ID.IsZero() {
+		review.ID = primitive.NewObjectID()
+	}
+	if err := r.docs.insert(review); err == errDuplicate {
+		return services.ErrReviewExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *memoryReviews) Update(ctx context.Context, id primitive.ObjectID, rating int, comment string, updatedAt time.Time) (*models.Review, error) {
+	found, err := r.docs.set(Fields{"_id": id}, bson.M{
+		"rating":     rating,
+		"comment":    comment,
+		"updated_at": updatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrReviewNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *memoryReviews) UpdateByUser(ctx context.Context, userID primitive.ObjectID, set bson.M, unset ...string) error {
+	byUser := func(doc bson.Raw) bool { return matches(doc, Fields{"user_id": userID}) }
+	_, err := r.docs.updateWhere(byUser, 0, setFields(set, unset...))
+	return err
+}
+
+func (r *memoryReviews) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !r.docs.remove(Fields{"_id": id}) {
+		return services.ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *memoryReviews) Rating(ctx context.Context, courseID string) (float64, int, error) {
+	reviews, err := r.ListByCourse(ctx, courseID, 0)
+	if err != nil || len(reviews) == 0 {
+		return 0, 0, err
+	}
+
+	total := 0
+	for _, review := range reviews {
+		total += review.Rating
+	}
+	return float64(total) / float64(len(reviews)), len(reviews), nil
+}
+
+// ============================================
+// COURSE COMMENTS
+// ============================================
+
+type memoryCourseComments struct {
+	docs *memoryCollection
+}
+
+func (r *memoryCourseComments) Insert(ctx context.Context, comment *models.CourseComment) error {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(comment)
+}
+
+func (r *memoryCourseComments) ListByCourse(ctx context.Context, courseID string) ([]models.CourseComment, error) {
+	// Oldest first then reversed, so comments made in the same millisecond are newest first too
+	comments := []models.CourseComment{}
+	if err := r.docs.find(Fields{"courseId": courseID}, Sort{Field: "createdAt"}, 0, &comments); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	return comments, nil
+}
+
+// ============================================
+// COURSE REVISIONS
+// ============================================
+
+type memoryCourseRevisions struct {
+	docs *memoryCollection
+}
+
+func (r *memoryCourseRevisions) Latest(ctx context.Context, courseID string) (*models.CourseRevision, error) {
+	revisions, err := r.List(ctx, courseID, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, services.ErrRevisionNotFound
+	}
+	return &revisions[0], nil
+}
+
+func (r *memoryCourseRevisions) Get(ctx context.Context, courseID string, number int) (*models.CourseRevision, error) {
+	var revision models.CourseRevision
+	found, err := r.docs.findOne(Fields{"courseId": courseID, "number": number}, &revision)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrRevisionNotFound
+	}
+	return &revision, nil
+}
+
+func (r *memoryCourseRevisions) List(ctx context.Context, courseID string, before int, limit int64) ([]models.CourseRevision, error) {
+	found := r.docs.filter(func(doc bson.Raw) bool {
+		number, ok := numberValue(doc.Lookup("number"))
+		return matches(doc, Fields{"courseId": courseID}) && ok && (before <= 0 || number < float64(before))
+	})
+	sort.SliceStable(found, func(i, j int) bool {
+		return compareValues(found[i].Lookup("number"), found[j].Lookup("number")) > 0
+	})
+	if limit > 0 && int64(len(found)) > limit {
+		found = found[:limit]
+	}
+
+	revisions := []models.CourseRevision{}
+	return revisions, decodeAll(found, &revisions)
+}
+
+func (r *memoryCourseRevisions) Insert(ctx context.Context, revision *models.CourseRevision) error {
+	if revision.ID.IsZero() {
+		revision.ID = primitive.NewObjectID()
+	}
+	if err := r.docs.insert(revision); err == errDuplicate {
+		return services.ErrRevisionExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ============================================
+// ACCOUNTS
+// ============================================
+
+type memoryUsers struct {
+	docs *memoryCollection
+}
+
+func (r *memoryUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return r.findOne(Fields{"_id": id})
+}
+
+func (r *memoryUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.findOne(Fields{"email": email})
+}
+
+func (r *memoryUsers) findOne(match Fields) (*models.User, error) {
+	var user models.User
+	found, err := r.docs.findOne(match, &user)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUsers) Insert(ctx context.Context, user *models.User) error {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if err := r.docs.insert(user); err == errDuplicate {
+		return services.ErrUserExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *memoryUsers) Update(ctx context.Context, id primitive.ObjectID, set bson.M, unset ...string) error {
+	found, err := r.docs.set(Fields{"_id": id}, set, unset...)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *memoryUsers) FindByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	return r.first(r.docs.filter(func(doc bson.Raw) bool {
+		var user struct {
+			Identities []models.ExternalIdentity `bson:"identities"`
+		}
+		if err := bson.Unmarshal(doc, &user); err != nil {
+			return false
+		}
+		for _, identity := range user.Identities {
+			if identity.Provider == provider && identity.Subject == subject {
+				return true
+			}
+		}
+		return false
+	}))
+}
+
+func (r *memoryUsers) FindByEmailFold(ctx context.Context, email string) (*models.User, error) {
+	return r.first(r.docs.filter(func(doc bson.Raw) bool {
+		stored, ok := doc.Lookup("email").StringValueOK()
+		return ok && strings.EqualFold(stored, email)
+	}))
+}
+
+// first - The first of found, decoded
+func (r *memoryUsers) first(found []bson.Raw) (*models.User, error) {
+	if len(found) == 0 {
+		return nil, services.ErrUserNotFound
+	}
+	var user models.User
+	if err := bson.Unmarshal(found[0], &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *memoryUsers) AddIdentity(ctx context.Context, id primitive.ObjectID, identity models.ExternalIdentity, set bson.M, unset ...string) error {
+	found, err := r.docs.update(Fields{"_id": id}, func(doc bson.D) (bson.D, error) {
+		identities := bson.A{}
+		for _, e := range doc {
+			if linked, ok := e.Value.(bson.A); ok && e.Key == "identities" {
+				identities = linked
+			}
+		}
+		doc = setField(doc, "identities", append(identities, identity))
+		return setFields(set, unset...)(doc)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *memoryUsers) ListDueForDeletion(ctx context.Context, now time.Time) ([]models.User, error) {
+	due := r.docs.filter(func(doc bson.Raw) bool { return atOrBefore(doc, "deletion_scheduled_at", now) })
+	users := []models.User{}
+	return users, decodeAll(due, &users)
+}
+
+func (r *memoryUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !r.docs.remove(Fields{"_id": id}) {
+		return services.ErrUserNotFound
+	}
+	return nil
+}
+
+type memoryAdmins struct {
+	docs *memoryCollection
+}
+
+func (r *memoryAdmins) Insert(ctx context.Context, admin *models.Admin) error {
+	if admin.ID.IsZero() {
+		admin.ID = primitive.NewObjectID()
+	}
+	if err := r.docs.insert(admin); err == errDuplicate {
+		return services.ErrAdminExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *memoryAdmins) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Admin, error) {
+	return r.findOne(Fields{"_id": id})
+}
+
+func (r *memoryAdmins) FindByEmail(ctx context.Context, email string) (*models.Admin, error) {
+	return r.findOne(Fields{"email": email})
+}
+
+func (r *memoryAdmins) findOne(match Fields) (*models.Admin, error) {
+	var raw bson.Raw
+	found, err := r.docs.findOne(match, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrAdminNotFound
+	}
+	return decodeAdmin(raw)
+}
+
+func (r *memoryAdmins) List(ctx context.Context) ([]models.Admin, error) {
+	var raws []bson.Raw
+	if err := r.docs.find(Fields{}, Sort{Field: "createdAt", Descending: true}, 0, &raws); err != nil {
+		return nil, err
+	}
+
+	admins := []models.Admin{}
+	for _, raw := range raws {
+		admin, err := decodeAdmin(raw)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, *admin)
+	}
+	return admins, nil
+}
+
+func (r *memoryAdmins) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	found, err := r.docs.set(Fields{"_id": id}, set)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrAdminNotFound
+	}
+	return nil
+}
+
+func (r *memoryAdmins) CountActiveSuperAdmins(ctx context.Context, exclude primitive.ObjectID) (int64, error) {
+	activeSuperAdmin := func(doc bson.Raw) bool {
+		if matches(doc, Fields{"_id": exclude}) || matches(doc, Fields{"isActive": false}) {
+			return false
+		}
+		for _, role := range superAdminRoles {
+			if matches(doc, Fields{"role": role}) {
+				return true
+			}
+		}
+		return false
+	}
+	return int64(r.docs.count(Fields{}, activeSuperAdmin)), nil
+}
+
+type memoryTutors struct {
+	docs *memoryCollection
+}
+
+func (r *memoryTutors) List(ctx context.Context) ([]models.TutorAccount, error) {
+	tutors := []models.TutorAccount{}
+	err := r.docs.find(Fields{}, Sort{Field: "createdAt", Descending: true}, 0, &tutors)
+	return tutors, err
+}
+
+func (r *memoryTutors) FindByID(ctx context.Context, id primitive.ObjectID) (*models.TutorAccount, error) {
+	return r.findOne(Fields{"_id": id})
+}
+
+func (r *memoryTutors) FindByEmail(ctx context.Context, email string) (*models.TutorAccount, error) {
+	return r.findOne(Fields{"email": email})
+}
+
+func (r *memoryTutors) findOne(match Fields) (*models.TutorAccount, error) {
+	var tutor models.TutorAccount
+	found, err := r.docs.findOne(match, &tutor)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrTutorNotFound
+	}
+	return &tutor, nil
+}
+
+func (r *memoryTutors) Insert(ctx context.Context, tutor *models.TutorAccount) error {
+	if tutor.ID.IsZero() {
+		tutor.ID = primitive.NewObjectID()
+	}
+	if err := r.docs.insert(tutor); err == errDuplicate {
+		return services.ErrTutorExists
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *memoryTutors) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	found, err := r.docs.set(Fields{"_id": id}, set)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrTutorNotFound
+	}
+	return nil
+}
+
+// memoryAccounts - Shares its collection with memoryUsers, memoryAdmins or
+// memoryTutors, like the Mongo implementations share theirs
+type memoryAccounts struct {
+	docs   *memoryCollection
+	fields accountFields
+}
+
+func (r *memoryAccounts) FindByID(ctx context.Context, id primitive.ObjectID) (*Account, error) {
+	return r.findOne(Fields{"_id": id})
+}
+
+func (r *memoryAccounts) FindByEmail(ctx context.Context, email string) (*Account, error) {
+	return r.findOne(Fields{"email": email})
+}
+
+func (r *memoryAccounts) findOne(match Fields) (*Account, error) {
+	var raw bson.Raw
+	found, err := r.docs.findOne(match, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrAccountNotFound
+	}
+	return decodeAccount(raw, r.fields)
+}
+
+func (r *memoryAccounts) SetPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string, now time.Time) error {
+	return r.update(id, setFields(bson.M{
+		"password":               hashedPassword,
+		r.fields.passwordChanged: now,
+		r.fields.updated:         now,
+	}))
+}
+
+func (r *memoryAccounts) BeginMFA(ctx context.Context, id primitive.ObjectID, secret string, recoveryCodeHashes []string) error {
+	return r.update(id, func(doc bson.D) (bson.D, error) {
+		settings, err := accountMFA(doc)
+		if err != nil {
+			return nil, err
+		}
+		settings.PendingSecret = secret
+		settings.PendingRecoveryCodeHashes = recoveryCodeHashes
+		return setField(doc, "mfa", settings), nil
+	})
+}
+
+func (r *memoryAccounts) SetMFA(ctx context.Context, id primitive.ObjectID, settings *models.MFASettings, now time.Time) error {
+	if settings == nil {
+		return r.update(id, setFields(bson.M{r.fields.updated: now}, "mfa"))
+	}
+	return r.update(id, setFields(bson.M{"mfa": settings, r.fields.updated: now}))
+}
+
+func (r *memoryAccounts) update(id primitive.ObjectID, change func(doc bson.D) (bson.D, error)) error {
+	found, err := r.docs.update(Fields{"_id": id}, change)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrAccountNotFound
+	}
+	return nil
+}
+
+func (r *memoryAccounts) UseMFAStep(ctx context.Context, id primitive.ObjectID, step int64) (bool, error) {
+	used := false
+	_, err := r.docs.update(Fields{"_id": id}, func(doc bson.D) (bson.D, error) {
+		settings, err := accountMFA(doc)
+		if err != nil || settings.LastUsedStep >= step {
+			return doc, err
+		}
+		settings.LastUsedStep = step
+		used = true
+		return setField(doc, "mfa", settings), nil
+	})
+	return used, err
+}
+
+func (r *memoryAccounts) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	used := false
+	_, err := r.docs.update(Fields{"_id": id}, func(doc bson.D) (bson.D, error) {
+		settings, err := accountMFA(doc)
+		if err != nil {
+			return nil, err
+		}
+		remaining := []string{}
+		for _, code := range settings.RecoveryCodeHashes {
+			if code == hash {
+				used = true
+				continue
+			}
+			remaining = append(remaining, code)
+		}
+		if !used {
+			return doc, nil
+		}
+		settings.RecoveryCodeHashes = remaining
+		return setField(doc, "mfa", settings), nil
+	})
+	return used, err
+}
+
+// accountMFA - The two-factor settings of an account document, empty if it has none
+func accountMFA(doc bson.D) (models.MFASettings, error) {
+	var settings models.MFASettings
+	for _, e := range doc {
+		if e.Key == "mfa" && e.Value != nil {
+			var holder struct {
+				MFA models.MFASettings `bson:"mfa"`
+			}
+			err := decodeDocument(bson.D{e}, &holder)
+			return holder.MFA, err
+		}
+	}
+	return settings, nil
+}
+
+// ============================================
+// SESSIONS
+// ============================================
+
+type memorySessions struct {
+	docs *memoryCollection
+}
+
+func (r *memorySessions) Insert(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(session)
+}
+
+func (r *memorySessions) Find(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	found, err := r.docs.findOne(Fields{"_id": id}, &session)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessions) Rotate(ctx context.Context, hash, newHash string, keep int, now time.Time) (*models.Session, error) {
+	holding := func(doc bson.Raw) bool {
+		return matches(doc, Fields{"refreshTokenHash": hash, "revokedAt": nil}) && after(doc, "expiresAt", now)
+	}
+
+	var session models.Session
+	n, err := r.docs.updateWhere(holding, 1, func(doc bson.D) (bson.D, error) {
+		if err := decodeDocument(doc, &session); err != nil {
+			return nil, err
+		}
+		session.RefreshTokenHash = newHash
+		session.UpdatedAt = now
+		session.RotatedTokenHashes = append(session.RotatedTokenHashes, hash)
+		if len(session.RotatedTokenHashes) > keep {
+			session.RotatedTokenHashes = session.RotatedTokenHashes[len(session.RotatedTokenHashes)-keep:]
+		}
+		return encodeDocument(session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, services.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *memorySessions) RevokeRotated(ctx context.Context, hash, reason string, now time.Time) error {
+	_, err := r.docs.set(Fields{"rotatedTokenHashes": hash, "revokedAt": nil}, revocation(reason, now))
+	return err
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, id primitive.ObjectID, match Fields, reason string, now time.Time) error {
+	active := Fields{"_id": id, "revokedAt": nil}
+	for key, value := range match {
+		active[key] = value
+	}
+
+	found, err := r.docs.set(active, revocation(reason, now))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *memorySessions) RevokeAll(ctx context.Context, accountType, accountID, reason string, now time.Time) error {
+	active := func(doc bson.Raw) bool {
+		return matches(doc, Fields{"accountType": accountType, "accountId": accountID, "revokedAt": nil})
+	}
+	_, err := r.docs.updateWhere(active, 0, setFields(revocation(reason, now)))
+	return err
+}
+
+func (r *memorySessions) ListActive(ctx context.Context, accountType, accountID string, now time.Time) ([]models.Session, error) {
+	found := r.docs.filter(func(doc bson.Raw) bool {
+		return matches(doc, Fields{"accountType": accountType, "accountId": accountID, "revokedAt": nil}) && after(doc, "expiresAt", now)
+	})
+	sort.SliceStable(found, func(i, j int) bool {
+		if cmp := compareValues(found[i].Lookup("lastSeenAt"), found[j].Lookup("lastSeenAt")); cmp != 0 {
+			return cmp > 0
+		}
+		return compareValues(found[i].Lookup("createdAt"), found[j].Lookup("createdAt")) > 0
+	})
+
+	sessions := []models.Session{}
+	return sessions, decodeAll(found, &sessions)
+}
+
+func (r *memorySessions) ListByAccount(ctx context.Context, accountType, accountID string) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := r.docs.find(Fields{"accountType": accountType, "accountId": accountID}, Sort{}, 0, &sessions)
+	return sessions, err
+}
+
+func (r *memorySessions) SetDevice(ctx context.Context, id primitive.ObjectID, userAgent, ip string, now time.Time) error {
+	_, err := r.docs.set(Fields{"_id": id}, bson.M{"lastSeenAt": now, "userAgent": userAgent, "ip": ip})
+	return err
+}
+
+func (r *memorySessions) DeleteByAccount(ctx context.Context, accountType, accountID string) error {
+	r.docs.removeWhere(func(doc bson.Raw) bool {
+		return matches(doc, Fields{"accountType": accountType, "accountId": accountID})
+	}, 0)
+	return nil
+}
+
+type memoryRevokedTokens struct {
+	docs *memoryCollection
+}
+
+func (r *memoryRevokedTokens) Add(ctx context.Context, token *models.RevokedToken) (bool, error) {
+	if err := r.docs.insert(token); err == errDuplicate {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *memoryRevokedTokens) Contains(ctx context.Context, jti string) (bool, error) {
+	return r.docs.count(Fields{"jti": jti}, nil) > 0, nil
+}
+
+// ============================================
+// LOGIN ATTEMPTS
+// ============================================
+
+type memoryLoginAttempts struct {
+	docs *memoryCollection
+}
+
+func (r *memoryLoginAttempts) ListLocked(ctx context.Context, keys []string, now time.Time) ([]models.LoginAttempt, error) {
+	found := r.docs.filter(func(doc bson.Raw) bool {
+		key, _ := doc.Lookup("key").StringValueOK()
+		return slices.Contains(keys, key) && after(doc, "lockedUntil", now)
+	})
+	attempts := []models.LoginAttempt{}
+	return attempts, decodeAll(found, &attempts)
+}
+
+func (r *memoryLoginAttempts) AddFailure(ctx context.Context, attempt *models.LoginAttempt, now, expiresAt time.Time) (*models.LoginAttempt, error) {
+	r.docs.removeWhere(func(doc bson.Raw) bool {
+		return matches(doc, Fields{"key": attempt.Key}) && atOrBefore(doc, "expiresAt", now)
+	}, 1)
+
+	for {
+		var counted models.LoginAttempt
+		found, err := r.docs.update(Fields{"key": attempt.Key}, func(doc bson.D) (bson.D, error) {
+			if err := decodeDocument(doc, &counted); err != nil {
+				return nil, err
+			}
+			counted.Failures++
+			counted.LastFailureAt = now
+			if expiresAt.After(counted.ExpiresAt) {
+				counted.ExpiresAt = expiresAt
+			}
+			return encodeDocument(counted)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return &counted, nil
+		}
+
+		counted = models.LoginAttempt{
+			ID:            primitive.NewObjectID(),
+			Key:           attempt.Key,
+			Kind:          attempt.Kind,
+			AccountType:   attempt.AccountType,
+			Identifier:    attempt.Identifier,
+			Failures:      1,
+			LastFailureAt: now,
+			ExpiresAt:     expiresAt,
+		}
+		// Another failure may have created the counter first; count on it then
+		if err := r.docs.insert(counted); err != errDuplicate {
+			return &counted, err
+		}
+	}
+}
+
+func (r *memoryLoginAttempts) Lock(ctx context.Context, attempt *models.LoginAttempt, lockedUntil, expiresAt time.Time) error {
+	_, err := r.docs.update(Fields{"_id": attempt.ID, "failures": attempt.Failures}, func(doc bson.D) (bson.D, error) {
+		var locked models.LoginAttempt
+		if err := decodeDocument(doc, &locked); err != nil {
+			return nil, err
+		}
+		locked.Failures = 0
+		locked.Lockouts++
+		locked.LockedUntil = &lockedUntil
+		locked.ExpiresAt = expiresAt
+		return encodeDocument(locked)
+	})
+	return err
+}
+
+func (r *memoryLoginAttempts) List(ctx context.Context, all bool, now time.Time) ([]models.LoginAttempt, error) {
+	field := "lockedUntil"
+	if all {
+		field = "expiresAt"
+	}
+	found := r.docs.filter(func(doc bson.Raw) bool { return after(doc, field, now) })
+	sort.SliceStable(found, func(i, j int) bool {
+		return compareValues(found[i].Lookup("lastFailureAt"), found[j].Lookup("lastFailureAt")) > 0
+	})
+
+	attempts := []models.LoginAttempt{}
+	return attempts, decodeAll(found, &attempts)
+}
+
+func (r *memoryLoginAttempts) DeleteByKey(ctx context.Context, key string) error {
+	r.docs.remove(Fields{"key": key})
+	return nil
+}
+
+func (r *memoryLoginAttempts) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if !r.docs.remove(Fields{"_id": id}) {
+		return services.ErrLockoutNotFound
+	}
+	return nil
+}
+
+// ============================================
+// PASSWORD RESETS
+// ============================================
+
+type memoryPasswordResets struct {
+	docs *memoryCollection
+}
+
+func (r *memoryPasswordResets) Insert(ctx context.Context, reset *models.PasswordReset) error {
+	if reset.ID.IsZero() {
+		reset.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(reset)
+}
+
+func (r *memoryPasswordResets) Claim(ctx context.Context, tokenHash, accountType string, now time.Time) (*models.PasswordReset, error) {
+	claimable := func(doc bson.Raw) bool {
+		return matches(doc, Fields{"tokenHash": tokenHash, "accountType": accountType, "usedAt": nil}) && after(doc, "expiresAt", now)
+	}
+
+	var reset models.PasswordReset
+	n, err := r.docs.updateWhere(claimable, 1, func(doc bson.D) (bson.D, error) {
+		if err := decodeDocument(doc, &reset); err != nil {
+			return nil, err
+		}
+		return setField(doc, "usedAt", now), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, services.ErrInvalidResetToken
+	}
+	return &reset, nil
+}
+
+func (r *memoryPasswordResets) InvalidateAll(ctx context.Context, accountType string, accountID primitive.ObjectID, now time.Time) error {
+	unused := func(doc bson.Raw) bool {
+		return matches(doc, Fields{"accountType": accountType, "accountId": accountID, "usedAt": nil})
+	}
+	_, err := r.docs.updateWhere(unused, 0, setFields(bson.M{"usedAt": now}))
+	return err
+}
+
+func (r *memoryPasswordResets) DeleteByAccount(ctx context.Context, accountType string, accountID primitive.ObjectID) error {
+	r.docs.removeWhere(func(doc bson.Raw) bool {
+		return matches(doc, Fields{"accountType": accountType, "accountId": accountID})
+	}, 0)
+	return nil
+}
+
+// ============================================
+// OIDC STATES
+// ============================================
+
+type memoryOIDCStates struct {
+	docs *memoryCollection
+}
+
+func (r *memoryOIDCStates) Insert(ctx context.Context, state *models.OIDCState) error {
+	if state.ID.IsZero() {
+		state.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(state)
+}
+
+func (r *memoryOIDCStates) Take(ctx context.Context, stateHash, provider string, now time.Time) (*models.OIDCState, error) {
+	var taken bson.Raw
+	r.docs.removeWhere(func(doc bson.Raw) bool {
+		if matches(doc, Fields{"stateHash": stateHash, "provider": provider}) && after(doc, "expiresAt", now) {
+			taken = doc
+			return true
+		}
+		return false
+	}, 1)
+	if taken == nil {
+		return nil, services.ErrInvalidOIDCState
+	}
+
+	var state models.OIDCState
+	if err := bson.Unmarshal(taken, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ============================================
+// API KEYS
+// ============================================
+
+type memoryAPIKeys struct {
+	docs *memoryCollection
+}
+
+func (r *memoryAPIKeys) Insert(ctx context.Context, key *models.APIKey) error {
+	if key.ID.IsZero() {
+		key.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(key)
+}
+
+func (r *memoryAPIKeys) List(ctx context.Context, ownerID string) ([]models.APIKey, error) {
+	match := Fields{}
+	if ownerID != "" {
+		match["ownerId"] = ownerID
+	}
+	keys := []models.APIKey{}
+	err := r.docs.find(match, Sort{Field: "createdAt", Descending: true}, 0, &keys)
+	return keys, err
+}
+
+func (r *memoryAPIKeys) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	found, err := r.docs.findOne(Fields{"prefix": prefix}, &key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, services.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+func (r *memoryAPIKeys) Revoke(ctx context.Context, id primitive.ObjectID, ownerID string, now time.Time) error {
+	match := Fields{"_id": id, "revokedAt": nil}
+	if ownerID != "" {
+		match["ownerId"] = ownerID
+	}
+
+	found, err := r.docs.set(match, bson.M{"revokedAt": now})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return services.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *memoryAPIKeys) MarkUsed(ctx context.Context, id primitive.ObjectID, ip string, now time.Time) error {
+	_, err := r.docs.set(Fields{"_id": id}, bson.M{"lastUsedAt": now, "lastUsedIp": ip})
+	return err
+}
+
+// ============================================
+// AUDIT LOG
+// ============================================
+
+type memoryAuditLogs struct {
+	docs *memoryCollection
+}
+
+func (r *memoryAuditLogs) Insert(ctx context.Context, entry *models.AuditLog) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	return r.docs.insert(entry)
+}
+
+func (r *memoryAuditLogs) SetStatus(ctx context.Context, id primitive.ObjectID, status int) error {
+	_, err := r.docs.set(Fields{"_id": id}, bson.M{"status": status})
+	return err
+}
+
+func (r *memoryAuditLogs) List(ctx context.Context, match Fields, limit int64) ([]models.AuditLog, error) {
+	entries := []models.AuditLog{}
+	err := r.docs.find(match, Sort{Field: "createdAt", Descending: true}, int(limit), &entries)
+	return entries, err
+}
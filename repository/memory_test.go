@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rawDoc(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func rawValue(t *testing.T, v interface{}) bson.RawValue {
+	t.Helper()
+	if v == nil {
+		return bson.RawValue{}
+	}
+	typ, data, err := bson.MarshalValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bson.RawValue{Type: typ, Value: data}
+}
+
+func TestMatches(t *testing.T) {
+	id := primitive.NewObjectID()
+	doc := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "email", Value: "ada@example.com"},
+		{Key: "count", Value: int32(3)},
+		{Key: "deletedAt", Value: nil},
+		{Key: "tags", Value: bson.A{"go", "mongo"}},
+	}
+
+	tests := []struct {
+		name  string
+		match Fields
+		want  bool
+	}{
+		{"empty match", Fields{}, true},
+		{"equal string", Fields{"email": "ada@example.com"}, true},
+		{"different string", Fields{"email": "grace@example.com"}, false},
+		{"object id", Fields{"_id": id}, true},
+		{"int32 against int64", Fields{"count": int64(3)}, true},
+		{"int32 against double", Fields{"count": 3.0}, true},
+		{"different number", Fields{"count": 4}, false},
+		{"number against string", Fields{"count": "3"}, false},
+		{"nil against null", Fields{"deletedAt": nil}, true},
+		{"nil against missing", Fields{"revokedAt": nil}, true},
+		{"nil against a value", Fields{"email": nil}, false},
+		{"value against missing", Fields{"revokedAt": "x"}, false},
+		{"array holds value", Fields{"tags": "go"}, true},
+		{"array lacks value", Fields{"tags": "rust"}, false},
+		{"equal array", Fields{"tags": bson.A{"go", "mongo"}}, true},
+		{"array in other order", Fields{"tags": bson.A{"mongo", "go"}}, false},
+		{"all fields must match", Fields{"email": "ada@example.com", "count": 4}, false},
+		{"raw value", Fields{"email": rawValue(t, "ada@example.com")}, true},
+	}
+
+	raw := rawDoc(t, doc)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(raw, tt.match); got != tt.want {
+				t.Errorf("matches(%v) = %v, want %v", tt.match, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		a, b interface{}
+		want int
+	}{
+		{"equal ints", int32(2), int32(2), 0},
+		{"int32 and int64", int32(2), int64(3), -1},
+		{"double and int", 2.5, int32(2), 1},
+		{"equal number types", int64(2), 2.0, 0},
+		{"strings", "apple", "banana", -1},
+		{"equal strings", "apple", "apple", 0},
+		{"booleans", true, false, 1},
+		{"dates", later, earlier, 1},
+		{"missing before numbers", nil, int32(0), -1},
+		{"null before numbers", primitive.Null{}, int32(0), -1},
+		{"missing and null", nil, primitive.Null{}, 0},
+		{"numbers before strings", int32(9), "1", -1},
+		{"strings before booleans", "z", false, -1},
+		{"booleans before dates", true, earlier, -1},
+		{"dates before other types", later, primitive.NewObjectID(), -1},
+		{"other types are equal", primitive.NewObjectID(), primitive.NewObjectID(), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareValues(rawValue(t, tt.a), rawValue(t, tt.b))
+			if sign(got) != tt.want {
+				t.Errorf("compareValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// OIDCStateRepository - Authorization requests waiting for the provider's callback
+type OIDCStateRepository interface {
+	// Insert assigns an id if the state has none
+	Insert(ctx context.Context, state *models.OIDCState) error
+
+	// Take removes and returns the provider's unexpired state with this hash,
+	// so each state is used once. Returns services.ErrInvalidOIDCState when
+	// there is none.
+	Take(ctx context.Context, stateHash, provider string, now time.Time) (*models.OIDCState, error)
+}
+
+type mongoOIDCStates struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoOIDCStates) Insert(ctx context.Context, state *models.OIDCState) error {
+	if state.ID.IsZero() {
+		state.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, state)
+	return err
+}
+
+func (r *mongoOIDCStates) Take(ctx context.Context, stateHash, provider string, now time.Time) (*models.OIDCState, error) {
+	var state models.OIDCState
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"stateHash": stateHash,
+		"provider":  provider,
+		"expiresAt": bson.M{"$gt": now},
+	}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrInvalidOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
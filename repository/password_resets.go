@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PasswordResetRepository - Single-use password reset and invitation tokens,
+// stored by hash
+type PasswordResetRepository interface {
+	// Insert assigns an id if the reset has none
+	Insert(ctx context.Context, reset *models.PasswordReset) error
+
+	// Claim marks the unused, unexpired token as used and returns it, in one
+	// step so it can only be claimed once. Returns
+	// services.ErrInvalidResetToken when there is no such token.
+	Claim(ctx context.Context, tokenHash, accountType string, now time.Time) (*models.PasswordReset, error)
+
+	// InvalidateAll marks every unused token of the account as used
+	InvalidateAll(ctx context.Context, accountType string, accountID primitive.ObjectID, now time.Time) error
+
+	// DeleteByAccount removes every token of the account
+	DeleteByAccount(ctx context.Context, accountType string, accountID primitive.ObjectID) error
+}
+
+type mongoPasswordResets struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPasswordResets) Insert(ctx context.Context, reset *models.PasswordReset) error {
+	if reset.ID.IsZero() {
+		reset.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, reset)
+	return err
+}
+
+func (r *mongoPasswordResets) Claim(ctx context.Context, tokenHash, accountType string, now time.Time) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{
+		"tokenHash":   tokenHash,
+		"accountType": accountType,
+		"usedAt":      bson.M{"$exists": false},
+		"expiresAt":   bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{"usedAt": now}}).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+func (r *mongoPasswordResets) InvalidateAll(ctx context.Context, accountType string, accountID primitive.ObjectID, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{
+		"accountType": accountType,
+		"accountId":   accountID,
+		"usedAt":      bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"usedAt": now}})
+	return err
+}
+
+func (r *mongoPasswordResets) DeleteByAccount(ctx context.Context, accountType string, accountID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"accountType": accountType, "accountId": accountID})
+	return err
+}
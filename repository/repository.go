@@ -0,0 +1,138 @@
+// Package repository holds the data access that is shared by controllers and
+// services, so lookups are written once. Every repository has a MongoDB
+// implementation and an in-memory one, which lets handlers run without a
+// database:
+//
+//	repository.Use(repository.NewMemoryStore())
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Store - The repositories the API works through
+type Store struct {
+	Courses     CourseRepository
+	Enrollments EnrollmentRepository
+	Users       UserRepository
+	Admins      AdminRepository
+	Tutors      TutorRepository
+	Accounts    Accounts
+	Reviews     ReviewRepository
+	Comments    CourseCommentRepository
+	Revisions   CourseRevisionRepository
+
+	Sessions       SessionRepository
+	RevokedTokens  RevokedTokenRepository
+	LoginAttempts  LoginAttemptRepository
+	PasswordResets PasswordResetRepository
+	OIDCStates     OIDCStateRepository
+	APIKeys        APIKeyRepository
+	AuditLogs      AuditLogRepository
+}
+
+var (
+	storeMu sync.Mutex
+	store   *Store
+)
+
+// Default - The store in use. Unless Use was called first, this is the
+// MongoDB store, which connects to the database on first use.
+func Default() *Store {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if store == nil {
+		store = NewMongoStore(database.GetDB())
+	}
+	return store
+}
+
+// Use - Replaces the store, e.g. with NewMemoryStore() in tests
+func Use(s *Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// NewMongoStore - Repositories backed by the collections of db
+func NewMongoStore(db *mongo.Database) *Store {
+	students, admins, tutors := db.Collection("students"), db.Collection("admins"), db.Collection("tutors")
+	return &Store{
+		Courses:     &mongoCourses{collection: db.Collection("courses")},
+		Enrollments: &mongoEnrollments{collection: db.Collection("enrollments")},
+		Users:       &mongoUsers{collection: students},
+		Admins:      &mongoAdmins{collection: admins},
+		Tutors:      &mongoTutors{collection: tutors},
+		Accounts: Accounts{
+			Students: &mongoAccounts{collection: students, fields: studentAccountFields},
+			Admins:   &mongoAccounts{collection: admins, fields: staffAccountFields},
+			Tutors:   &mongoAccounts{collection: tutors, fields: staffAccountFields},
+		},
+		Reviews:   &mongoReviews{collection: db.Collection("reviews")},
+		Comments:  &mongoCourseComments{collection: db.Collection("course_comments")},
+		Revisions: &mongoCourseRevisions{collection: db.Collection("course_revisions")},
+
+		Sessions:       &mongoSessions{collection: db.Collection("sessions")},
+		RevokedTokens:  &mongoRevokedTokens{collection: db.Collection("revoked_tokens")},
+		LoginAttempts:  &mongoLoginAttempts{collection: db.Collection("login_attempts")},
+		PasswordResets: &mongoPasswordResets{collection: db.Collection("password_resets")},
+		OIDCStates:     &mongoOIDCStates{collection: db.Collection("oidc_states")},
+		APIKeys:        &mongoAPIKeys{collection: db.Collection("api_keys")},
+		AuditLogs:      &mongoAuditLogs{collection: db.Collection("audit_logs")},
+	}
+}
+
+// Fields - Top-level fields a document must be equal to (nil matches a
+// missing field too). This is the part of a Mongo filter every
+// implementation can evaluate.
+type Fields map[string]interface{}
+
+func (f Fields) filter() bson.M {
+	filter := bson.M{}
+	for key, value := range f {
+		filter[key] = value
+	}
+	return filter
+}
+
+// Sort - Order of a listing by one top-level field
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
+func (s Sort) document() bson.D {
+	order := 1
+	if s.Descending {
+		order = -1
+	}
+	return bson.D{{Key: s.Field, Value: order}}
+}
+
+// decodeAll - Decodes docs into out, a pointer to a slice
+func decodeAll(docs []bson.Raw, out interface{}) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("repository: out must be a pointer to a slice, got %T", out)
+	}
+
+	elemType := slice.Elem().Type().Elem()
+	result := reflect.MakeSlice(slice.Elem().Type(), 0, len(docs))
+	for _, doc := range docs {
+		elem := reflect.New(elemType)
+		if err := bson.Unmarshal(doc, elem.Interface()); err != nil {
+			return err
+		}
+		result = reflect.Append(result, elem.Elem())
+	}
+	slice.Elem().Set(result)
+	return nil
+}
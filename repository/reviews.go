@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReviewRepository - Course reviews, one per student and course
+type ReviewRepository interface {
+	// Get returns services.ErrReviewNotFound when there is no such review
+	Get(ctx context.Context, id primitive.ObjectID) (*models.Review, error)
+
+	// FindByUserAndCourse returns services.ErrReviewNotFound when the student
+	// hasn't reviewed the course
+	FindByUserAndCourse(ctx context.Context, userID primitive.ObjectID, courseID string) (*models.Review, error)
+
+	// ListByCourse returns the newest reviews of a course first; limit 0 means all
+	ListByCourse(ctx context.Context, courseID string, limit int64) ([]models.Review, error)
+
+	// ListByUser returns every review the student wrote
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Review, error)
+
+	// Insert assigns an id if the review has none. Returns
+	// services.ErrReviewExists for a second review of the same course.
+	Insert(ctx context.Context, review *models.Review) error
+
+	// Update changes the rating and comment and returns the updated review
+	Update(ctx context.Context, id primitive.ObjectID, rating int, comment string, updatedAt time.Time) (*models.Review, error)
+
+	// UpdateByUser sets and unsets top-level fields on every review the student wrote
+	UpdateByUser(ctx context.Context, userID primitive.ObjectID, set bson.M, unset ...string) error
+
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// Rating - Average rating and number of reviews of a course
+	Rating(ctx context.Context, courseID string) (float64, int, error)
+}
+
+type mongoReviews struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoReviews) Get(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoReviews) FindByUserAndCourse(ctx context.Context, userID primitive.ObjectID, courseID string) (*models.Review, error) {
+	return r.findOne(ctx, bson.M{"user_id": userID, "course_id": courseID})
+}
+
+func (r *mongoReviews) findOne(ctx context.Context, filter bson.M) (*models.Review, error) {
+	var review models.Review
+	err := r.collection.FindOne(ctx, filter).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *mongoReviews) ListByCourse(ctx context.Context, courseID string, limit int64) ([]models.Review, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	return r.list(ctx, bson.M{"course_id": courseID}, opts)
+}
+
+func (r *mongoReviews) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Review, error) {
+	return r.list(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoReviews) list(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]models.Review, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := []models.Review{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+func (r *mongoReviews) Insert(ctx context.Context, review *models.Review) error {
+	if review.ID.IsZero() {
+		review.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, review)
+	if mongo.IsDuplicateKeyError(err) {
+		return services.ErrReviewExists
+	}
+	return err
+}
+
+func (r *mongoReviews) Update(ctx context.Context, id primitive.ObjectID, rating int, comment string, updatedAt time.Time) (*models.Review, error) {
+	update := bson.M{"$set": bson.M{
+		"rating":     rating,
+		"comment":    comment,
+		"updated_at": updatedAt,
+	}}
+
+	var review models.Review
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&review)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *mongoReviews) UpdateByUser(ctx context.Context, userID primitive.ObjectID, set bson.M, unset ...string) error {
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, field := range unset {
+			fields[field] = ""
+		}
+		update["$unset"] = fields
+	}
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID}, update)
+	return err
+}
+
+func (r *mongoReviews) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return services.ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *mongoReviews) Rating(ctx context.Context, courseID string) (float64, int, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"course_id": courseID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          nil,
+			"avgRating":    bson.M{"$avg": "$rating"},
+			"totalReviews": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var result []struct {
+		AvgRating    float64 `bson:"avgRating"`
+		TotalReviews int     `bson:"totalReviews"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, 0, err
+	}
+	if len(result) == 0 {
+		return 0, 0, nil
+	}
+	return result[0].AvgRating, result[0].TotalReviews, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SessionRepository - Logins and their refresh tokens. A session stays
+// active until it is revoked; expired sessions are only left out where a
+// method says so.
+type SessionRepository interface {
+	// Insert assigns an id if the session has none
+	Insert(ctx context.Context, session *models.Session) error
+
+	// Find returns services.ErrSessionNotFound when there is no such session
+	Find(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+
+	// Rotate replaces the refresh token hash of the unexpired active session
+	// holding it, in one step so a token can only be rotated once. The old
+	// hash is kept, up to keep of them, to detect reuse. Returns the updated
+	// session, or services.ErrSessionNotFound.
+	Rotate(ctx context.Context, hash, newHash string, keep int, now time.Time) (*models.Session, error)
+
+	// RevokeRotated revokes the active session that held hash before it was rotated
+	RevokeRotated(ctx context.Context, hash, reason string, now time.Time) error
+
+	// Revoke revokes the session if it is active and matches match. Returns
+	// services.ErrSessionNotFound otherwise.
+	Revoke(ctx context.Context, id primitive.ObjectID, match Fields, reason string, now time.Time) error
+
+	// RevokeAll revokes every active session of the account
+	RevokeAll(ctx context.Context, accountType, accountID, reason string, now time.Time) error
+
+	// ListActive returns the account's active, unexpired sessions, most recently used first
+	ListActive(ctx context.Context, accountType, accountID string, now time.Time) ([]models.Session, error)
+
+	// ListByAccount returns every session of the account, revoked and expired ones too
+	ListByAccount(ctx context.Context, accountType, accountID string) ([]models.Session, error)
+
+	// SetDevice records when and from where the session was last used
+	SetDevice(ctx context.Context, id primitive.ObjectID, userAgent, ip string, now time.Time) error
+
+	// DeleteByAccount removes every session of the account
+	DeleteByAccount(ctx context.Context, accountType, accountID string) error
+}
+
+// RevokedTokenRepository - Access tokens revoked before they expire, by their
+// "jti" claim
+type RevokedTokenRepository interface {
+	// Add keeps the first revocation of a token; false if it was already revoked
+	Add(ctx context.Context, token *models.RevokedToken) (bool, error)
+
+	Contains(ctx context.Context, jti string) (bool, error)
+}
+
+// revocation - The fields set when a session is revoked
+func revocation(reason string, now time.Time) bson.M {
+	return bson.M{"revokedAt": now, "revokedReason": reason, "updatedAt": now}
+}
+
+type mongoSessions struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoSessions) Insert(ctx context.Context, session *models.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *mongoSessions) Find(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *mongoSessions) Rotate(ctx context.Context, hash, newHash string, keep int, now time.Time) (*models.Session, error) {
+	var session models.Session
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{
+		"refreshTokenHash": hash,
+		"revokedAt":        bson.M{"$exists": false},
+		"expiresAt":        bson.M{"$gt": now},
+	}, bson.M{
+		"$set": bson.M{
+			"refreshTokenHash": newHash,
+			"updatedAt":        now,
+		},
+		"$push": bson.M{
+			"rotatedTokenHashes": bson.M{"$each": []string{hash}, "$slice": -keep},
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, services.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *mongoSessions) RevokeRotated(ctx context.Context, hash, reason string, now time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{
+		"rotatedTokenHashes": hash,
+		"revokedAt":          bson.M{"$exists": false},
+	}, bson.M{"$set": revocation(reason, now)})
+	return err
+}
+
+func (r *mongoSessions) Revoke(ctx context.Context, id primitive.ObjectID, match Fields, reason string, now time.Time) error {
+	filter := match.filter()
+	filter["_id"] = id
+	filter["revokedAt"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": revocation(reason, now)})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return services.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *mongoSessions) RevokeAll(ctx context.Context, accountType, accountID, reason string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{
+		"accountType": accountType,
+		"accountId":   accountID,
+		"revokedAt":   bson.M{"$exists": false},
+	}, bson.M{"$set": revocation(reason, now)})
+	return err
+}
+
+func (r *mongoSessions) ListActive(ctx context.Context, accountType, accountID string, now time.Time) ([]models.Session, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}, {Key: "createdAt", Value: -1}})
+	return r.list(ctx, bson.M{
+		"accountType": accountType,
+		"accountId":   accountID,
+		"revokedAt":   bson.M{"$exists": false},
+		"expiresAt":   bson.M{"$gt": now},
+	}, opts)
+}
+
+func (r *mongoSessions) ListByAccount(ctx context.Context, accountType, accountID string) ([]models.Session, error) {
+	return r.list(ctx, bson.M{"accountType": accountType, "accountId": accountID})
+}
+
+func (r *mongoSessions) list(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]models.Session, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *mongoSessions) SetDevice(ctx context.Context, id primitive.ObjectID, userAgent, ip string, now time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"lastSeenAt": now,
+		"userAgent":  userAgent,
+		"ip":         ip,
+	}})
+	return err
+}
+
+func (r *mongoSessions) DeleteByAccount(ctx context.Context, accountType, accountID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"accountType": accountType, "accountId": accountID})
+	return err
+}
+
+type mongoRevokedTokens struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRevokedTokens) Add(ctx context.Context, token *models.RevokedToken) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"jti": token.JTI}, bson.M{"$setOnInsert": token}, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+func (r *mongoRevokedTokens) Contains(ctx context.Context, jti string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"jti": jti}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,177 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+)
+
+var testCourse = map[string]interface{}{
+	"title":       "Introduction to Algebra",
+	"description": "Equations, inequalities and graphs",
+	"type":        "academic",
+	"level":       "beginner",
+	"category":    "mathematics",
+	"price":       5000,
+	"tags":        []string{"algebra", "equations"},
+}
+
+// createCourse - Creates a course as the admin and returns its id
+func (s *testServer) createCourse(token string, course map[string]interface{}) string {
+	s.t.Helper()
+	created := s.expect(http.StatusCreated, http.MethodPost, "/admin/courses", course, bearer(token)...)
+	data, _ := created["course"].(map[string]interface{})
+	id, _ := data["id"].(string)
+	if id == "" || data["status"] != models.CourseStatusDraft {
+		s.t.Fatalf("POST /admin/courses = %v, want a draft with an id", created)
+	}
+	return id
+}
+
+// publishCourse - Moves a draft through review to published
+func (s *testServer) publishCourse(token, id string) {
+	s.t.Helper()
+	s.expect(http.StatusOK, http.MethodPost, "/admin/courses/"+id+"/status", map[string]string{"status": models.CourseStatusInReview}, bearer(token)...)
+	s.expect(http.StatusOK, http.MethodPost, "/admin/courses/"+id+"/publish", nil, bearer(token)...)
+}
+
+func TestCourseCreateAndUpdate(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("editor@example.com", models.RoleContentAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	id := s.createCourse(token, testCourse)
+
+	s.expect(http.StatusOK, http.MethodPut, "/admin/courses/"+id, map[string]interface{}{"price": 7500, "level": "Intermediate"}, bearer(token)...)
+	var course models.Course
+	if err := s.store.Courses.Find(context.Background(), id, repository.Fields{}, &course); err != nil {
+		t.Fatal(err)
+	}
+	if course.Price != 7500 || course.Level != "intermediate" || course.Title != testCourse["title"] {
+		t.Errorf("updated course = %+v, want the new price and level and the old title", course)
+	}
+
+	s.expect(http.StatusBadRequest, http.MethodPut, "/admin/courses/"+id, map[string]interface{}{"price": -1}, bearer(token)...)
+	s.expect(http.StatusNotFound, http.MethodPut, "/admin/courses/unknown", map[string]interface{}{"price": 1}, bearer(token)...)
+
+	student := s.addStudent("ada@example.com")
+	studentToken, _ := s.login("/login", student.Email)
+	s.expect(http.StatusForbidden, http.MethodPost, "/admin/courses", testCourse, bearer(studentToken)...)
+}
+
+func TestCourseCatalogListsPublishedCourses(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	published := s.createCourse(token, testCourse)
+	s.publishCourse(token, published)
+	s.createCourse(token, map[string]interface{}{"title": "Geometry draft", "category": "mathematics"})
+
+	list := s.expect(http.StatusOK, http.MethodGet, "/courses?category=mathematics", nil)
+	courses, _ := list["courses"].([]interface{})
+	if len(courses) != 1 || list["total"] != float64(1) {
+		t.Fatalf("GET /courses = %v, want only the published course", list)
+	}
+	if id, _ := courses[0].(map[string]interface{})["id"].(string); id != published {
+		t.Errorf("GET /courses listed %q, want %q", id, published)
+	}
+
+	s.expect(http.StatusBadRequest, http.MethodGet, "/courses?sortBy=password", nil)
+}
+
+func TestCourseSearch(t *testing.T) {
+	// Rebuild the shared index on every search so it sees this test's store
+	t.Setenv("COURSE_SEARCH_REFRESH", "1ns")
+
+	s := newTestServer(t)
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	id := s.createCourse(token, testCourse)
+	if found := s.expect(http.StatusOK, http.MethodGet, "/courses/search?q=algebra", nil); found["total"] != float64(0) {
+		t.Fatalf("GET /courses/search = %v, want drafts left out", found)
+	}
+
+	s.publishCourse(token, id)
+	found := s.expect(http.StatusOK, http.MethodGet, "/courses/search?q=algebra", nil)
+	results, _ := found["results"].([]interface{})
+	if len(results) != 1 {
+		t.Fatalf("GET /courses/search = %v, want the published course", found)
+	}
+	hit, _ := results[0].(map[string]interface{})
+	course, _ := hit["course"].(map[string]interface{})
+	if course["id"] != id {
+		t.Errorf("search hit = %v, want course %q", hit, id)
+	}
+
+	s.expect(http.StatusBadRequest, http.MethodGet, "/courses/search", nil)
+}
+
+func TestEnrollInCourse(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	adminToken, _ := s.login("/admin/login", admin.Email)
+	student := s.addStudent("ada@example.com")
+	token, _ := s.login("/login", student.Email)
+
+	id := s.createCourse(adminToken, testCourse)
+	s.expect(http.StatusNotFound, http.MethodPost, "/user/enroll/"+id, nil, bearer(token)...)
+
+	s.publishCourse(adminToken, id)
+	enrolled := s.expect(http.StatusOK, http.MethodPost, "/user/enroll/"+id, nil, bearer(token)...)
+	if enrollment, _ := enrolled["enrollment"].(map[string]interface{}); enrollment == nil {
+		t.Fatalf("POST /user/enroll = %v, want the enrollment", enrolled)
+	}
+	s.expect(http.StatusBadRequest, http.MethodPost, "/user/enroll/"+id, nil, bearer(token)...)
+
+	var course models.Course
+	if err := s.store.Courses.Find(context.Background(), id, repository.Fields{}, &course); err != nil {
+		t.Fatal(err)
+	}
+	if course.EnrollmentCount != 1 {
+		t.Errorf("enrollmentCount = %d, want 1", course.EnrollmentCount)
+	}
+
+	list := s.expect(http.StatusOK, http.MethodGet, "/user/enrollments", nil, bearer(token)...)
+	if list["count"] != float64(1) {
+		t.Errorf("GET /user/enrollments = %v, want one enrollment", list)
+	}
+}
+
+func TestCourseInputErrors(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("editor@example.com", models.RoleContentAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	workflow := "is changed through the review workflow, see /admin/courses/:id/status"
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields map[string]interface{}
+	}{
+		{"no body", nil, map[string]interface{}{"body": "is required"}},
+		{"missing title", map[string]interface{}{"price": 10}, map[string]interface{}{"title": "is required"}},
+		{"negative price", map[string]interface{}{"title": "Go", "price": -1}, map[string]interface{}{"price": "must be 0 or more"}},
+		{"wrong type", map[string]interface{}{"title": "Go", "price": "free"}, map[string]interface{}{"price": "must be a number"}},
+		{"unknown level", map[string]interface{}{"title": "Go", "level": "expert"},
+			map[string]interface{}{"level": "must be one of beginner, intermediate, advanced, all-levels"}},
+		{"status", map[string]interface{}{"title": "Go", "status": "published"}, map[string]interface{}{"status": workflow}},
+		{"isActive", map[string]interface{}{"title": "Go", "isActive": true}, map[string]interface{}{"isActive": workflow}},
+		{"server-managed field", map[string]interface{}{"title": "Go", "rating": 5}, map[string]interface{}{"rating": "is not an editable field"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := s.expect(http.StatusBadRequest, http.MethodPost, "/admin/courses", tt.body, bearer(token)...)
+			want := map[string]interface{}{"success": false, "error": "Invalid course data", "fields": tt.fields}
+			if !reflect.DeepEqual(body, want) {
+				t.Errorf("POST /admin/courses = %v, want %v", body, want)
+			}
+		})
+	}
+}
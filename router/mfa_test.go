@@ -0,0 +1,85 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// enableMFA - Turns on two-factor authentication for the student and
+// returns their recovery codes
+func (s *testServer) enableMFA(student *models.User) []string {
+	s.t.Helper()
+	token, _ := s.login("/login", student.Email)
+
+	setup := s.expect(http.StatusOK, http.MethodPost, "/user/2fa/setup", nil, bearer(token)...)
+	enrollment, _ := setup["enrollment"].(map[string]interface{})
+	secret, _ := enrollment["secret"].(string)
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.expect(http.StatusOK, http.MethodPost, "/user/2fa/enable", map[string]string{"code": code}, bearer(token)...)
+
+	var codes []string
+	for _, code := range enrollment["recoveryCodes"].([]interface{}) {
+		codes = append(codes, code.(string))
+	}
+	return codes
+}
+
+// startMFALogin - Passes the password step and returns the challenge token
+func (s *testServer) startMFALogin(email string) string {
+	s.t.Helper()
+	body := s.expect(http.StatusOK, http.MethodPost, "/login", map[string]string{"email": email, "password": testPassword})
+	challenge, _ := body["mfaToken"].(string)
+	if body["mfaRequired"] != true || challenge == "" {
+		s.t.Fatalf("POST /login = %v, want a two-factor challenge", body)
+	}
+	return challenge
+}
+
+func TestMFAChallengeIsSingleUse(t *testing.T) {
+	s := newTestServer(t)
+	student := s.addStudent("ada@example.com")
+	codes := s.enableMFA(student)
+
+	challenge := s.startMFALogin(student.Email)
+	body := s.expect(http.StatusOK, http.MethodPost, "/login/mfa", map[string]string{"mfaToken": challenge, "code": codes[0]})
+	token, _ := body["token"].(string)
+	s.expect(http.StatusOK, http.MethodGet, "/user/profile", nil, bearer(token)...)
+
+	// Even with another valid code the challenge can't open a second session
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/login/mfa", map[string]string{"mfaToken": challenge, "code": codes[1]})
+	s.expect(http.StatusOK, http.MethodPost, "/login/mfa", map[string]string{"mfaToken": s.startMFALogin(student.Email), "code": codes[1]})
+}
+
+func TestMFAChallengeRechecksAccount(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func() bson.M // applied after the password step
+	}{
+		{"deletion requested", func() bson.M { return bson.M{"deletion_requested_at": time.Now()} }},
+		{"password changed", func() bson.M { return bson.M{"password_changed_at": time.Now().Add(time.Second)} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			student := s.addStudent("ada@example.com")
+			codes := s.enableMFA(student)
+
+			challenge := s.startMFALogin(student.Email)
+			if err := s.store.Users.Update(context.Background(), student.ID, tt.change()); err != nil {
+				t.Fatal(err)
+			}
+			s.expect(http.StatusUnauthorized, http.MethodPost, "/login/mfa", map[string]string{"mfaToken": challenge, "code": codes[0]})
+		})
+	}
+}
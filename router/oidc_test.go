@@ -0,0 +1,174 @@
+package router_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/oidc/oidctest"
+)
+
+var issuer *oidctest.Issuer
+
+const nonceCookie = "oidc_nonce"
+
+func (s *testServer) get(path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	s.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	return w
+}
+
+func responseCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// startOIDCLogin - Starts a login and lets the issuer approve it. Returns the
+// callback path the browser is sent back to and the nonce cookie it holds.
+func (s *testServer) startOIDCLogin() (string, *http.Cookie) {
+	s.t.Helper()
+	w := s.get("/auth/oidc/mock/start")
+	if w.Code != http.StatusFound {
+		s.t.Fatalf("GET /auth/oidc/mock/start = %d %s, want 302", w.Code, w.Body.String())
+	}
+
+	cookie := responseCookie(w, nonceCookie)
+	if cookie == nil || cookie.Value == "" {
+		s.t.Fatal("GET /auth/oidc/mock/start set no nonce cookie")
+	}
+
+	callback, err := issuer.Authorize(w.Header().Get("Location"))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return callback.RequestURI(), cookie
+}
+
+func TestOIDCLogin(t *testing.T) {
+	s := newTestServer(t)
+	issuer.SignIn(oidctest.Identity{Subject: "sub-1", Email: "ada@example.com", EmailVerified: true, Name: "Ada"})
+
+	start := s.get("/auth/oidc/mock/start")
+	authURL, err := url.Parse(start.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(authURL.String(), issuer.URL+"/authorize?") {
+		t.Fatalf("GET /auth/oidc/mock/start redirected to %q, want the issuer", authURL)
+	}
+	if authURL.Query().Get("code_challenge_method") != "S256" || authURL.Query().Get("nonce") == "" {
+		t.Fatalf("authorization request %q has no PKCE challenge or nonce", authURL)
+	}
+
+	cookie := responseCookie(start, nonceCookie)
+	if cookie == nil || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/auth/oidc/mock" || cookie.MaxAge <= 0 {
+		t.Fatalf("nonce cookie = %+v, want an httpOnly, SameSite=Lax cookie for /auth/oidc/mock", cookie)
+	}
+	if cookie.Value == authURL.Query().Get("state") || cookie.Value == authURL.Query().Get("nonce") {
+		t.Fatal("the nonce cookie must not be sent to the provider")
+	}
+
+	callback, err := issuer.Authorize(authURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.get(callback.RequestURI(), cookie)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d %s, want 200", callback.Path, w.Code, w.Body.String())
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	token, _ := body["token"].(string)
+	s.expect(http.StatusOK, http.MethodGet, "/user/profile", nil, bearer(token)...)
+
+	if cleared := responseCookie(w, nonceCookie); cleared == nil || cleared.MaxAge >= 0 {
+		t.Errorf("callback nonce cookie = %+v, want it deleted", cleared)
+	}
+	if _, err := s.store.Users.FindByIdentity(context.Background(), "mock", "sub-1"); err != nil {
+		t.Errorf("student linked to the identity: %v", err)
+	}
+}
+
+func TestOIDCStartJSON(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.get("/auth/oidc/mock/start?format=json")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "authorizationUrl") {
+		t.Fatalf("GET /auth/oidc/mock/start?format=json = %d %s, want the URL", w.Code, w.Body.String())
+	}
+	if responseCookie(w, nonceCookie) == nil {
+		t.Fatal("GET /auth/oidc/mock/start?format=json set no nonce cookie")
+	}
+}
+
+func TestOIDCCallbackRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback func(s *testServer) (string, []*http.Cookie)
+	}{
+		{"no nonce cookie", func(s *testServer) (string, []*http.Cookie) {
+			callback, _ := s.startOIDCLogin()
+			return callback, nil
+		}},
+		{"wrong nonce cookie", func(s *testServer) (string, []*http.Cookie) {
+			callback, cookie := s.startOIDCLogin()
+			cookie.Value += "x"
+			return callback, []*http.Cookie{cookie}
+		}},
+		{"nonce cookie of another login", func(s *testServer) (string, []*http.Cookie) {
+			// An attacker's callback opened in a victim's browser
+			callback, _ := s.startOIDCLogin()
+			_, victimCookie := s.startOIDCLogin()
+			return callback, []*http.Cookie{victimCookie}
+		}},
+		{"replayed state", func(s *testServer) (string, []*http.Cookie) {
+			callback, cookie := s.startOIDCLogin()
+			if w := s.get(callback, cookie); w.Code != http.StatusOK {
+				s.t.Fatalf("first callback = %d %s, want 200", w.Code, w.Body.String())
+			}
+			return callback, []*http.Cookie{cookie}
+		}},
+		{"unknown state", func(s *testServer) (string, []*http.Cookie) {
+			callback, cookie := s.startOIDCLogin()
+			parsed, _ := url.Parse(callback)
+			query := parsed.Query()
+			query.Set("state", "forged")
+			parsed.RawQuery = query.Encode()
+			return parsed.RequestURI(), []*http.Cookie{cookie}
+		}},
+		{"missing code", func(s *testServer) (string, []*http.Cookie) {
+			callback, cookie := s.startOIDCLogin()
+			parsed, _ := url.Parse(callback)
+			query := parsed.Query()
+			query.Del("code")
+			parsed.RawQuery = query.Encode()
+			return parsed.RequestURI(), []*http.Cookie{cookie}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			issuer.SignIn(oidctest.Identity{Subject: "sub-2", Email: "grace@example.com", EmailVerified: true})
+
+			callback, cookies := tt.callback(s)
+			w := s.get(callback, cookies...)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("GET %s = %d %s, want 400", callback, w.Code, w.Body.String())
+			}
+		})
+	}
+}
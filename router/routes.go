@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/AbaraEmmanuel/jaromind-backend/controllers"
 	"github.com/AbaraEmmanuel/jaromind-backend/middleware"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
 )
 
 func RegisterRoutes(router *gin.Engine) {
@@ -40,6 +41,9 @@ func RegisterRoutes(router *gin.Engine) {
 		})
 	})
 
+	// Token verification keys for other services
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	// ======================
 	// PUBLIC ROUTES
 	// ======================
@@ -47,10 +51,32 @@ func RegisterRoutes(router *gin.Engine) {
 	// Auth routes
 	router.POST("/register", controllers.RegisterUser)
 	router.POST("/login", controllers.LoginUser)
+	router.POST("/login/mfa", controllers.CompleteMFALogin)
+	router.POST("/login/mfa/enroll", controllers.EnrollMFAWithChallenge)
+	router.POST("/verify-email", controllers.VerifyEmail)
+	router.POST("/verify-email/resend", controllers.ResendVerification)
+	router.POST("/forgot-password", controllers.ForgotPassword)
+	router.POST("/reset-password", controllers.ResetPassword)
 	router.POST("/admin/login", controllers.AdminLogin) // ✅ Admin login
+	router.POST("/admin/forgot-password", controllers.AdminForgotPassword)
+	router.POST("/admin/reset-password", controllers.AdminResetPassword)
+	router.POST("/tutor/login", controllers.TutorLogin)
+	router.POST("/tutor/forgot-password", controllers.TutorForgotPassword)
+	router.POST("/tutor/reset-password", controllers.TutorResetPassword)
+
+	// Social login (OpenID Connect, configured with OIDC_PROVIDERS)
+	router.GET("/auth/oidc/providers", controllers.ListOIDCProviders)
+	router.GET("/auth/oidc/:provider/start", controllers.StartOIDCLogin)
+	router.GET("/auth/oidc/:provider/callback", controllers.OIDCCallback)
+
+	// Token lifecycle
+	router.POST("/token/refresh", controllers.RefreshToken)
+	router.POST("/logout", middleware.JWTAuthMiddleware(), controllers.Logout)
 	
 	// Public course routes
 	router.GET("/courses", controllers.GetAllCourses)
+	router.GET("/courses/search", controllers.SearchCourses)
+	router.GET("/courses/suggest", controllers.SuggestCourses)
 	router.GET("/courses/:id", controllers.GetCourseByID)
 	router.GET("/courses/:id/stats", controllers.GetCourseStats)
 	router.GET("/courses/:id/reviews", controllers.GetCourseReviews)
@@ -59,10 +85,29 @@ func RegisterRoutes(router *gin.Engine) {
 	// ======================
 	// PROTECTED USER ROUTES
 	// ======================
+	// Admins impersonating a student can use these, except DELETEs and the
+	// routes marked RequireInteractive; every such request is audited
 	userProtected := router.Group("/user")
 	userProtected.Use(middleware.JWTAuthMiddleware())
 	{
 		userProtected.GET("/profile", controllers.GetProfile)
+		userProtected.PATCH("/profile", controllers.UpdateProfile)
+		userProtected.PUT("/password", middleware.RequireInteractive(), controllers.ChangePassword)
+
+		// Data export and account deletion
+		userProtected.GET("/export", middleware.RequireInteractive(), controllers.ExportAccountData)
+		userProtected.DELETE("/account", middleware.RequireInteractive(), controllers.DeleteAccount)
+
+		// Signed-in devices
+		userProtected.GET("/sessions", controllers.ListSessions)
+		userProtected.DELETE("/sessions/:id", middleware.RequireInteractive(), controllers.RevokeSession)
+
+		// Two-factor authentication (optional for students)
+		userProtected.GET("/2fa", controllers.GetMFAStatus)
+		userProtected.POST("/2fa/setup", middleware.RequireInteractive(), controllers.SetupMFA)
+		userProtected.POST("/2fa/enable", middleware.RequireInteractive(), controllers.EnableMFA)
+		userProtected.POST("/2fa/disable", middleware.RequireInteractive(), controllers.DisableMFA)
+
 		userProtected.POST("/enroll/:id", controllers.EnrollInCourse)
 		userProtected.GET("/enrollments", controllers.GetUserEnrollments)
 		userProtected.PUT("/courses/:id/progress", controllers.UpdateProgress)
@@ -81,27 +126,91 @@ func RegisterRoutes(router *gin.Engine) {
         reviewProtected.DELETE("/:reviewId", controllers.DeleteReview)
     }
 
+	// ======================
+	// TUTOR ROUTES
+	// ======================
+	tutorProtected := router.Group("/tutor")
+	tutorProtected.Use(middleware.JWTAuthMiddleware(), middleware.RequirePermission(models.PermCourseAuthor))
+	{
+		tutorProtected.GET("/profile", controllers.GetTutorProfile)
+		tutorProtected.PATCH("/profile", controllers.UpdateTutorProfile)
+
+		// Course authoring - tutors write drafts and submit them for review
+		tutorProtected.GET("/courses", controllers.ListTutorCourses)
+		tutorProtected.POST("/courses", controllers.CreateTutorCourse)
+		tutorProtected.PUT("/courses/:id", controllers.UpdateTutorCourse)
+		tutorProtected.POST("/courses/:id/submit", controllers.SubmitTutorCourse)
+		tutorProtected.POST("/courses/:id/withdraw", controllers.WithdrawTutorCourse)
+		tutorProtected.GET("/courses/:id/comments", controllers.ListCourseComments)
+		tutorProtected.POST("/courses/:id/comments", controllers.AddCourseComment)
+
+		// Signed-in devices
+		tutorProtected.GET("/sessions", controllers.ListSessions)
+		tutorProtected.DELETE("/sessions/:id", middleware.RequireInteractive(), controllers.RevokeSession)
+
+		// Two-factor authentication
+		tutorProtected.GET("/2fa", controllers.GetMFAStatus)
+		tutorProtected.POST("/2fa/setup", middleware.RequireInteractive(), controllers.SetupMFA)
+		tutorProtected.POST("/2fa/enable", middleware.RequireInteractive(), controllers.EnableMFA)
+		tutorProtected.POST("/2fa/disable", middleware.RequireInteractive(), controllers.DisableMFA)
+	}
+
 	// ======================
 	// ADMIN ROUTES
 	// ======================
 	adminProtected := router.Group("/admin")
-	adminProtected.Use(middleware.JWTAuthMiddleware())
-	// adminProtected.Use(middleware.AdminAuthMiddleware()) // ⚠️ Comment this out for now
+	adminProtected.Use(middleware.AdminAuthMiddleware())
 	{
-		// Course management (these should exist in course_controller.go)
-		adminProtected.POST("/courses", controllers.CreateCourse)
-		adminProtected.PUT("/courses/:id", controllers.UpdateCourse)
-		adminProtected.DELETE("/courses/:id", controllers.DeleteCourse)
-		
-		// ❌ REMOVE OR COMMENT THESE LINES - they don't exist yet
-		// adminProtected.GET("/dashboard", controllers.GetAdminDashboard)
-		// adminProtected.GET("/users", controllers.GetAllUsers)
-		
-		// ✅ OR keep them commented until you create the functions
-		/*
-		adminProtected.GET("/dashboard", controllers.GetAdminDashboard)
-		adminProtected.GET("/users", controllers.GetAllUsers)
-		*/
+		// Two-factor authentication for the signed-in admin (not with API keys)
+		adminProtected.GET("/2fa", middleware.RequireInteractive(), controllers.GetMFAStatus)
+		adminProtected.POST("/2fa/setup", middleware.RequireInteractive(), controllers.SetupMFA)
+		adminProtected.POST("/2fa/enable", middleware.RequireInteractive(), controllers.EnableMFA)
+		adminProtected.POST("/2fa/disable", middleware.RequireInteractive(), controllers.DisableMFA)
+
+		// Signed-in devices of the admin (not with API keys)
+		adminProtected.GET("/sessions", middleware.RequireInteractive(), controllers.ListSessions)
+		adminProtected.DELETE("/sessions/:id", middleware.RequireInteractive(), controllers.RevokeSession)
+
+		// API keys for integrations - keys act as their owner, limited to their scopes
+		adminProtected.GET("/api-keys", middleware.RequireInteractive(), controllers.ListAPIKeys)
+		adminProtected.POST("/api-keys", middleware.RequireInteractive(), controllers.CreateAPIKey)
+		adminProtected.DELETE("/api-keys/:id", middleware.RequireInteractive(), controllers.RevokeAPIKey)
+
+		// Course management - each route declares the permission it needs
+		adminProtected.GET("/courses", middleware.RequirePermission(models.PermCourseUpdate), controllers.ListAdminCourses)
+		adminProtected.POST("/courses", middleware.RequirePermission(models.PermCourseCreate), controllers.CreateCourse)
+		adminProtected.PUT("/courses/:id", middleware.RequirePermission(models.PermCourseUpdate), controllers.UpdateCourse)
+		adminProtected.DELETE("/courses/:id", middleware.RequirePermission(models.PermCourseDelete), controllers.DeleteCourse)
+		adminProtected.POST("/courses/:id/publish", middleware.RequirePermission(models.PermCoursePublish), controllers.PublishCourse)
+
+		// Review workflow: draft -> in_review -> published -> archived, with reviewer comments
+		adminProtected.POST("/courses/:id/status", middleware.RequirePermission(models.PermCoursePublish), controllers.ChangeCourseStatus)
+		adminProtected.PUT("/courses/:id/schedule", middleware.RequirePermission(models.PermCoursePublish), controllers.ScheduleCourse)
+		adminProtected.GET("/courses/:id/comments", middleware.RequirePermission(models.PermCoursePublish), controllers.ListCourseComments)
+		adminProtected.POST("/courses/:id/comments", middleware.RequirePermission(models.PermCoursePublish), controllers.AddCourseComment)
+
+		// Change history - every course change is kept as a revision that can be restored
+		adminProtected.GET("/courses/:id/revisions", middleware.RequirePermission(models.PermCourseUpdate), controllers.ListCourseRevisions)
+		adminProtected.POST("/courses/:id/revisions/:number/rollback", middleware.RequirePermission(models.PermCourseUpdate), controllers.RollbackCourse)
+
+		// Tutor accounts
+		adminProtected.GET("/tutors", middleware.RequirePermission(models.PermTutorManage), controllers.ListTutors)
+		adminProtected.POST("/tutors", middleware.RequirePermission(models.PermTutorManage), controllers.InviteTutor)
+		adminProtected.PATCH("/tutors/:id", middleware.RequirePermission(models.PermTutorManage), controllers.UpdateTutor)
+
+		// Admin account management (super-admins)
+		adminProtected.GET("/admins", middleware.RequirePermission(models.PermAdminManage), controllers.ListAdmins)
+		adminProtected.POST("/admins", middleware.RequirePermission(models.PermAdminManage), controllers.InviteAdmin)
+		adminProtected.PATCH("/admins/:id", middleware.RequirePermission(models.PermAdminManage), controllers.UpdateAdmin)
+		adminProtected.POST("/admins/:id/reset-password", middleware.RequirePermission(models.PermAdminManage), controllers.ResetAdminPassword)
+
+		// Login lockouts (student and admin accounts, client IPs)
+		adminProtected.GET("/lockouts", middleware.RequirePermission(models.PermUserManage), controllers.ListLockouts)
+		adminProtected.DELETE("/lockouts/:id", middleware.RequirePermission(models.PermUserManage), controllers.ClearLockout)
+
+		// Support impersonation (short-lived student tokens) and its audit trail
+		adminProtected.POST("/impersonate/:userId", middleware.RequireInteractive(), middleware.RequirePermission(models.PermUserImpersonate), controllers.ImpersonateUser)
+		adminProtected.GET("/audit-logs", middleware.RequirePermission(models.PermAuditView), controllers.ListAuditLogs)
 	}
 
 	// ======================
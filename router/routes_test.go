@@ -0,0 +1,363 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/oidc/oidctest"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/router"
+	"github.com/gin-gonic/gin"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct-horse"
+
+func TestMain(m *testing.M) {
+	// An empty key directory with the development opt-in makes the token
+	// service use a throwaway key
+	keysDir, err := os.MkdirTemp("", "jwt-keys")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("JWT_KEYS_DIR", keysDir)
+	os.Setenv("JWT_EPHEMERAL_KEY", "true")
+	os.Setenv("MAILER_DRIVER", "log")
+	gin.SetMode(gin.TestMode)
+
+	// Providers are read from the environment once, so the mock issuer
+	// runs for the whole package
+	issuer = oidctest.NewIssuer("jaromind")
+	os.Setenv("OIDC_PROVIDERS", "mock")
+	os.Setenv("OIDC_MOCK_ISSUER", issuer.URL)
+	os.Setenv("OIDC_MOCK_CLIENT_ID", issuer.ClientID)
+	os.Setenv("OIDC_MOCK_REDIRECT_URL", "http://api.test/auth/oidc/mock/callback")
+
+	code := m.Run()
+	issuer.Close()
+	os.RemoveAll(keysDir)
+	os.Exit(code)
+}
+
+// testServer - The real routes on an empty in-memory store
+type testServer struct {
+	t      *testing.T
+	engine *gin.Engine
+	store  *repository.Store
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	repository.Use(store)
+
+	engine := gin.New()
+	router.RegisterRoutes(engine)
+	return &testServer{t: t, engine: engine, store: store}
+}
+
+func passwordHash(t *testing.T) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func (s *testServer) addStudent(email string) *models.User {
+	s.t.Helper()
+	now := time.Now()
+	user := &models.User{
+		ID:        primitive.NewObjectID(),
+		Name:      "Student",
+		Email:     email,
+		Password:  passwordHash(s.t),
+		Verified:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Users.Insert(context.Background(), user); err != nil {
+		s.t.Fatal(err)
+	}
+	return user
+}
+
+func (s *testServer) addAdmin(email, role string) *models.Admin {
+	s.t.Helper()
+	admin := &models.Admin{
+		ID:        primitive.NewObjectID(),
+		Name:      "Admin",
+		Email:     email,
+		Password:  passwordHash(s.t),
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+		IsActive:  true,
+		Role:      role,
+	}
+	if err := s.store.Admins.Insert(context.Background(), admin); err != nil {
+		s.t.Fatal(err)
+	}
+	return admin
+}
+
+// do sends a JSON request; headers are name/value pairs
+func (s *testServer) do(method, path string, body interface{}, headers ...string) (int, map[string]interface{}) {
+	s.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+
+	response := map[string]interface{}{}
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			s.t.Fatalf("%s %s: invalid JSON %q", method, path, w.Body.String())
+		}
+	}
+	return w.Code, response
+}
+
+func bearer(token string) []string {
+	return []string{"Authorization", "Bearer " + token}
+}
+
+// login signs in and returns the access and refresh tokens
+func (s *testServer) login(path, email string) (string, string) {
+	s.t.Helper()
+	status, body := s.do(http.MethodPost, path, map[string]string{"email": email, "password": testPassword})
+	if status != http.StatusOK {
+		s.t.Fatalf("POST %s = %d %v, want 200", path, status, body)
+	}
+	token, _ := body["token"].(string)
+	refresh, _ := body["refreshToken"].(string)
+	if token == "" || refresh == "" {
+		s.t.Fatalf("POST %s returned no tokens: %v", path, body)
+	}
+	return token, refresh
+}
+
+func (s *testServer) expect(want int, method, path string, body interface{}, headers ...string) map[string]interface{} {
+	s.t.Helper()
+	status, response := s.do(method, path, body, headers...)
+	if status != want {
+		s.t.Fatalf("%s %s = %d %v, want %d", method, path, status, response, want)
+	}
+	return response
+}
+
+func TestStudentLoginAndLogout(t *testing.T) {
+	s := newTestServer(t)
+	s.addStudent("ada@example.com")
+
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/login", map[string]string{"email": "ada@example.com", "password": "wrong-password"})
+
+	token, _ := s.login("/login", "ada@example.com")
+	profile := s.expect(http.StatusOK, http.MethodGet, "/user/profile", nil, bearer(token)...)
+	if user, _ := profile["user"].(map[string]interface{}); user == nil || user["email"] != "ada@example.com" {
+		t.Fatalf("GET /user/profile = %v, want ada@example.com", profile)
+	}
+
+	s.expect(http.StatusOK, http.MethodPost, "/logout", nil, bearer(token)...)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/user/profile", nil, bearer(token)...)
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := newTestServer(t)
+	s.addStudent("ada@example.com")
+
+	token, refresh := s.login("/login", "ada@example.com")
+	rotated := s.expect(http.StatusOK, http.MethodPost, "/token/refresh", map[string]string{"refreshToken": refresh})
+	newToken, _ := rotated["token"].(string)
+	s.expect(http.StatusOK, http.MethodGet, "/user/profile", nil, bearer(newToken)...)
+
+	// Presenting the rotated-out token again ends the whole session
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/token/refresh", map[string]string{"refreshToken": refresh})
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/user/profile", nil, bearer(newToken)...)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/user/profile", nil, bearer(token)...)
+}
+
+func TestPasswordChangeRejectsOlderTokens(t *testing.T) {
+	s := newTestServer(t)
+	s.addStudent("ada@example.com")
+
+	token, _ := s.login("/login", "ada@example.com")
+	// Tokens carry second precision, so move past the second they were issued in
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	s.expect(http.StatusOK, http.MethodPut, "/user/password", map[string]string{
+		"current_password": testPassword,
+		"new_password":     "new-password",
+	}, bearer(token)...)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/user/profile", nil, bearer(token)...)
+
+	s.expect(http.StatusOK, http.MethodPost, "/login", map[string]string{"email": "ada@example.com", "password": "new-password"})
+}
+
+func TestLoginLockout(t *testing.T) {
+	t.Setenv("LOGIN_MAX_FAILURES", "3")
+	s := newTestServer(t)
+	s.addStudent("ada@example.com")
+
+	wrong := map[string]string{"email": "ada@example.com", "password": "wrong-password"}
+	for i := 0; i < 3; i++ {
+		s.expect(http.StatusUnauthorized, http.MethodPost, "/login", wrong)
+	}
+
+	// Locked, even with the right password
+	status, body := s.do(http.MethodPost, "/login", map[string]string{"email": "ada@example.com", "password": testPassword})
+	if status != http.StatusTooManyRequests {
+		t.Fatalf("POST /login after lockout = %d %v, want 429", status, body)
+	}
+
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+	lockouts := s.expect(http.StatusOK, http.MethodGet, "/admin/lockouts", nil, bearer(token)...)
+	list, _ := lockouts["lockouts"].([]interface{})
+	if len(list) != 1 {
+		t.Fatalf("GET /admin/lockouts = %v, want one lockout", lockouts)
+	}
+
+	id, _ := list[0].(map[string]interface{})["id"].(string)
+	s.expect(http.StatusOK, http.MethodDelete, "/admin/lockouts/"+id, nil, bearer(token)...)
+	s.login("/login", "ada@example.com")
+}
+
+func TestAdminManagement(t *testing.T) {
+	s := newTestServer(t)
+	root := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	other := s.addAdmin("other@example.com", models.RoleSuperAdmin)
+	editor := s.addAdmin("editor@example.com", models.RoleContentAdmin)
+
+	token, _ := s.login("/admin/login", root.Email)
+	otherToken, _ := s.login("/admin/login", other.Email)
+	editorToken, _ := s.login("/admin/login", editor.Email)
+
+	list := s.expect(http.StatusOK, http.MethodGet, "/admin/admins", nil, bearer(token)...)
+	if list["count"] != float64(3) {
+		t.Fatalf("GET /admin/admins = %v, want 3 admins", list)
+	}
+	s.expect(http.StatusForbidden, http.MethodGet, "/admin/admins", nil, bearer(editorToken)...)
+
+	s.expect(http.StatusConflict, http.MethodPatch, "/admin/admins/"+root.ID.Hex(), map[string]interface{}{"isActive": false}, bearer(token)...)
+
+	// A deactivated admin's tokens stop working right away
+	s.expect(http.StatusOK, http.MethodPatch, "/admin/admins/"+other.ID.Hex(), map[string]interface{}{"isActive": false}, bearer(token)...)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/admin/admins", nil, bearer(otherToken)...)
+}
+
+func TestDeactivatedAdminLogin(t *testing.T) {
+	t.Setenv("LOGIN_MAX_FAILURES", "2")
+	s := newTestServer(t)
+	root := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	other := s.addAdmin("other@example.com", models.RoleSuperAdmin)
+
+	token, _ := s.login("/admin/login", root.Email)
+	s.expect(http.StatusOK, http.MethodPatch, "/admin/admins/"+other.ID.Hex(), map[string]interface{}{"isActive": false}, bearer(token)...)
+
+	// The right password gets the same answer as a wrong one, and counts as a failure
+	wrong := s.expect(http.StatusUnauthorized, http.MethodPost, "/admin/login", map[string]string{"email": other.Email, "password": "wrong-password"})
+	right := s.expect(http.StatusUnauthorized, http.MethodPost, "/admin/login", map[string]string{"email": other.Email, "password": testPassword})
+	if right["error"] != wrong["error"] {
+		t.Errorf("deactivated admin login = %v, want the wrong-password response %v", right, wrong)
+	}
+	s.expect(http.StatusTooManyRequests, http.MethodPost, "/admin/login", map[string]string{"email": other.Email, "password": testPassword})
+}
+
+func TestTutorInvite(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("editor@example.com", models.RoleContentAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	invite := map[string]string{"email": "grace@example.com", "name": "Grace"}
+	s.expect(http.StatusCreated, http.MethodPost, "/admin/tutors", invite, bearer(token)...)
+	s.expect(http.StatusConflict, http.MethodPost, "/admin/tutors", invite, bearer(token)...)
+
+	list := s.expect(http.StatusOK, http.MethodGet, "/admin/tutors", nil, bearer(token)...)
+	tutors, _ := list["tutors"].([]interface{})
+	if len(tutors) != 1 {
+		t.Fatalf("GET /admin/tutors = %v, want one tutor", list)
+	}
+
+	// Invited tutors have no password until they accept
+	s.expect(http.StatusUnauthorized, http.MethodPost, "/tutor/login", map[string]string{"email": "grace@example.com", "password": testPassword})
+}
+
+func TestAPIKeys(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	created := s.expect(http.StatusCreated, http.MethodPost, "/admin/api-keys", map[string]interface{}{
+		"name":   "reporting",
+		"scopes": []models.Permission{models.PermTutorManage},
+	}, bearer(token)...)
+	key, _ := created["key"].(string)
+	apiKey, _ := created["apiKey"].(map[string]interface{})
+	if key == "" || apiKey == nil {
+		t.Fatalf("POST /admin/api-keys = %v, want a key", created)
+	}
+
+	s.expect(http.StatusOK, http.MethodGet, "/admin/tutors", nil, "X-API-Key", key)
+	s.expect(http.StatusForbidden, http.MethodGet, "/admin/audit-logs", nil, "X-API-Key", key)
+	// Keys can't manage keys
+	s.expect(http.StatusForbidden, http.MethodGet, "/admin/api-keys", nil, "X-API-Key", key)
+
+	s.expect(http.StatusOK, http.MethodDelete, "/admin/api-keys/"+apiKey["id"].(string), nil, bearer(token)...)
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/admin/tutors", nil, "X-API-Key", key)
+}
+
+func TestImpersonationIsAudited(t *testing.T) {
+	s := newTestServer(t)
+	student := s.addStudent("ada@example.com")
+	admin := s.addAdmin("root@example.com", models.RoleSuperAdmin)
+	token, _ := s.login("/admin/login", admin.Email)
+
+	started := s.expect(http.StatusOK, http.MethodPost, "/admin/impersonate/"+student.ID.Hex(),
+		map[string]string{"reason": "Ticket 42"}, bearer(token)...)
+	impersonation, _ := started["impersonation"].(map[string]interface{})
+	studentToken, _ := impersonation["token"].(string)
+
+	s.expect(http.StatusOK, http.MethodGet, "/user/profile", nil, bearer(studentToken)...)
+	s.expect(http.StatusForbidden, http.MethodDelete, "/user/account", nil, bearer(studentToken)...)
+
+	logs := s.expect(http.StatusOK, http.MethodGet, "/admin/audit-logs?subjectId="+student.ID.Hex(), nil, bearer(token)...)
+	if logs["count"] != float64(2) {
+		t.Fatalf("GET /admin/audit-logs = %v, want the start and the profile request", logs)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	s := newTestServer(t)
+	s.addStudent("ada@example.com")
+	token, _ := s.login("/login", "ada@example.com")
+
+	s.expect(http.StatusUnauthorized, http.MethodDelete, "/user/account", map[string]string{"password": "wrong-password"}, bearer(token)...)
+	s.expect(http.StatusOK, http.MethodDelete, "/user/account", map[string]string{"password": testPassword}, bearer(token)...)
+
+	s.expect(http.StatusUnauthorized, http.MethodGet, "/user/profile", nil, bearer(token)...)
+	s.expect(http.StatusForbidden, http.MethodPost, "/login", map[string]string{"email": "ada@example.com", "password": testPassword})
+}
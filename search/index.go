@@ -0,0 +1,351 @@
+// Package search ranks documents against free-text queries in memory.
+// Fields are weighted, query words tolerate typos, the last query word also
+// matches as a prefix (search as you type), and hits come with highlighted
+// snippets of the text they matched.
+//
+// An Index never changes once built; build a new one when the documents do.
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	prefixFactor  = 0.7 // Score of a prefix match relative to the whole word
+	typoFactor    = 0.5 // Score kept per typo
+	maxQueryTerms = 10
+	snippetLength = 160
+)
+
+// Field - A named part of a document and how much a match in it counts
+type Field struct {
+	Name   string
+	Weight float64
+}
+
+// Document - Text to index, by field name. List fields (tags, ...) have
+// several values.
+type Document struct {
+	ID     string
+	Fields map[string][]string
+}
+
+// Hit - A document that matched a query
+type Hit struct {
+	ID    string
+	Score float64
+
+	doc     int
+	matched map[string]bool // Indexed words the query matched in this document
+}
+
+// Index - Documents prepared for searching
+type Index struct {
+	fields   []Field
+	docs     []Document
+	postings map[string][]posting // Where each word occurs
+	docFreq  map[string]int       // How many documents contain each word
+	terms    []string             // Every word, sorted
+}
+
+type posting struct {
+	doc, field, count int
+}
+
+// NewIndex - Indexes docs. Their order breaks ties between equal scores.
+func NewIndex(fields []Field, docs []Document) *Index {
+	ix := &Index{
+		fields:   fields,
+		docs:     docs,
+		postings: map[string][]posting{},
+		docFreq:  map[string]int{},
+	}
+
+	for d, doc := range docs {
+		seen := map[string]bool{}
+		for f, field := range fields {
+			counts := map[string]int{}
+			for _, value := range doc.Fields[field.Name] {
+				for _, term := range terms(value) {
+					counts[term]++
+				}
+			}
+			for term, count := range counts {
+				ix.postings[term] = append(ix.postings[term], posting{doc: d, field: f, count: count})
+				if !seen[term] {
+					seen[term] = true
+					ix.docFreq[term]++
+				}
+			}
+		}
+	}
+
+	ix.terms = make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		ix.terms = append(ix.terms, term)
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+// Len - Number of indexed documents
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Search - Every document matching at least one query word, best first.
+// Within a document each query word scores by the fields it occurs in,
+// weighted by how rare it is; documents matching only some of the words
+// are ranked down.
+func (ix *Index) Search(query string) []Hit {
+	words := queryTerms(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	scores := map[int]float64{}
+	wordsMatched := map[int]int{}
+	matched := map[int]map[string]bool{}
+	total := float64(len(ix.docs))
+
+	for i, word := range words {
+		best := map[int]float64{}
+		for term, factor := range ix.expand(word, i == len(words)-1) {
+			idf := math.Log(1 + total/float64(ix.docFreq[term]))
+
+			perDoc := map[int]float64{}
+			for _, p := range ix.postings[term] {
+				perDoc[p.doc] += ix.fields[p.field].Weight * (1 + math.Log(float64(p.count)))
+			}
+			for doc, score := range perDoc {
+				// Several spellings of one query word don't add up; the best counts
+				best[doc] = math.Max(best[doc], score*factor*idf)
+				if matched[doc] == nil {
+					matched[doc] = map[string]bool{}
+				}
+				matched[doc][term] = true
+			}
+		}
+		for doc, score := range best {
+			scores[doc] += score
+			wordsMatched[doc]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		coverage := float64(wordsMatched[doc]) / float64(len(words))
+		hits = append(hits, Hit{
+			ID:      ix.docs[doc].ID,
+			Score:   score * coverage * coverage,
+			doc:     doc,
+			matched: matched[doc],
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].doc < hits[j].doc
+	})
+	return hits
+}
+
+// expand - The indexed words a query word stands for, with how much each
+// counts: the word itself, words it is a prefix of (last query word only)
+// and words within a few typos of it
+func (ix *Index) expand(word string, last bool) map[string]float64 {
+	found := map[string]float64{}
+	if _, exists := ix.postings[word]; exists {
+		found[word] = 1
+	}
+
+	if last && len([]rune(word)) >= 2 {
+		for i := sort.SearchStrings(ix.terms, word); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], word); i++ {
+			if ix.terms[i] != word {
+				found[ix.terms[i]] = prefixFactor
+			}
+		}
+	}
+
+	if limit := maxEdits(word); limit > 0 {
+		for _, term := range ix.terms {
+			edits := editDistance(word, term, limit)
+			if edits == 0 || edits > limit {
+				continue
+			}
+			if factor := math.Pow(typoFactor, float64(edits)); factor > found[term] {
+				found[term] = factor
+			}
+		}
+	}
+	return found
+}
+
+// Highlights - For each field of the hit's document that matched, a
+// snippet around the first match with matches wrapped in <mark></mark>.
+// The text is HTML-escaped, so snippets can be inserted as markup.
+func (ix *Index) Highlights(hit Hit) map[string]string {
+	highlights := map[string]string{}
+	doc := ix.docs[hit.doc]
+	for _, field := range ix.fields {
+		for _, value := range doc.Fields[field.Name] {
+			if text, ok := snippet(value, hit.matched); ok {
+				highlights[field.Name] = text
+				break
+			}
+		}
+	}
+	return highlights
+}
+
+// snippet - About snippetLength bytes of text around the first matched word
+func snippet(text string, matched map[string]bool) (string, bool) {
+	tokens := tokenize(text)
+	first := -1
+	for i, t := range tokens {
+		if matched[t.term] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	// Start a little before the match, on a word boundary
+	start := 0
+	if len(text) > snippetLength {
+		from := tokens[first].start - snippetLength/3
+		for _, t := range tokens[:first+1] {
+			if t.start >= from {
+				start = t.start
+				break
+			}
+		}
+	}
+	end := len(text)
+	if end-start > snippetLength {
+		end = start + snippetLength
+		for i := len(tokens) - 1; i >= 0; i-- {
+			if tokens[i].end <= end && tokens[i].start >= start {
+				end = tokens[i].end
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !matched[t.term] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[t.start:t.end]))
+		b.WriteString("</mark>")
+		pos = t.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return strings.TrimSpace(b.String()), true
+}
+
+// PrefixMatches - IDs of documents whose field has, for every query word, a
+// word starting with it. Documents whose field starts with the query come
+// first, then the rest, each in index order.
+func (ix *Index) PrefixMatches(query, field string, limit int) []string {
+	words := queryTerms(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var leading, other []string
+	for _, doc := range ix.docs {
+		for _, value := range doc.Fields[field] {
+			valueTerms := terms(value)
+			if !coversPrefixes(valueTerms, words) {
+				continue
+			}
+			if strings.HasPrefix(valueTerms[0], words[0]) {
+				leading = append(leading, doc.ID)
+			} else {
+				other = append(other, doc.ID)
+			}
+			break
+		}
+		if len(leading) >= limit {
+			break
+		}
+	}
+
+	ids := append(leading, other...)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+func coversPrefixes(valueTerms, words []string) bool {
+	for _, word := range words {
+		found := false
+		for _, term := range valueTerms {
+			if strings.HasPrefix(term, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Complete - Indexed words starting with the last word of query, the most
+// common first
+func (ix *Index) Complete(query string, limit int) []string {
+	words := queryTerms(query)
+	if len(words) == 0 {
+		return nil
+	}
+	prefix := words[len(words)-1]
+
+	var found []string
+	for i := sort.SearchStrings(ix.terms, prefix); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], prefix); i++ {
+		found = append(found, ix.terms[i])
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return ix.docFreq[found[i]] > ix.docFreq[found[j]]
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found
+}
+
+// queryTerms - The distinct words of a query, at most maxQueryTerms
+func queryTerms(query string) []string {
+	var words []string
+	seen := map[string]bool{}
+	for _, term := range terms(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		words = append(words, term)
+		if len(words) == maxQueryTerms {
+			break
+		}
+	}
+	return words
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testFields = []Field{
+	{Name: "title", Weight: 3},
+	{Name: "description", Weight: 1},
+	{Name: "tags", Weight: 2},
+}
+
+func testIndex() *Index {
+	return NewIndex(testFields, []Document{
+		{ID: "go", Fields: map[string][]string{
+			"title":       {"Go Programming"},
+			"description": {"Learn Go from scratch"},
+			"tags":        {"golang", "backend"},
+		}},
+		{ID: "py", Fields: map[string][]string{
+			"title":       {"Python for Data Science"},
+			"description": {"Pandas and programming basics"},
+			"tags":        {"python", "data"},
+		}},
+		{ID: "ml", Fields: map[string][]string{
+			"title":       {"Machine Learning"},
+			"description": {"Models in Python"},
+			"tags":        {"ai"},
+		}},
+		{ID: "web", Fields: map[string][]string{
+			"title":       {"Web Development"},
+			"description": {"HTML, CSS & <script> tricks"},
+			"tags":        {"frontend"},
+		}},
+	})
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"empty query", "", []string{}},
+		{"no match", "rust", []string{}},
+		{"title outranks description", "python", []string{"py", "ml"}},
+		{"case insensitive", "PYTHON", []string{"py", "ml"}},
+		{"one typo", "pythn", []string{"py", "ml"}},
+		{"swapped letters", "pyhton", []string{"py", "ml"}},
+		{"no typos in short words", "aj", []string{}},
+		{"last word as prefix", "progr", []string{"go", "py"}},
+		{"only the last word is a prefix", "pyth machine", []string{"ml"}},
+		{"all words beat some words", "machine pyth", []string{"ml", "py"}},
+		{"short prefix", "go", []string{"go"}},
+		{"punctuation ignored", "web, development!", []string{"web"}},
+	}
+
+	ix := testIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitIDs(ix.Search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	tests := []struct {
+		query string
+		want  map[string]string
+	}{
+		{"python", map[string]string{
+			"title": "<mark>Python</mark> for Data Science",
+			"tags":  "<mark>python</mark>",
+		}},
+		{"progr", map[string]string{
+			"title": "Go <mark>Programming</mark>",
+		}},
+		{"script", map[string]string{
+			"description": "HTML, CSS &amp; &lt;<mark>script</mark>&gt; tricks",
+		}},
+	}
+
+	ix := testIndex()
+	for _, tt := range tests {
+		hits := ix.Search(tt.query)
+		if len(hits) == 0 {
+			t.Fatalf("Search(%q) found nothing", tt.query)
+		}
+		if got := ix.Highlights(hits[0]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Highlights(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSnippetLongText(t *testing.T) {
+	text := strings.Repeat("filler ", 40) + "needle" + strings.Repeat(" filler", 40)
+
+	got, ok := snippet(text, map[string]bool{"needle": true})
+	if !ok {
+		t.Fatal("snippet found no match")
+	}
+	if len(got) > snippetLength+2*len("…")+len("<mark></mark>") {
+		t.Errorf("snippet is %d bytes, want about %d", len(got), snippetLength)
+	}
+	if want := "<mark>needle</mark>"; !strings.Contains(got, want) || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("snippet = %q, want %s between ellipses", got, want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"", 5, nil},
+		{"pro", 5, []string{"programming"}},
+		{"python da", 5, []string{"data"}},
+		// Most common first, then alphabetical
+		{"p", 5, []string{"programming", "python", "pandas"}},
+		{"p", 2, []string{"programming", "python"}},
+		{"zzz", 5, nil},
+	}
+
+	ix := testIndex()
+	for _, tt := range tests {
+		if got := ix.Complete(tt.query, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixMatches(t *testing.T) {
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"", 5, nil},
+		{"web", 5, []string{"web"}},
+		{"pro", 5, []string{"go"}},
+		// Titles starting with the query come first
+		{"p", 5, []string{"py", "go"}},
+		{"p", 1, []string{"py"}},
+		{"data py", 5, []string{"py"}},
+		{"data go", 5, nil},
+	}
+
+	ix := testIndex()
+	for _, tt := range tests {
+		if got := ix.PrefixMatches(tt.query, "title", tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PrefixMatches(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"python", "python", 2, 0},
+		{"pythn", "python", 2, 1},
+		{"pyhton", "python", 2, 1},
+		{"pithon", "python", 2, 1},
+		{"pytho", "python", 2, 1},
+		{"pyton", "pythons", 2, 2},
+		{"java", "python", 2, 3},
+		{"ab", "abcdef", 2, 3},
+		{"café", "cafe", 1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token - A normalized word and where it sits in the original text
+type token struct {
+	term       string
+	start, end int // byte offsets
+}
+
+// tokenize splits text into lower-cased words of letters and digits
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		wordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case wordRune && start < 0:
+			start = i
+		case !wordRune && start >= 0:
+			tokens = append(tokens, token{term: normalize(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: normalize(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// terms - Just the words of text
+func terms(text string) []string {
+	tokens := tokenize(text)
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.term
+	}
+	return words
+}
+
+func normalize(word string) string {
+	return strings.ToLower(word)
+}
+
+// maxEdits - How many typos a query word may contain and still match: none
+// for short words, where one edit already makes a different word
+func maxEdits(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// editDistance - Optimal string alignment distance between a and b (an
+// adjacent swap counts as one edit), or limit+1 once it exceeds limit
+func editDistance(a, b string, limit int) int {
+	x, y := []rune(a), []rune(b)
+	if abs(len(x)-len(y)) > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(y)+1)
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+		best := curr[0]
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			best = min(best, curr[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return min(prev[len(y)], limit+1)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
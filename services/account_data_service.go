@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// AccountDataService handles data protection requests for student accounts
+type AccountDataService interface {
+	// Export collects everything stored about the student
+	Export(ctx context.Context, userID string) (*models.AccountExport, error)
+
+	// RequestDeletion anonymizes the student's reviews, removes their
+	// enrollments, signs them out everywhere and schedules the record for
+	// removal after the grace period. Returns when it will be removed.
+	RequestDeletion(ctx context.Context, userID, password string) (time.Time, error)
+
+	// PurgeDue hard-deletes accounts whose grace period has ended
+	PurgeDue(ctx context.Context) (int, error)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// AdminUpdate holds the optional fields of an admin that can be changed
+type AdminUpdate struct {
+	Name     *string
+	Role     *string
+	IsActive *bool
+}
+
+// AdminService manages accounts in the admins collection
+type AdminService interface {
+	// List returns every admin, newest first
+	List(ctx context.Context) ([]models.Admin, error)
+
+	// GetByID returns a single admin
+	GetByID(ctx context.Context, id string) (*models.Admin, error)
+
+	// Create adds an admin with a known password (used by the bootstrap CLI)
+	Create(ctx context.Context, email, name, password, role string) (*models.Admin, error)
+
+	// Invite adds an admin without a password and emails a set-password link
+	Invite(ctx context.Context, email, name, role, invitedBy string) (*models.Admin, error)
+
+	// Update changes name, role or isActive. Deactivating ends all sessions.
+	Update(ctx context.Context, id, actorID string, update AdminUpdate) (*models.Admin, error)
+
+	// SendPasswordReset emails the admin a password reset token
+	SendPasswordReset(ctx context.Context, id string) error
+
+	// SetPassword replaces the password directly (used by the CLI to rotate credentials)
+	SetPassword(ctx context.Context, email, password string) error
+}
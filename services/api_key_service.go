@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// APIKeyPrincipal is who a request authenticated with an API key acts as
+type APIKeyPrincipal struct {
+	Key   *models.APIKey
+	Email string
+	Role  string // the owner's current role
+}
+
+// APIKeyService manages admin API keys
+type APIKeyService interface {
+	// Create issues a key for the owner. Scopes must be ones the owner's role
+	// grants. The plain key is returned only here.
+	Create(ctx context.Context, ownerID, ownerRole, name string, scopes []models.Permission, expiresAt *time.Time) (*models.APIKey, string, error)
+
+	// List returns the owner's keys, or every key when ownerID is empty
+	List(ctx context.Context, ownerID string) ([]models.APIKey, error)
+
+	// Revoke disables a key. Unless ownerID is empty, only the owner's keys match.
+	Revoke(ctx context.Context, id, ownerID string) error
+
+	// Authenticate resolves a plain key; expired, revoked and deactivated-owner keys fail
+	Authenticate(ctx context.Context, rawKey, clientIP string) (*APIKeyPrincipal, error)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditFilter narrows an audit log listing; empty fields match everything
+type AuditFilter struct {
+	Action    string
+	ActorID   string
+	SubjectID string
+	Limit     int64
+}
+
+// AuditService writes and reads the audit_logs collection
+type AuditService interface {
+	// Record appends an entry, assigning its ID and stamping CreatedAt
+	Record(ctx context.Context, entry *models.AuditLog) error
+
+	// SetStatus fills in the response status of an entry recorded before the
+	// request was handled
+	SetStatus(ctx context.Context, id primitive.ObjectID, status int) error
+
+	// List returns matching entries, newest first
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditLog, error)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CourseActor - Who changes or comments on a course. Tutors may only act on
+// their own courses.
+type CourseActor struct {
+	ID    string
+	Email string
+	Role  string
+}
+
+// CourseSchedule - When a course is published or archived by the scheduler;
+// nil means not scheduled
+type CourseSchedule struct {
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
+}
+
+// CourseAuthoringService lets tutors write their own courses as drafts and
+// moves courses through review to publication (models.CourseTransitions)
+type CourseAuthoringService interface {
+	// ListByTutor returns every course linked to the tutor, drafts included
+	ListByTutor(ctx context.Context, tutorID string) ([]bson.M, error)
+
+	// CreateDraft stores a new draft owned by the tutor. Fields only admins
+	// may set are rejected with models.FieldErrors.
+	CreateDraft(ctx context.Context, tutorID string, input *models.CourseInput) (bson.M, error)
+
+	// UpdateDraft changes one of the tutor's courses while it is still a draft
+	UpdateDraft(ctx context.Context, tutorID, courseID string, input *models.CourseInput) error
+
+	// Transition moves a course to status and records the change, with the
+	// comment, for reviewers. Returns ErrInvalidTransition if the workflow
+	// doesn't allow it (or the course changed meanwhile), and
+	// ErrCommentRequired when a course in review goes back to draft without
+	// a comment. Tutors can only submit and withdraw their own courses.
+	Transition(ctx context.Context, actor CourseActor, courseID, status, comment string) error
+
+	// Schedule replaces the scheduled publish and unpublish times. Publishing
+	// can only be scheduled for courses in review, unpublishing for courses in
+	// review or published; invalid times are models.FieldErrors.
+	Schedule(ctx context.Context, actor CourseActor, courseID string, schedule CourseSchedule) error
+
+	// RunSchedule publishes and archives the courses whose scheduled time has
+	// come, returning how many of each it changed
+	RunSchedule(ctx context.Context, now time.Time) (int, int, error)
+
+	// AddComment adds a reviewer or tutor note to a course
+	AddComment(ctx context.Context, actor CourseActor, courseID, comment string) (*models.CourseComment, error)
+
+	// ListComments returns the notes and status changes of a course, newest first
+	ListComments(ctx context.Context, actor CourseActor, courseID string) ([]models.CourseComment, error)
+
+	// LinkTutor fills the embedded tutor display fields from the tutor account
+	// named by course["tutorId"], if any
+	LinkTutor(ctx context.Context, course bson.M) error
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CourseRevisionService keeps the change history of courses and restores
+// earlier versions
+type CourseRevisionService interface {
+	// Insert stores a new course with its first revision
+	Insert(ctx context.Context, actor CourseActor, course bson.M) (*models.CourseRevision, error)
+
+	// Update sets and unsets fields of the course if it also matches match
+	// (see repository.CourseRepository.Change) and records what that write
+	// changed as a revision. Returns ErrCourseNotFound when nothing matched,
+	// and a nil revision when no tracked field changed. If recording fails
+	// the course stays changed and the error is returned.
+	Update(ctx context.Context, actor CourseActor, action, courseID string, match map[string]interface{}, set bson.M, unset ...string) (*models.CourseRevision, error)
+
+	// List returns the revisions numbered below before (0 = from the latest),
+	// newest first and at most limit
+	List(ctx context.Context, courseID string, before, limit int) ([]models.CourseRevision, error)
+
+	// Rollback restores the course fields of a revision, except the workflow
+	// fields (models.CourseWorkflowFields), and records that as a new
+	// revision. Returns ErrRevisionUnchanged if there is nothing to restore.
+	Rollback(ctx context.Context, actor CourseActor, courseID string, number int) (*models.CourseRevision, error)
+}
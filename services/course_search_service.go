@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CourseSearchHit - A course matching a search, with snippets of the fields
+// that matched (matches wrapped in <mark></mark>, the rest HTML-escaped)
+type CourseSearchHit struct {
+	Course     bson.M            `json:"course"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// CourseSuggestion - A course title completing what the student typed
+type CourseSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// CourseSearchService searches the public catalog by text
+type CourseSearchService interface {
+	// Search ranks published courses against query and returns the hits from
+	// offset on, at most limit, with the total number of hits
+	Search(ctx context.Context, query string, offset, limit int) ([]CourseSearchHit, int, error)
+
+	// Suggest returns course titles with words starting with the words of
+	// prefix, and completions for its last word
+	Suggest(ctx context.Context, prefix string, limit int) ([]CourseSuggestion, []string, error)
+}
@@ -0,0 +1,63 @@
+package services
+
+import "errors"
+
+// Errors returned by service implementations. Controllers compare against
+// these with errors.Is to pick an HTTP status code.
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrEmailNotVerified   = errors.New("email is not verified")
+	ErrInvalidCode        = errors.New("invalid or expired verification code")
+	ErrAlreadyVerified    = errors.New("email is already verified")
+
+	ErrInvalidResetToken  = errors.New("invalid or expired reset token")
+	ErrUnknownAccountType = errors.New("unknown account type")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+	ErrAdminNotFound    = errors.New("admin not found")
+	ErrAdminExists      = errors.New("admin with this email already exists")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrLastSuperAdmin   = errors.New("cannot remove the last active super-admin")
+	ErrCannotModifySelf = errors.New("you cannot deactivate or demote your own account")
+
+	ErrInvalidMFACode         = errors.New("invalid two-factor code")
+	ErrMFANotEnabled          = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolling        = errors.New("start two-factor enrollment first")
+	ErrMFARequired            = errors.New("two-factor authentication is required for this account")
+	ErrInvalidMFAChallenge    = errors.New("invalid or expired two-factor challenge")
+	ErrTooManyAttempts        = errors.New("too many failed attempts, try again later")
+	ErrLockoutNotFound        = errors.New("lockout not found")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrAccountNotFound        = errors.New("account not found")
+	ErrInvalidProfile         = errors.New("invalid profile")
+	ErrIncorrectPassword      = errors.New("current password is incorrect")
+	ErrUnknownProvider        = errors.New("unknown login provider")
+	ErrInvalidOIDCState       = errors.New("invalid or expired login attempt, please start again")
+	ErrOIDCEmailNotVerified   = errors.New("the provider did not confirm your email address")
+	ErrOIDCLoginFailed        = errors.New("could not sign in with the provider")
+	ErrInvalidAPIKey          = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+	ErrInvalidAPIKeyRequest   = errors.New("invalid API key request")
+	ErrSessionNotFound        = errors.New("session not found")
+	ErrSessionTerminated      = errors.New("this session has been signed out")
+	ErrAccountPendingDeletion = errors.New("this account has been deleted")
+	ErrImpersonationReason    = errors.New("a reason is required to impersonate a user")
+	ErrTutorNotFound          = errors.New("tutor not found")
+	ErrTutorExists            = errors.New("tutor with this email already exists")
+	ErrTutorDeactivated       = errors.New("tutor account is deactivated")
+	ErrCourseNotFound         = errors.New("course not found")
+	ErrCourseNotEditable      = errors.New("only draft courses can be edited")
+	ErrUserExists             = errors.New("user with this email already exists")
+	ErrAlreadyEnrolled        = errors.New("already enrolled in this course")
+	ErrNotEnrolled            = errors.New("not enrolled in this course")
+	ErrReviewNotFound         = errors.New("review not found")
+	ErrReviewExists           = errors.New("review already exists")
+	ErrInvalidCursor          = errors.New("invalid or expired cursor")
+	ErrInvalidTransition      = errors.New("the course cannot move to this status")
+	ErrCommentRequired        = errors.New("a comment is required to send a course back to draft")
+	ErrRevisionNotFound       = errors.New("revision not found")
+	ErrRevisionExists         = errors.New("revision already exists")
+	ErrRevisionUnchanged      = errors.New("the course already matches this revision")
+)
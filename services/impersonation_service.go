@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// ImpersonationService lets support staff act as a student
+type ImpersonationService interface {
+	// Start mints a short-lived token for the student, marked with the admin
+	// as actor, and records it in the audit log
+	Start(ctx context.Context, adminID, adminEmail, studentID, reason, ip, userAgent string) (*models.ImpersonationToken, error)
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// LoginGuardService tracks failed logins per account and per client IP and
+// locks them out with exponential backoff. An empty ip skips the IP counter.
+type LoginGuardService interface {
+	// Check returns ErrTooManyAttempts and the remaining lock time if the
+	// account or IP is locked out
+	Check(ctx context.Context, accountType, email, ip string) (time.Duration, error)
+
+	// RecordFailure counts a failed attempt, locking out once the limit is reached
+	RecordFailure(ctx context.Context, accountType, email, ip string) error
+
+	// RecordSuccess clears the account counter after a successful login
+	RecordSuccess(ctx context.Context, accountType, email string) error
+
+	// List returns current lockouts, or every tracked counter when all is set
+	List(ctx context.Context, all bool) ([]models.LoginAttempt, error)
+
+	// Clear removes a counter (and its lockout) by ID
+	Clear(ctx context.Context, id string) error
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// MFAService manages TOTP two-factor authentication for students and admins
+type MFAService interface {
+	// BeginEnrollment generates a pending secret and recovery codes. Nothing
+	// changes for the account until ConfirmEnrollment succeeds.
+	BeginEnrollment(ctx context.Context, accountType, accountID string) (*models.MFAEnrollment, error)
+
+	// ConfirmEnrollment enables 2FA once the user proves their app works
+	ConfirmEnrollment(ctx context.Context, accountType, accountID, code string) error
+
+	// Disable turns 2FA off; requires a current code or a recovery code
+	Disable(ctx context.Context, accountType, accountID, code string) error
+
+	// Verify checks a TOTP code, or consumes a recovery code
+	Verify(ctx context.Context, accountType, accountID, code string) error
+
+	// IsEnabled reports whether 2FA is on for the account
+	IsEnabled(ctx context.Context, accountType, accountID string) (bool, error)
+
+	// IsRequired reports whether policy makes 2FA mandatory for the account type
+	IsRequired(accountType string) bool
+
+	// StartLogin runs after a successful password check: it issues tokens, or
+	// an MFA challenge when 2FA is enabled or required for the account
+	StartLogin(ctx context.Context, accountType, accountID, email, role string) (*models.LoginResult, error)
+
+	// EnrollWithChallenge starts enrollment for an account whose challenge
+	// demands it (mandatory 2FA, not yet set up)
+	EnrollWithChallenge(ctx context.Context, challengeToken string) (*models.MFAEnrollment, error)
+
+	// CompleteLogin exchanges a challenge token and code for tokens, once per
+	// challenge. For enrollment challenges the code also confirms the enrollment.
+	CompleteLogin(ctx context.Context, challengeToken, code string) (*models.TokenPair, error)
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// OIDCService signs students in through external OpenID Connect providers
+type OIDCService interface {
+	// Providers lists the configured provider names
+	Providers() []string
+
+	// Begin stores a new state/PKCE verifier and returns the provider's
+	// authorization URL with the browser nonce bound to the state
+	Begin(ctx context.Context, provider string) (*models.OIDCAuthorization, error)
+
+	// Complete handles the callback: it checks the browser nonce against the
+	// state, redeems the code, finds or creates the student linked to the
+	// identity, and starts a login like a password login
+	Complete(ctx context.Context, provider, code, state, browserNonce string) (*models.LoginResult, error)
+}
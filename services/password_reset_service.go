@@ -0,0 +1,19 @@
+package services
+
+import "context"
+
+// PasswordResetService issues and redeems one-time password reset tokens for
+// students, admins and tutors (see models.AccountTypeStudent / AccountTypeAdmin / AccountTypeTutor).
+type PasswordResetService interface {
+	// RequestReset emails a reset token if an account exists for the email.
+	// It returns nil for unknown emails so callers cannot probe for accounts.
+	RequestReset(ctx context.Context, accountType, email string) error
+
+	// ResetPassword redeems the token, sets the new password and invalidates
+	// every token issued to the account before the change.
+	ResetPassword(ctx context.Context, accountType, token, newPassword string) error
+
+	// SendInvitation emails a longer-lived set-password token to a newly
+	// created account. The token is redeemed through ResetPassword.
+	SendInvitation(ctx context.Context, accountType, email, invitedBy string) error
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// SessionService manages refresh-token sessions and the access token revocation list
+type SessionService interface {
+	// Create starts a session and returns its first access/refresh token pair
+	Create(ctx context.Context, accountType, accountID, email, role string) (*models.TokenPair, error)
+
+	// Refresh rotates the refresh token. Presenting an already-rotated token
+	// revokes the whole session, since it means the token was copied.
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+
+	// Logout revokes the access token (by jti) and, if set, its session
+	Logout(ctx context.Context, jti, sessionID string, tokenExpiresAt time.Time) error
+
+	// RevokeToken adds a single access token to the revocation list
+	RevokeToken(ctx context.Context, jti, reason string, tokenExpiresAt time.Time) error
+
+	// IsTokenRevoked checks the revocation list
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	// RevokeAllForAccount ends every session of an account (e.g. after a password change)
+	RevokeAllForAccount(ctx context.Context, accountType, accountID, reason string) error
+
+	// ListForAccount returns the account's active sessions, most recently used first
+	ListForAccount(ctx context.Context, accountType, accountID string) ([]models.Session, error)
+
+	// RevokeForAccount ends one session, if it belongs to the account
+	RevokeForAccount(ctx context.Context, accountType, accountID, sessionID, reason string) error
+
+	// Touch records device info and last-seen time, and returns
+	// ErrSessionTerminated if the session was revoked or no longer exists
+	Touch(ctx context.Context, sessionID, userAgent, ip string) error
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+)
+
+// TutorUpdate holds the optional fields of a tutor that can be changed.
+// IsActive is only honoured for admins; tutors edit their own profile.
+type TutorUpdate struct {
+	Name        *string
+	Bio         *string
+	Avatar      *string
+	Expertise   *string
+	YearsExp    *int
+	Credentials *string
+	IsActive    *bool
+}
+
+// TutorService manages accounts in the tutors collection
+type TutorService interface {
+	// List returns every tutor, newest first
+	List(ctx context.Context) ([]models.TutorAccount, error)
+
+	// GetByID returns a single tutor
+	GetByID(ctx context.Context, id string) (*models.TutorAccount, error)
+
+	// Invite adds a tutor without a password and emails a set-password link
+	Invite(ctx context.Context, email, name, invitedBy string) (*models.TutorAccount, error)
+
+	// Update applies the fields that are set and copies the display fields
+	// onto the tutor's courses. Deactivating ends all sessions.
+	Update(ctx context.Context, id string, update TutorUpdate) (*models.TutorAccount, error)
+
+	// Login returns tokens, or an MFA challenge when 2FA is enabled for the tutor
+	Login(ctx context.Context, email, password string) (*models.LoginResult, error)
+}
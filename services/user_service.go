@@ -2,7 +2,35 @@ package services
 
 import "github.com/AbaraEmmanuel/jaromind-backend/models"
 
+// ProfileUpdate holds the optional profile fields a student can change
+type ProfileUpdate struct {
+	Name        *string
+	Avatar      *string
+	ClassLevel  *string
+	Language    *string
+	Preferences *models.UserPreferences
+}
+
 type UserService interface {
 	Register(student models.User) error
-	Login(email, password string) (string, error)
+	// Login returns tokens, or an MFA challenge when 2FA is enabled for the student
+	Login(email, password string) (*models.LoginResult, error)
+
+	// VerifyEmail marks the account as verified if the code matches and has not expired
+	VerifyEmail(email, code string) error
+
+	// ResendVerification issues a fresh code and emails it to the student. It
+	// returns nil without sending for unknown or verified addresses and within
+	// the resend cooldown, so callers cannot tell registered addresses apart.
+	ResendVerification(email string) error
+
+	// GetProfile loads the student from the students collection
+	GetProfile(userID string) (*models.User, error)
+
+	// UpdateProfile validates and applies the fields that are set
+	UpdateProfile(userID string, update ProfileUpdate) (*models.User, error)
+
+	// ChangePassword checks the current password, ends every other session and
+	// returns fresh tokens for the caller, whose old token stops working
+	ChangePassword(userID, currentPassword, newPassword string) (*models.TokenPair, error)
 }
@@ -0,0 +1,207 @@
+package services_impl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type accountDataServiceImpl struct {
+	store *repository.Store
+}
+
+// NewAccountDataService - Constructor
+func NewAccountDataService() services.AccountDataService {
+	return &accountDataServiceImpl{store: repository.Default()}
+}
+
+// accountDeletionGrace - Time between a deletion request and removing the record
+func accountDeletionGrace() time.Duration {
+	return utils.GetEnvDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour)
+}
+
+func (s *accountDataServiceImpl) findStudent(ctx context.Context, userID string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, services.ErrUserNotFound
+	}
+
+	return s.store.Users.FindByID(ctx, objectID)
+}
+
+// -------- EXPORT --------
+
+func (s *accountDataServiceImpl) Export(ctx context.Context, userID string) (*models.AccountExport, error) {
+	student, err := s.findStudent(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.AccountExport{
+		ExportedAt:  time.Now(),
+		Profile:     student,
+		Enrollments: []models.EnrollmentExport{},
+		Reviews:     []models.Review{},
+		Sessions:    []models.Session{},
+	}
+
+	enrollments, err := s.store.Enrollments.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, enrollment := range enrollments {
+		item := models.EnrollmentExport{Enrollment: enrollment}
+		var course struct {
+			Title string `bson:"title"`
+		}
+		if err := s.store.Courses.Find(ctx, enrollment.CourseID, nil, &course); err == nil {
+			item.CourseTitle = course.Title
+		}
+		export.Enrollments = append(export.Enrollments, item)
+	}
+
+	if export.Reviews, err = s.store.Reviews.ListByUser(ctx, student.ID); err != nil {
+		return nil, err
+	}
+	if export.Sessions, err = s.store.Sessions.ListByAccount(ctx, models.AccountTypeStudent, userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// -------- DELETION --------
+
+func (s *accountDataServiceImpl) RequestDeletion(ctx context.Context, userID, password string) (time.Time, error) {
+	student, err := s.findStudent(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if student.DeletionScheduledAt != nil {
+		return *student.DeletionScheduledAt, nil
+	}
+
+	// Accounts created through social login have no password to confirm
+	if student.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(password)); err != nil {
+			return time.Time{}, services.ErrIncorrectPassword
+		}
+	}
+
+	now := time.Now()
+	scheduledAt := now.Add(accountDeletionGrace())
+
+	// Mark first: from here on the middleware rejects the student's tokens
+	err = s.store.Users.Update(ctx, student.ID, bson.M{
+		"deletion_requested_at": now,
+		"deletion_scheduled_at": scheduledAt,
+		"updated_at":            now,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := NewSessionService().RevokeAllForAccount(ctx, models.AccountTypeStudent, userID, "account deleted"); err != nil {
+		return time.Time{}, err
+	}
+
+	// Reviews stay (they feed course ratings) but no longer name the author
+	err = s.store.Reviews.UpdateByUser(ctx, student.ID, bson.M{
+		"user_name":  models.DeletedUserName,
+		"updated_at": now,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := s.removeEnrollments(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	log.Printf("🗑️ Student %s requested deletion, removing on %s", userID, scheduledAt.Format(time.RFC3339))
+	return scheduledAt, nil
+}
+
+// removeEnrollments - Deletes the student's enrollments and keeps course counters in step
+func (s *accountDataServiceImpl) removeEnrollments(ctx context.Context, userID string) error {
+	enrollments, err := s.store.Enrollments.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, enrollment := range enrollments {
+		err := s.store.Enrollments.Delete(ctx, userID, enrollment.CourseID)
+		if errors.Is(err, services.ErrNotEnrolled) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.store.Courses.Decrement(ctx, enrollment.CourseID, "enrollmentCount"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *accountDataServiceImpl) PurgeDue(ctx context.Context) (int, error) {
+	due, err := s.store.Users.ListDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, student := range due {
+		if err := s.purge(ctx, &student); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purge - Removes the student and everything that still points at them.
+// Each step is idempotent, so a purge interrupted halfway is finished next run.
+func (s *accountDataServiceImpl) purge(ctx context.Context, student *models.User) error {
+	userID := student.ID.Hex()
+
+	if err := s.removeEnrollments(ctx, userID); err != nil {
+		return err
+	}
+
+	// Keep the anonymized reviews, but drop the last link to the person. The
+	// field is removed rather than zeroed: reviews without it are left out of
+	// the unique index, so two purged reviewers of one course don't collide.
+	err := s.store.Reviews.UpdateByUser(ctx, student.ID, bson.M{"user_name": models.DeletedUserName}, "user_id")
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Sessions.DeleteByAccount(ctx, models.AccountTypeStudent, userID); err != nil {
+		return err
+	}
+	if err := s.store.PasswordResets.DeleteByAccount(ctx, models.AccountTypeStudent, student.ID); err != nil {
+		return err
+	}
+	if err := s.store.LoginAttempts.DeleteByKey(ctx, accountCounter(models.AccountTypeStudent, student.Email).key); err != nil {
+		return err
+	}
+
+	// The student record goes last, so PurgeDue finds it again if anything above failed
+	if err := s.store.Users.Delete(ctx, student.ID); err != nil && !errors.Is(err, services.ErrUserNotFound) {
+		return err
+	}
+
+	log.Printf("🗑️ Purged deleted student %s", userID)
+	return nil
+}
@@ -0,0 +1,55 @@
+package services_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPurgeDueKeepsAnonymizedReviews(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	service := &accountDataServiceImpl{store: store}
+
+	due := time.Now().Add(-time.Hour)
+	for _, email := range []string{"ada@example.com", "grace@example.com"} {
+		student := &models.User{ID: primitive.NewObjectID(), Email: email, DeletionScheduledAt: &due}
+		if err := store.Users.Insert(ctx, student); err != nil {
+			t.Fatal(err)
+		}
+		review := &models.Review{CourseID: "course-1", UserID: student.ID, UserName: "Student", Rating: 4}
+		if err := store.Reviews.Insert(ctx, review); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := service.PurgeDue(ctx)
+	if err != nil {
+		t.Fatalf("PurgeDue: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("PurgeDue purged %d students, want 2", purged)
+	}
+
+	reviews, err := store.Reviews.ListByCourse(ctx, "course-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("course has %d reviews, want both kept", len(reviews))
+	}
+	for _, review := range reviews {
+		if !review.UserID.IsZero() || review.UserName != models.DeletedUserName {
+			t.Errorf("review = %+v, want it anonymized", review)
+		}
+	}
+
+	if again, err := service.PurgeDue(ctx); err != nil || again != 0 {
+		t.Errorf("second PurgeDue = %d, %v, want nothing left", again, err)
+	}
+}
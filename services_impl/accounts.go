@@ -0,0 +1,48 @@
+package services_impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// accountsFor - The repository of a kind of account (models.AccountType*)
+func accountsFor(accountType string) (repository.AccountRepository, error) {
+	return repository.Default().Accounts.For(accountType)
+}
+
+// TokenInvalidForAccount reports whether a token issued at issuedAt for the
+// given account must be rejected: the account is gone, deactivated, or its
+// password changed after the token was issued.
+func TokenInvalidForAccount(ctx context.Context, accountType, accountID string, issuedAt time.Time) (bool, error) {
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return false, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return true, nil
+	}
+
+	account, err := accounts.FindByID(ctx, objectID)
+	if err == services.ErrAccountNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !account.IsActive {
+		return true, nil
+	}
+
+	if account.PasswordChangedAt == nil {
+		return false, nil
+	}
+	// JWT iat has second precision
+	return issuedAt.Before(account.PasswordChangedAt.Truncate(time.Second)), nil
+}
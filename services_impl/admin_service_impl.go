@@ -0,0 +1,213 @@
+package services_impl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type adminServiceImpl struct {
+	admins repository.AdminRepository
+}
+
+// NewAdminService - Constructor
+func NewAdminService() services.AdminService {
+	return &adminServiceImpl{
+		admins: repository.Default().Admins,
+	}
+}
+
+func (s *adminServiceImpl) List(ctx context.Context) ([]models.Admin, error) {
+	admins, err := s.admins.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range admins {
+		admins[i].Role = adminRole(&admins[i])
+	}
+	return admins, nil
+}
+
+func (s *adminServiceImpl) GetByID(ctx context.Context, id string) (*models.Admin, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, services.ErrAdminNotFound
+	}
+
+	admin, err := s.admins.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	admin.Role = adminRole(admin)
+	return admin, nil
+}
+
+func (s *adminServiceImpl) Create(ctx context.Context, email, name, password, role string) (*models.Admin, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return s.insert(ctx, email, name, string(hashedPassword), role, "")
+}
+
+func (s *adminServiceImpl) Invite(ctx context.Context, email, name, role, invitedBy string) (*models.Admin, error) {
+	// No usable password until the invitation is accepted
+	admin, err := s.insert(ctx, email, name, "", role, invitedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	inviterName := ""
+	if inviter, err := s.GetByID(ctx, invitedBy); err == nil {
+		inviterName = inviter.Name
+	}
+
+	if err := NewPasswordResetService().SendInvitation(ctx, models.AccountTypeAdmin, admin.Email, inviterName); err != nil {
+		log.Printf("⚠️ Failed to send admin invitation to %s: %v", admin.Email, err)
+	}
+	return admin, nil
+}
+
+func (s *adminServiceImpl) insert(ctx context.Context, email, name, hashedPassword, role, invitedBy string) (*models.Admin, error) {
+	email = strings.TrimSpace(email)
+	if role == "" {
+		role = models.RoleContentAdmin
+	}
+	if !models.IsValidAdminRole(role) {
+		return nil, services.ErrInvalidRole
+	}
+
+	if _, err := s.admins.FindByEmail(ctx, email); err == nil {
+		return nil, services.ErrAdminExists
+	} else if !errors.Is(err, services.ErrAdminNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	admin := models.Admin{
+		ID:        primitive.NewObjectID(),
+		Email:     email,
+		Password:  hashedPassword,
+		Name:      name,
+		CreatedAt: primitive.NewDateTimeFromTime(now),
+		UpdatedAt: now,
+		IsActive:  true,
+		Role:      role,
+		InvitedBy: invitedBy,
+	}
+
+	if err := s.admins.Insert(ctx, &admin); err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+func (s *adminServiceImpl) Update(ctx context.Context, id, actorID string, update services.AdminUpdate) (*models.Admin, error) {
+	admin, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+
+	if update.Name != nil {
+		set["name"] = strings.TrimSpace(*update.Name)
+	}
+
+	demoting := false
+	if update.Role != nil && *update.Role != admin.Role {
+		if !models.IsValidAdminRole(*update.Role) {
+			return nil, services.ErrInvalidRole
+		}
+		set["role"] = *update.Role
+		demoting = admin.Role == models.RoleSuperAdmin
+	}
+
+	deactivating := update.IsActive != nil && !*update.IsActive && admin.IsActive
+	if update.IsActive != nil {
+		set["isActive"] = *update.IsActive
+	}
+
+	if (deactivating || demoting) && id == actorID {
+		return nil, services.ErrCannotModifySelf
+	}
+	if (deactivating || demoting) && admin.Role == models.RoleSuperAdmin {
+		if err := s.ensureAnotherSuperAdmin(ctx, admin.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.admins.Update(ctx, admin.ID, set); err != nil {
+		return nil, err
+	}
+
+	// Role changes and deactivation must not wait for tokens to expire
+	if _, roleChanged := set["role"]; deactivating || roleChanged {
+		if err := NewSessionService().RevokeAllForAccount(ctx, models.AccountTypeAdmin, id, "admin updated"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *adminServiceImpl) ensureAnotherSuperAdmin(ctx context.Context, exclude primitive.ObjectID) error {
+	count, err := s.admins.CountActiveSuperAdmins(ctx, exclude)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return services.ErrLastSuperAdmin
+	}
+	return nil
+}
+
+func (s *adminServiceImpl) SendPasswordReset(ctx context.Context, id string) error {
+	admin, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return NewPasswordResetService().RequestReset(ctx, models.AccountTypeAdmin, admin.Email)
+}
+
+func (s *adminServiceImpl) SetPassword(ctx context.Context, email, password string) error {
+	accounts, err := accountsFor(models.AccountTypeAdmin)
+	if err != nil {
+		return err
+	}
+
+	account, err := accounts.FindByEmail(ctx, strings.TrimSpace(email))
+	if err == services.ErrAccountNotFound {
+		return services.ErrAdminNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := accounts.SetPassword(ctx, account.ID, string(hashedPassword), time.Now()); err != nil {
+		return err
+	}
+	return NewSessionService().RevokeAllForAccount(ctx, models.AccountTypeAdmin, account.ID.Hex(), "password rotated")
+}
+
+// adminRole - Role of an admin document, mapping pre-role documents to super-admin
+func adminRole(admin *models.Admin) string {
+	if admin.Role == "" {
+		return models.RoleSuperAdmin
+	}
+	return models.NormalizeRole(admin.Role)
+}
@@ -0,0 +1,179 @@
+package services_impl
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lastUsedResolution - lastUsedAt is only rewritten when older than this, so
+// a busy integration doesn't cause a write on every request
+const lastUsedResolution = time.Minute
+
+type apiKeyServiceImpl struct {
+	keys repository.APIKeyRepository
+}
+
+// NewAPIKeyService - Constructor
+func NewAPIKeyService() services.APIKeyService {
+	return &apiKeyServiceImpl{
+		keys: repository.Default().APIKeys,
+	}
+}
+
+// apiKeyDefaultTTL / apiKeyMaxTTL - Keys always expire; the maximum keeps
+// forgotten keys from living forever
+func apiKeyDefaultTTL() time.Duration {
+	return utils.GetEnvDuration("API_KEY_DEFAULT_TTL", 90*24*time.Hour)
+}
+
+func apiKeyMaxTTL() time.Duration {
+	return utils.GetEnvDuration("API_KEY_MAX_TTL", 365*24*time.Hour)
+}
+
+// newAPIKey - jmk_<8 char id>_<secret>. The id part is the stored prefix.
+func newAPIKey() (plain, prefix string, err error) {
+	id, err := utils.GenerateSecureToken(6)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	prefix = models.APIKeyPrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+// apiKeyPrefix - The prefix part of a plain key ("" if it isn't shaped like one)
+func apiKeyPrefix(rawKey string) string {
+	if !strings.HasPrefix(rawKey, models.APIKeyPrefix) {
+		return ""
+	}
+	// The id is base64url, which may itself contain "_", so cut by length
+	prefixLength := len(models.APIKeyPrefix) + 8
+	if len(rawKey) <= prefixLength+1 || rawKey[prefixLength] != '_' {
+		return ""
+	}
+	return rawKey[:prefixLength]
+}
+
+func (s *apiKeyServiceImpl) Create(ctx context.Context, ownerID, ownerRole, name string, scopes []models.Permission, expiresAt *time.Time) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", services.ErrInvalidAPIKeyRequest)
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", services.ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range scopes {
+		if !models.IsValidAPIKeyScope(scope) {
+			return nil, "", fmt.Errorf("%w: %q can't be granted to API keys", services.ErrInvalidAPIKeyRequest, scope)
+		}
+		// A key can never do more than its owner
+		if !models.HasPermission(ownerRole, scope) {
+			return nil, "", fmt.Errorf("%w: your role doesn't grant %q", services.ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+
+	now := time.Now()
+	expiry := now.Add(apiKeyDefaultTTL())
+	if expiresAt != nil {
+		expiry = *expiresAt
+	}
+	if !expiry.After(now) || expiry.After(now.Add(apiKeyMaxTTL())) {
+		return nil, "", fmt.Errorf("%w: expiry must be in the future and within %s", services.ErrInvalidAPIKeyRequest, apiKeyMaxTTL())
+	}
+
+	plain, prefix, err := newAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(plain),
+		OwnerID:   ownerID,
+		Scopes:    scopes,
+		ExpiresAt: &expiry,
+		CreatedAt: now,
+	}
+	if err := s.keys.Insert(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plain, nil
+}
+
+func (s *apiKeyServiceImpl) List(ctx context.Context, ownerID string) ([]models.APIKey, error) {
+	return s.keys.List(ctx, ownerID)
+}
+
+func (s *apiKeyServiceImpl) Revoke(ctx context.Context, id, ownerID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return services.ErrAPIKeyNotFound
+	}
+	return s.keys.Revoke(ctx, objectID, ownerID, time.Now())
+}
+
+func (s *apiKeyServiceImpl) Authenticate(ctx context.Context, rawKey, clientIP string) (*services.APIKeyPrincipal, error) {
+	prefix := apiKeyPrefix(rawKey)
+	if prefix == "" {
+		return nil, services.ErrInvalidAPIKey
+	}
+
+	key, err := s.keys.FindByPrefix(ctx, prefix)
+	if err == services.ErrAPIKeyNotFound {
+		return nil, services.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(rawKey))) != 1 {
+		return nil, services.ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, services.ErrInvalidAPIKey
+	}
+
+	// The owner's current state decides: deactivated or demoted owners take their keys with them
+	owner, err := NewAdminService().GetByID(ctx, key.OwnerID)
+	if err == services.ErrAdminNotFound {
+		return nil, services.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Older admin documents have no isActive field; they come back active
+	if !owner.IsActive {
+		return nil, services.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.keys.MarkUsed(ctx, key.ID, clientIP, now); err != nil {
+			log.Printf("⚠️ Failed to record API key use for %s: %v", key.Prefix, err)
+		}
+	}
+
+	return &services.APIKeyPrincipal{
+		Key:   key,
+		Email: owner.Email,
+		Role:  owner.Role,
+	}, nil
+}
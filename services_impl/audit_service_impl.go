@@ -0,0 +1,60 @@
+package services_impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditServiceImpl struct {
+	logs repository.AuditLogRepository
+}
+
+// NewAuditService - Constructor
+func NewAuditService() services.AuditService {
+	return &auditServiceImpl{
+		logs: repository.Default().AuditLogs,
+	}
+}
+
+func (s *auditServiceImpl) Record(ctx context.Context, entry *models.AuditLog) error {
+	entry.CreatedAt = time.Now()
+	return s.logs.Insert(ctx, entry)
+}
+
+func (s *auditServiceImpl) SetStatus(ctx context.Context, id primitive.ObjectID, status int) error {
+	return s.logs.SetStatus(ctx, id, status)
+}
+
+func (s *auditServiceImpl) List(ctx context.Context, filter services.AuditFilter) ([]models.AuditLog, error) {
+	query := repository.Fields{}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.ActorID != "" {
+		query["actorId"] = filter.ActorID
+	}
+	if filter.SubjectID != "" {
+		query["subjectId"] = filter.SubjectID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	return s.logs.List(ctx, query, limit)
+}
@@ -0,0 +1,342 @@
+package services_impl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type courseAuthoringServiceImpl struct {
+	courses  repository.CourseRepository
+	comments repository.CourseCommentRepository
+	history  services.CourseRevisionService
+}
+
+// NewCourseAuthoringService - Constructor
+func NewCourseAuthoringService() services.CourseAuthoringService {
+	store := repository.Default()
+	return &courseAuthoringServiceImpl{
+		courses:  store.Courses,
+		comments: store.Comments,
+		history:  NewCourseRevisionService(),
+	}
+}
+
+func (s *courseAuthoringServiceImpl) ListByTutor(ctx context.Context, tutorID string) ([]bson.M, error) {
+	courses := []bson.M{}
+	err := s.courses.List(ctx, repository.Fields{"tutorId": tutorID}, repository.Sort{Field: "updatedAt", Descending: true}, &courses)
+	if err != nil {
+		return nil, err
+	}
+	for _, course := range courses {
+		delete(course, "_id")
+	}
+	return courses, nil
+}
+
+func (s *courseAuthoringServiceImpl) CreateDraft(ctx context.Context, tutorID string, input *models.CourseInput) (bson.M, error) {
+	if errs := input.TutorRestricted(); errs != nil {
+		return nil, errs
+	}
+	if errs := input.Validate(true); errs != nil {
+		return nil, errs
+	}
+
+	tutor, err := NewTutorService().GetByID(ctx, tutorID)
+	if err != nil {
+		return nil, err
+	}
+
+	course := input.Fields()
+	now := time.Now()
+	course["id"] = uuid.New().String()
+	course["tutorId"] = tutorID
+	course["tutor"] = tutor.DisplayProfile()
+	course["status"] = models.CourseStatusDraft
+	course["isActive"] = false
+	course["isFeatured"] = false
+	course["enrollmentCount"] = 0
+	course["rating"] = 0.0
+	course["reviewCount"] = 0
+	course["createdAt"] = now
+	course["updatedAt"] = now
+	if _, exists := course["lessonCount"]; !exists {
+		course["lessonCount"] = 0
+	}
+
+	actor := services.CourseActor{ID: tutorID, Email: tutor.Email, Role: models.RoleTutor}
+	if _, err := s.history.Insert(ctx, actor, course); err != nil {
+		return nil, err
+	}
+
+	delete(course, "_id")
+	return course, nil
+}
+
+func (s *courseAuthoringServiceImpl) UpdateDraft(ctx context.Context, tutorID, courseID string, input *models.CourseInput) error {
+	if errs := input.TutorRestricted(); errs != nil {
+		return errs
+	}
+	if errs := input.Validate(false); errs != nil {
+		return errs
+	}
+
+	updates := input.Fields()
+	updates["updatedAt"] = time.Now()
+
+	own := repository.Fields{"tutorId": tutorID}
+
+	var current struct {
+		Status string `bson:"status"`
+	}
+	if err := s.courses.Find(ctx, courseID, own, &current); err != nil {
+		return err
+	}
+	if current.Status != models.CourseStatusDraft {
+		return services.ErrCourseNotEditable
+	}
+
+	// The status is part of the match so a course published meanwhile is left alone
+	own["status"] = models.CourseStatusDraft
+	actor := services.CourseActor{ID: tutorID, Role: models.RoleTutor}
+	_, err := s.history.Update(ctx, actor, models.CourseRevisionUpdate, courseID, own, updates)
+	if errors.Is(err, services.ErrCourseNotFound) {
+		return services.ErrCourseNotEditable
+	}
+	return err
+}
+
+// courseCommentMaxLength - Longest reviewer or tutor note accepted
+const courseCommentMaxLength = 2000
+
+// schedulerActor - Who status changes made by RunSchedule are recorded as
+var schedulerActor = services.CourseActor{ID: "scheduler", Role: "system"}
+
+// courseState - The workflow fields of a stored course
+type courseState struct {
+	Status               string     `bson:"status"`
+	ScheduledPublishAt   *time.Time `bson:"scheduledPublishAt"`
+	ScheduledUnpublishAt *time.Time `bson:"scheduledUnpublishAt"`
+}
+
+// load - The workflow state of a course the actor may act on. Tutors only
+// see their own courses.
+func (s *courseAuthoringServiceImpl) load(ctx context.Context, actor services.CourseActor, courseID string) (*courseState, error) {
+	var match repository.Fields
+	if actor.Role == models.RoleTutor {
+		match = repository.Fields{"tutorId": actor.ID}
+	}
+
+	var state courseState
+	if err := s.courses.Find(ctx, courseID, match, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *courseAuthoringServiceImpl) Transition(ctx context.Context, actor services.CourseActor, courseID, status, comment string) error {
+	comment = strings.TrimSpace(comment)
+	if len(comment) > courseCommentMaxLength {
+		return models.FieldErrors{"comment": "must be at most 2000 characters"}
+	}
+
+	state, err := s.load(ctx, actor, courseID)
+	if err != nil {
+		return err
+	}
+	if !models.CanTransition(state.Status, status) {
+		return services.ErrInvalidTransition
+	}
+
+	// Tutors submit their drafts and withdraw them from review; the rest is up to reviewers
+	if actor.Role == models.RoleTutor && status != models.CourseStatusInReview && status != models.CourseStatusDraft {
+		return services.ErrInvalidTransition
+	}
+	if state.Status == models.CourseStatusInReview && status == models.CourseStatusDraft &&
+		actor.Role != models.RoleTutor && comment == "" {
+		return services.ErrCommentRequired
+	}
+
+	return s.transition(ctx, actor, courseID, state.Status, status, comment, time.Now())
+}
+
+// transition - Moves the course from one status to another, unless its status
+// changed meanwhile, and records the change
+func (s *courseAuthoringServiceImpl) transition(ctx context.Context, actor services.CourseActor, courseID, from, to, comment string, now time.Time) error {
+	set := bson.M{
+		"status":    to,
+		"isActive":  to == models.CourseStatusPublished,
+		"updatedAt": now,
+	}
+	// A scheduled publish only applies while in review, and a scheduled
+	// unpublish only until the course leaves review or publication
+	unset := []string{"scheduledPublishAt"}
+	switch to {
+	case models.CourseStatusPublished:
+		set["publishedAt"] = now
+	case models.CourseStatusArchived:
+		set["archivedAt"] = now
+		unset = append(unset, "scheduledUnpublishAt")
+	default:
+		unset = append(unset, "scheduledUnpublishAt")
+	}
+
+	_, err := s.history.Update(ctx, actor, models.CourseRevisionStatus, courseID, repository.Fields{"status": from}, set, unset...)
+	if errors.Is(err, services.ErrCourseNotFound) {
+		return services.ErrInvalidTransition
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.comments.Insert(ctx, &models.CourseComment{
+		CourseID:    courseID,
+		AuthorID:    actor.ID,
+		AuthorEmail: actor.Email,
+		AuthorRole:  actor.Role,
+		Comment:     comment,
+		FromStatus:  from,
+		ToStatus:    to,
+		CreatedAt:   now,
+	})
+}
+
+func (s *courseAuthoringServiceImpl) Schedule(ctx context.Context, actor services.CourseActor, courseID string, schedule services.CourseSchedule) error {
+	state, err := s.load(ctx, actor, courseID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	errs := models.FieldErrors{}
+	if at := schedule.PublishAt; at != nil {
+		switch {
+		case state.Status != models.CourseStatusInReview:
+			errs["publishAt"] = "only courses in review can be scheduled for publishing"
+		case !at.After(now):
+			errs["publishAt"] = "must be in the future"
+		}
+	}
+	if at := schedule.UnpublishAt; at != nil {
+		switch {
+		case state.Status != models.CourseStatusInReview && state.Status != models.CourseStatusPublished:
+			errs["unpublishAt"] = "only courses in review or published can be scheduled for unpublishing"
+		case !at.After(now):
+			errs["unpublishAt"] = "must be in the future"
+		case schedule.PublishAt != nil && !at.After(*schedule.PublishAt):
+			errs["unpublishAt"] = "must be after publishAt"
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	set := bson.M{"updatedAt": now}
+	var unset []string
+	for field, at := range map[string]*time.Time{
+		"scheduledPublishAt":   schedule.PublishAt,
+		"scheduledUnpublishAt": schedule.UnpublishAt,
+	} {
+		if at != nil {
+			set[field] = at.UTC()
+		} else {
+			unset = append(unset, field)
+		}
+	}
+
+	// The status is part of the match so the times are checked against the status they apply to
+	_, err = s.history.Update(ctx, actor, models.CourseRevisionSchedule, courseID, repository.Fields{"status": state.Status}, set, unset...)
+	if errors.Is(err, services.ErrCourseNotFound) {
+		return services.ErrInvalidTransition
+	}
+	return err
+}
+
+func (s *courseAuthoringServiceImpl) RunSchedule(ctx context.Context, now time.Time) (int, int, error) {
+	published, err := s.runDue(ctx, models.CourseStatusInReview, "scheduledPublishAt", models.CourseStatusPublished, now)
+	if err != nil {
+		return published, 0, err
+	}
+	archived, err := s.runDue(ctx, models.CourseStatusPublished, "scheduledUnpublishAt", models.CourseStatusArchived, now)
+	return published, archived, err
+}
+
+// runDue - Moves the courses in status whose time field has come to the next
+// status. Courses that changed since they were listed are skipped.
+func (s *courseAuthoringServiceImpl) runDue(ctx context.Context, status, field, to string, now time.Time) (int, error) {
+	var due []struct {
+		ID string `bson:"id"`
+	}
+	if err := s.courses.ListDue(ctx, status, field, now, &due); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, course := range due {
+		err := s.transition(ctx, schedulerActor, course.ID, status, to, "", now)
+		if errors.Is(err, services.ErrInvalidTransition) {
+			continue
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+func (s *courseAuthoringServiceImpl) AddComment(ctx context.Context, actor services.CourseActor, courseID, comment string) (*models.CourseComment, error) {
+	comment = strings.TrimSpace(comment)
+	switch {
+	case comment == "":
+		return nil, models.FieldErrors{"comment": "is required"}
+	case len(comment) > courseCommentMaxLength:
+		return nil, models.FieldErrors{"comment": "must be at most 2000 characters"}
+	}
+
+	if _, err := s.load(ctx, actor, courseID); err != nil {
+		return nil, err
+	}
+
+	note := &models.CourseComment{
+		CourseID:    courseID,
+		AuthorID:    actor.ID,
+		AuthorEmail: actor.Email,
+		AuthorRole:  actor.Role,
+		Comment:     comment,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.comments.Insert(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+func (s *courseAuthoringServiceImpl) ListComments(ctx context.Context, actor services.CourseActor, courseID string) ([]models.CourseComment, error) {
+	if _, err := s.load(ctx, actor, courseID); err != nil {
+		return nil, err
+	}
+	return s.comments.ListByCourse(ctx, courseID)
+}
+
+func (s *courseAuthoringServiceImpl) LinkTutor(ctx context.Context, course bson.M) error {
+	tutorID, _ := course["tutorId"].(string)
+	if tutorID == "" {
+		return nil
+	}
+
+	tutor, err := NewTutorService().GetByID(ctx, tutorID)
+	if err != nil {
+		return err
+	}
+	course["tutor"] = tutor.DisplayProfile()
+	return nil
+}
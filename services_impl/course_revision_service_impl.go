@@ -0,0 +1,164 @@
+package services_impl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type courseRevisionServiceImpl struct {
+	courses   repository.CourseRepository
+	revisions repository.CourseRevisionRepository
+}
+
+// NewCourseRevisionService - Constructor
+func NewCourseRevisionService() services.CourseRevisionService {
+	store := repository.Default()
+	return &courseRevisionServiceImpl{
+		courses:   store.Courses,
+		revisions: store.Revisions,
+	}
+}
+
+func (s *courseRevisionServiceImpl) Insert(ctx context.Context, actor services.CourseActor, course bson.M) (*models.CourseRevision, error) {
+	course[models.CourseRevisionField] = int32(1)
+	if err := s.courses.Insert(ctx, course); err != nil {
+		return nil, err
+	}
+
+	// Round trip the course so the snapshot holds the values as stored
+	raw, err := bson.Marshal(course)
+	if err != nil {
+		return nil, err
+	}
+	var stored bson.M
+	if err := bson.Unmarshal(raw, &stored); err != nil {
+		return nil, err
+	}
+
+	courseID, _ := course["id"].(string)
+	change := &models.CourseChange{Revision: 1, After: stored}
+	return s.record(ctx, actor, courseID, models.CourseRevisionCreate, change, 0)
+}
+
+func (s *courseRevisionServiceImpl) Update(ctx context.Context, actor services.CourseActor, action, courseID string, match map[string]interface{}, set bson.M, unset ...string) (*models.CourseRevision, error) {
+	change, err := s.courses.Change(ctx, courseID, match, set, unset...)
+	if err != nil {
+		return nil, err
+	}
+	return s.record(ctx, actor, courseID, action, change, 0)
+}
+
+// record - Stores what one write changed under the revision number the write
+// was given; nothing when no tracked field changed
+func (s *courseRevisionServiceImpl) record(ctx context.Context, actor services.CourseActor, courseID, action string, change *models.CourseChange, restoredFrom int) (*models.CourseRevision, error) {
+	var before bson.M
+	if change.Before != nil {
+		before = models.CourseSnapshot(change.Before)
+	}
+	after := models.CourseSnapshot(change.After)
+
+	fields := changedFields(before, after)
+	if change.Before != nil && len(fields) == 0 {
+		return nil, nil
+	}
+
+	revision := &models.CourseRevision{
+		CourseID:     courseID,
+		Number:       change.Revision,
+		Action:       action,
+		AuthorID:     actor.ID,
+		AuthorEmail:  actor.Email,
+		AuthorRole:   actor.Role,
+		RestoredFrom: restoredFrom,
+		Fields:       fields,
+		Snapshot:     after,
+		CreatedAt:    time.Now(),
+		Changes:      fieldChanges(fields, before, after),
+	}
+	if err := s.revisions.Insert(ctx, revision); err != nil {
+		return nil, fmt.Errorf("recording revision %d of course %s: %w", change.Revision, courseID, err)
+	}
+	return revision, nil
+}
+
+func (s *courseRevisionServiceImpl) List(ctx context.Context, courseID string, before, limit int) ([]models.CourseRevision, error) {
+	var course struct{}
+	if err := s.courses.Find(ctx, courseID, nil, &course); err != nil {
+		return nil, err
+	}
+	return s.revisions.List(ctx, courseID, before, int64(limit))
+}
+
+func (s *courseRevisionServiceImpl) Rollback(ctx context.Context, actor services.CourseActor, courseID string, number int) (*models.CourseRevision, error) {
+	var course bson.M
+	if err := s.courses.Find(ctx, courseID, nil, &course); err != nil {
+		return nil, err
+	}
+	target, err := s.revisions.Get(ctx, courseID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := map[string]bool{}
+	for _, field := range models.CourseWorkflowFields {
+		workflow[field] = true
+	}
+
+	set := bson.M{}
+	var unset []string
+	for _, field := range changedFields(models.CourseSnapshot(course), target.Snapshot) {
+		if workflow[field] {
+			continue
+		}
+		if value, ok := target.Snapshot[field]; ok {
+			set[field] = value
+		} else {
+			unset = append(unset, field)
+		}
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return nil, services.ErrRevisionUnchanged
+	}
+
+	set["updatedAt"] = time.Now()
+	change, err := s.courses.Change(ctx, courseID, nil, set, unset...)
+	if err != nil {
+		return nil, err
+	}
+	return s.record(ctx, actor, courseID, models.CourseRevisionRollback, change, number)
+}
+
+// changedFields - The fields that differ between two snapshots, sorted
+func changedFields(before, after bson.M) []string {
+	fields := []string{}
+	for field, value := range after {
+		if previous, ok := before[field]; !ok || !reflect.DeepEqual(previous, value) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// fieldChanges - The fields before and after a revision
+func fieldChanges(fields []string, before, after bson.M) []models.CourseFieldChange {
+	changes := []models.CourseFieldChange{}
+	for _, field := range fields {
+		changes = append(changes, models.CourseFieldChange{Field: field, Before: before[field], After: after[field]})
+	}
+	return changes
+}
@@ -0,0 +1,97 @@
+package services_impl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChangedFields(t *testing.T) {
+	updated := primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name          string
+		before, after bson.M
+		want          []string
+	}{
+		{
+			name:   "created",
+			before: nil,
+			after:  bson.M{"title": "Go", "price": 10.0},
+			want:   []string{"price", "title"},
+		},
+		{
+			name:   "nothing changed",
+			before: bson.M{"title": "Go", "price": 10.0},
+			after:  bson.M{"title": "Go", "price": 10.0},
+			want:   []string{},
+		},
+		{
+			name:   "value changed",
+			before: bson.M{"title": "Go", "price": 10.0},
+			after:  bson.M{"title": "Go", "price": 12.5},
+			want:   []string{"price"},
+		},
+		{
+			name:   "field added",
+			before: bson.M{"title": "Go"},
+			after:  bson.M{"title": "Go", "level": "beginner"},
+			want:   []string{"level"},
+		},
+		{
+			name:   "field removed",
+			before: bson.M{"title": "Go", "level": "beginner"},
+			after:  bson.M{"title": "Go"},
+			want:   []string{"level"},
+		},
+		{
+			name:   "null is not missing",
+			before: bson.M{"title": "Go", "level": nil},
+			after:  bson.M{"title": "Go"},
+			want:   []string{"level"},
+		},
+		{
+			name:   "list reordered",
+			before: bson.M{"tags": bson.A{"go", "backend"}},
+			after:  bson.M{"tags": bson.A{"backend", "go"}},
+			want:   []string{"tags"},
+		},
+		{
+			name:   "nested value changed",
+			before: bson.M{"tutor": bson.M{"name": "Ada", "bio": "Engineer"}},
+			after:  bson.M{"tutor": bson.M{"name": "Ada", "bio": "Mathematician"}},
+			want:   []string{"tutor"},
+		},
+		{
+			name:   "nested value unchanged",
+			before: bson.M{"tutor": bson.M{"name": "Ada"}, "tags": bson.A{"go"}},
+			after:  bson.M{"tutor": bson.M{"name": "Ada"}, "tags": bson.A{"go"}},
+			want:   []string{},
+		},
+		{
+			name:   "several changes sorted",
+			before: bson.M{"title": "Go", "price": 10.0, "level": "beginner"},
+			after:  bson.M{"title": "Go 2", "price": 12.0, "duration": "4 weeks"},
+			want:   []string{"duration", "level", "price", "title"},
+		},
+		{
+			name:   "untracked fields ignored",
+			before: models.CourseSnapshot(bson.M{"title": "Go", "updatedAt": updated, "enrollmentCount": int32(1), models.CourseRevisionField: int32(1)}),
+			after:  models.CourseSnapshot(bson.M{"title": "Go", "updatedAt": primitive.NewDateTimeFromTime(time.Now()), "enrollmentCount": int32(2), models.CourseRevisionField: int32(2)}),
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedFields(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedFields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package services_impl
+
+import (
+	"context"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/search"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// courseSearchFields - What a search looks at, and how much a match counts
+var courseSearchFields = []search.Field{
+	{Name: "title", Weight: 10},
+	{Name: "tags", Weight: 5},
+	{Name: "subject", Weight: 4},
+	{Name: "category", Weight: 4},
+	{Name: "learningGoals", Weight: 3},
+	{Name: "tutor", Weight: 3},
+	{Name: "description", Weight: 2},
+	{Name: "longDescription", Weight: 1},
+}
+
+// courseSearchIndex - The index of the published courses, shared by every
+// request. Course changes show up once it is rebuilt.
+var courseSearchIndex struct {
+	sync.Mutex
+	index   *search.Index
+	courses map[string]bson.M
+	builtAt time.Time
+}
+
+// courseSearchRefresh - How old the search index may get before it is rebuilt
+func courseSearchRefresh() time.Duration {
+	return utils.GetEnvDuration("COURSE_SEARCH_REFRESH", time.Minute)
+}
+
+type courseSearchServiceImpl struct {
+	courses repository.CourseRepository
+}
+
+// NewCourseSearchService - Constructor
+func NewCourseSearchService() services.CourseSearchService {
+	return &courseSearchServiceImpl{
+		courses: repository.Default().Courses,
+	}
+}
+
+func (s *courseSearchServiceImpl) Search(ctx context.Context, query string, offset, limit int) ([]services.CourseSearchHit, int, error) {
+	index, courses, err := s.index(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := index.Search(query)
+	results := []services.CourseSearchHit{}
+	for i := offset; i < len(hits) && i < offset+limit; i++ {
+		results = append(results, services.CourseSearchHit{
+			Course:     courses[hits[i].ID],
+			Score:      math.Round(hits[i].Score*1000) / 1000,
+			Highlights: index.Highlights(hits[i]),
+		})
+	}
+	return results, len(hits), nil
+}
+
+func (s *courseSearchServiceImpl) Suggest(ctx context.Context, prefix string, limit int) ([]services.CourseSuggestion, []string, error) {
+	index, courses, err := s.index(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suggestions := []services.CourseSuggestion{}
+	for _, id := range index.PrefixMatches(prefix, "title", limit) {
+		title, _ := courses[id]["title"].(string)
+		suggestions = append(suggestions, services.CourseSuggestion{ID: id, Title: title})
+	}
+
+	completions := index.Complete(prefix, limit)
+	if completions == nil {
+		completions = []string{}
+	}
+	return suggestions, completions, nil
+}
+
+// index - The shared index, rebuilt when it is too old. If rebuilding fails
+// the old one keeps serving.
+func (s *courseSearchServiceImpl) index(ctx context.Context) (*search.Index, map[string]bson.M, error) {
+	courseSearchIndex.Lock()
+	defer courseSearchIndex.Unlock()
+
+	if courseSearchIndex.index != nil && time.Since(courseSearchIndex.builtAt) < courseSearchRefresh() {
+		return courseSearchIndex.index, courseSearchIndex.courses, nil
+	}
+
+	index, courses, err := s.build(ctx)
+	if err != nil {
+		if courseSearchIndex.index != nil {
+			log.Printf("⚠️ Failed to rebuild the course search index, keeping the old one: %v", err)
+			return courseSearchIndex.index, courseSearchIndex.courses, nil
+		}
+		return nil, nil, err
+	}
+
+	courseSearchIndex.index = index
+	courseSearchIndex.courses = courses
+	courseSearchIndex.builtAt = time.Now()
+	return index, courses, nil
+}
+
+// build - Indexes the published courses, most enrolled first so they win ties
+func (s *courseSearchServiceImpl) build(ctx context.Context) (*search.Index, map[string]bson.M, error) {
+	var list []bson.M
+	err := s.courses.List(ctx, repository.Fields{"status": models.CourseStatusPublished}, repository.Sort{Field: "enrollmentCount", Descending: true}, &list)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	courses := make(map[string]bson.M, len(list))
+	docs := make([]search.Document, 0, len(list))
+	for _, course := range list {
+		id, _ := course["id"].(string)
+		if id == "" {
+			continue
+		}
+		delete(course, "_id")
+		courses[id] = course
+
+		var tutorName []string
+		if tutor, ok := course["tutor"].(bson.M); ok {
+			tutorName = textValues(tutor["name"])
+		}
+		docs = append(docs, search.Document{
+			ID: id,
+			Fields: map[string][]string{
+				"title":           textValues(course["title"]),
+				"tags":            textValues(course["tags"]),
+				"subject":         append(textValues(course["subject"]), textValues(course["subjects"])...),
+				"category":        textValues(course["category"]),
+				"learningGoals":   textValues(course["learningGoals"]),
+				"tutor":           tutorName,
+				"description":     textValues(course["description"]),
+				"longDescription": textValues(course["longDescription"]),
+			},
+		})
+	}
+	return search.NewIndex(courseSearchFields, docs), courses, nil
+}
+
+// textValues - The strings in a document value, which may be a string or an array
+func textValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case primitive.A:
+		var values []string
+		for _, item := range v {
+			values = append(values, textValues(item)...)
+		}
+		return values
+	}
+	return nil
+}
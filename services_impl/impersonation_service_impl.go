@@ -0,0 +1,64 @@
+package services_impl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+)
+
+type impersonationServiceImpl struct{}
+
+// NewImpersonationService - Constructor
+func NewImpersonationService() services.ImpersonationService {
+	return &impersonationServiceImpl{}
+}
+
+func (s *impersonationServiceImpl) Start(ctx context.Context, adminID, adminEmail, studentID, reason, ip, userAgent string) (*models.ImpersonationToken, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, services.ErrImpersonationReason
+	}
+
+	student, err := NewUserService().GetProfile(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if student.DeletionRequestedAt != nil {
+		return nil, services.ErrAccountPendingDeletion
+	}
+
+	token, jti, err := utils.GenerateImpersonationToken(student.ID.Hex(), student.Email, models.RoleStudent, adminID, adminEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	// No audit entry, no token
+	err = NewAuditService().Record(ctx, &models.AuditLog{
+		Action:      models.AuditImpersonationStart,
+		ActorID:     adminID,
+		ActorEmail:  adminEmail,
+		SubjectType: models.AccountTypeStudent,
+		SubjectID:   student.ID.Hex(),
+		Reason:      reason,
+		TokenID:     jti,
+		IP:          ip,
+		UserAgent:   userAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := utils.ImpersonationTTL()
+	return &models.ImpersonationToken{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresIn: int64(ttl.Seconds()),
+		ExpiresAt: time.Now().Add(ttl),
+		UserID:    student.ID.Hex(),
+		UserEmail: student.Email,
+	}, nil
+}
@@ -0,0 +1,175 @@
+package services_impl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type loginGuardServiceImpl struct {
+	attempts repository.LoginAttemptRepository
+}
+
+// NewLoginGuardService - Constructor
+func NewLoginGuardService() services.LoginGuardService {
+	return &loginGuardServiceImpl{
+		attempts: repository.Default().LoginAttempts,
+	}
+}
+
+// Tuning, all overridable through the environment. An account is locked after
+// LOGIN_MAX_FAILURES failures inside LOGIN_ATTEMPT_WINDOW; an IP after
+// LOGIN_IP_MAX_FAILURES. Each further lockout doubles, up to LOGIN_LOCKOUT_MAX.
+func loginMaxFailures() int {
+	return utils.GetEnvInt("LOGIN_MAX_FAILURES", 5)
+}
+
+func loginIPMaxFailures() int {
+	return utils.GetEnvInt("LOGIN_IP_MAX_FAILURES", 20)
+}
+
+func loginAttemptWindow() time.Duration {
+	return utils.GetEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute)
+}
+
+func loginLockoutBase() time.Duration {
+	return utils.GetEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute)
+}
+
+func loginLockoutMax() time.Duration {
+	return utils.GetEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour)
+}
+
+// lockoutDuration - base * 2^previousLockouts, capped
+func lockoutDuration(previousLockouts int) time.Duration {
+	duration := loginLockoutBase()
+	for i := 0; i < previousLockouts && duration < loginLockoutMax(); i++ {
+		duration *= 2
+	}
+	if duration > loginLockoutMax() {
+		duration = loginLockoutMax()
+	}
+	return duration
+}
+
+// attemptCounter - One key the guard counts failures under
+type attemptCounter struct {
+	key         string
+	kind        string
+	accountType string
+	identifier  string
+	maxFailures int
+}
+
+func accountCounter(accountType, email string) attemptCounter {
+	email = strings.ToLower(strings.TrimSpace(email))
+	return attemptCounter{
+		key:         models.LoginAttemptAccount + ":" + accountType + ":" + email,
+		kind:        models.LoginAttemptAccount,
+		accountType: accountType,
+		identifier:  email,
+		maxFailures: loginMaxFailures(),
+	}
+}
+
+func ipCounter(ip string) attemptCounter {
+	return attemptCounter{
+		key:         models.LoginAttemptIP + ":" + ip,
+		kind:        models.LoginAttemptIP,
+		identifier:  ip,
+		maxFailures: loginIPMaxFailures(),
+	}
+}
+
+func counters(accountType, email, ip string) []attemptCounter {
+	list := []attemptCounter{accountCounter(accountType, email)}
+	if ip != "" {
+		list = append(list, ipCounter(ip))
+	}
+	return list
+}
+
+func keysOf(list []attemptCounter) []string {
+	keys := make([]string, len(list))
+	for i, counter := range list {
+		keys[i] = counter.key
+	}
+	return keys
+}
+
+func (s *loginGuardServiceImpl) Check(ctx context.Context, accountType, email, ip string) (time.Duration, error) {
+	now := time.Now()
+	locked, err := s.attempts.ListLocked(ctx, keysOf(counters(accountType, email, ip)), now)
+	if err != nil {
+		return 0, err
+	}
+
+	var remaining time.Duration
+	for _, attempt := range locked {
+		if left := attempt.LockedUntil.Sub(now); left > remaining {
+			remaining = left
+		}
+	}
+
+	if remaining > 0 {
+		return remaining, services.ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+func (s *loginGuardServiceImpl) RecordFailure(ctx context.Context, accountType, email, ip string) error {
+	for _, counter := range counters(accountType, email, ip) {
+		if err := s.recordFailure(ctx, counter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *loginGuardServiceImpl) recordFailure(ctx context.Context, counter attemptCounter) error {
+	now := time.Now()
+
+	attempt, err := s.attempts.AddFailure(ctx, &models.LoginAttempt{
+		Key:         counter.key,
+		Kind:        counter.kind,
+		AccountType: counter.accountType,
+		Identifier:  counter.identifier,
+	}, now, now.Add(loginAttemptWindow()))
+	if err != nil {
+		return err
+	}
+
+	if attempt.Failures < counter.maxFailures {
+		return nil
+	}
+
+	// Limit reached: lock out and start counting again. Matching on the
+	// failure count makes sure only one instance applies the lockout.
+	lockedUntil := now.Add(lockoutDuration(attempt.Lockouts))
+	return s.attempts.Lock(ctx, attempt, lockedUntil, lockedUntil.Add(loginAttemptWindow()))
+}
+
+func (s *loginGuardServiceImpl) RecordSuccess(ctx context.Context, accountType, email string) error {
+	// The IP counter is left alone, otherwise one valid login would reset an
+	// attacker's count against every other account
+	return s.attempts.DeleteByKey(ctx, accountCounter(accountType, email).key)
+}
+
+func (s *loginGuardServiceImpl) List(ctx context.Context, all bool) ([]models.LoginAttempt, error) {
+	return s.attempts.List(ctx, all, time.Now())
+}
+
+func (s *loginGuardServiceImpl) Clear(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return services.ErrLockoutNotFound
+	}
+	return s.attempts.Delete(ctx, objectID)
+}
@@ -0,0 +1,332 @@
+package services_impl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const recoveryCodeCount = 10
+
+type mfaServiceImpl struct{}
+
+// NewMFAService - Constructor
+func NewMFAService() services.MFAService {
+	return &mfaServiceImpl{}
+}
+
+// mfaIssuer - Name shown in authenticator apps
+func mfaIssuer() string {
+	return utils.GetEnv("MFA_ISSUER", "JaroMind")
+}
+
+// requireAdminMFA - When true, admins must enroll before they can log in
+func requireAdminMFA() bool {
+	return utils.GetEnvBool("REQUIRE_ADMIN_MFA", false)
+}
+
+func (s *mfaServiceImpl) IsRequired(accountType string) bool {
+	return accountType == models.AccountTypeAdmin && requireAdminMFA()
+}
+
+// load - The account and its two-factor settings (empty before enrollment)
+func (s *mfaServiceImpl) load(ctx context.Context, accountType, accountID string) (repository.AccountRepository, *repository.Account, error) {
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, nil, services.ErrAccountNotFound
+	}
+
+	account, err := accounts.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if account.MFA == nil {
+		account.MFA = &models.MFASettings{}
+	}
+	return accounts, account, nil
+}
+
+func (s *mfaServiceImpl) BeginEnrollment(ctx context.Context, accountType, accountID string) (*models.MFAEnrollment, error) {
+	accounts, account, err := s.load(ctx, accountType, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.MFA.Enabled {
+		return nil, services.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := accounts.BeginMFA(ctx, account.ID, secret, hashRecoveryCodes(codes)); err != nil {
+		return nil, err
+	}
+
+	return &models.MFAEnrollment{
+		Secret:        secret,
+		OTPAuthURI:    utils.TOTPAuthURI(mfaIssuer(), account.Email, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *mfaServiceImpl) ConfirmEnrollment(ctx context.Context, accountType, accountID, code string) error {
+	accounts, account, err := s.load(ctx, accountType, accountID)
+	if err != nil {
+		return err
+	}
+	if account.MFA.Enabled {
+		return services.ErrMFAAlreadyEnabled
+	}
+	if account.MFA.PendingSecret == "" {
+		return services.ErrMFANotEnrolling
+	}
+
+	step, ok := utils.ValidateTOTP(account.MFA.PendingSecret, code, time.Now())
+	if !ok {
+		return services.ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	return accounts.SetMFA(ctx, account.ID, &models.MFASettings{
+		Enabled:            true,
+		Secret:             account.MFA.PendingSecret,
+		RecoveryCodeHashes: account.MFA.PendingRecoveryCodeHashes,
+		LastUsedStep:       step,
+		EnabledAt:          &now,
+	}, now)
+}
+
+func (s *mfaServiceImpl) Disable(ctx context.Context, accountType, accountID, code string) error {
+	if s.IsRequired(accountType) {
+		return services.ErrMFARequired
+	}
+
+	if err := s.Verify(ctx, accountType, accountID, code); err != nil {
+		return err
+	}
+
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return err
+	}
+	objectID, _ := primitive.ObjectIDFromHex(accountID)
+	return accounts.SetMFA(ctx, objectID, nil, time.Now())
+}
+
+func (s *mfaServiceImpl) Verify(ctx context.Context, accountType, accountID, code string) error {
+	accounts, account, err := s.load(ctx, accountType, accountID)
+	if err != nil {
+		return err
+	}
+	if !account.MFA.Enabled {
+		return services.ErrMFANotEnabled
+	}
+
+	if step, ok := utils.ValidateTOTP(account.MFA.Secret, code, time.Now()); ok {
+		// Accept each time step once, so an observed code can't be replayed
+		used, err := accounts.UseMFAStep(ctx, account.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return services.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	// Fall back to a recovery code, which is consumed on use
+	used, err := accounts.UseRecoveryCode(ctx, account.ID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return services.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *mfaServiceImpl) IsEnabled(ctx context.Context, accountType, accountID string) (bool, error) {
+	_, account, err := s.load(ctx, accountType, accountID)
+	if err != nil {
+		return false, err
+	}
+	return account.MFA.Enabled, nil
+}
+
+func hashRecoveryCodes(codes []string) []string {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+	return hashes
+}
+
+// -------- TWO-STEP LOGIN --------
+
+func (s *mfaServiceImpl) StartLogin(ctx context.Context, accountType, accountID, email, role string) (*models.LoginResult, error) {
+	enabled, err := s.IsEnabled(ctx, accountType, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if enabled || s.IsRequired(accountType) {
+		challenge, err := utils.GenerateMFAChallengeToken(accountType, accountID, email, role, !enabled)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResult{
+			MFARequired:       true,
+			MFAEnrollRequired: !enabled,
+			MFAToken:          challenge,
+		}, nil
+	}
+
+	tokens, err := NewSessionService().Create(ctx, accountType, accountID, email, role)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{Tokens: tokens}, nil
+}
+
+// mfaChallenge - Claims of a validated challenge token
+type mfaChallenge struct {
+	accountType string
+	accountID   string
+	email       string
+	role        string
+	enroll      bool
+	jti         string
+	issuedAt    time.Time
+	expiresAt   time.Time
+}
+
+func parseMFAChallenge(token string) (*mfaChallenge, error) {
+	claims, err := utils.ValidateMFAChallengeToken(token)
+	if err != nil {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+
+	challenge := &mfaChallenge{}
+	challenge.accountType, _ = claims["acct"].(string)
+	challenge.accountID, _ = claims["sub"].(string)
+	challenge.email, _ = claims["email"].(string)
+	challenge.role, _ = claims["role"].(string)
+	challenge.enroll, _ = claims["enroll"].(bool)
+	challenge.jti, _ = claims["jti"].(string)
+	if challenge.accountType == "" || challenge.accountID == "" || challenge.jti == "" {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+	challenge.issuedAt = issuedAt.Time
+	challenge.expiresAt = expiresAt.Time
+	return challenge, nil
+}
+
+func (s *mfaServiceImpl) EnrollWithChallenge(ctx context.Context, challengeToken string) (*models.MFAEnrollment, error) {
+	challenge, err := parseMFAChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.enroll {
+		return nil, services.ErrMFAAlreadyEnabled
+	}
+	return s.BeginEnrollment(ctx, challenge.accountType, challenge.accountID)
+}
+
+func (s *mfaServiceImpl) CompleteLogin(ctx context.Context, challengeToken, code string) (*models.TokenPair, error) {
+	challenge, err := parseMFAChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Code guesses count against the same per-account limit as passwords
+	guard := NewLoginGuardService()
+	if _, err := guard.Check(ctx, challenge.accountType, challenge.email, ""); err != nil {
+		return nil, err
+	}
+
+	// A challenge completes one login. Checked up front so a replay doesn't
+	// use up the code sent with it; claimed for good once the code is right.
+	revoked := repository.Default().RevokedTokens
+	used, err := revoked.Contains(ctx, challenge.jti)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+
+	// The account may have been deactivated, marked for deletion or given a
+	// new password since the password step
+	invalid, err := TokenInvalidForAccount(ctx, challenge.accountType, challenge.accountID, challenge.issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if invalid {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+
+	if challenge.enroll {
+		err = s.ConfirmEnrollment(ctx, challenge.accountType, challenge.accountID, code)
+	} else {
+		err = s.Verify(ctx, challenge.accountType, challenge.accountID, code)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			if recordErr := guard.RecordFailure(ctx, challenge.accountType, challenge.email, ""); recordErr != nil {
+				log.Printf("⚠️ Failed to record two-factor failure: %v", recordErr)
+			}
+		}
+		return nil, err
+	}
+
+	// Only one of concurrent logins with the same challenge gets through
+	first, err := revoked.Add(ctx, &models.RevokedToken{
+		JTI:       challenge.jti,
+		Reason:    "two-factor challenge used",
+		ExpiresAt: challenge.expiresAt,
+		RevokedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, services.ErrInvalidMFAChallenge
+	}
+
+	tokens, err := NewSessionService().Create(ctx, challenge.accountType, challenge.accountID, challenge.email, challenge.role)
+	if err != nil {
+		return nil, err
+	}
+	if err := guard.RecordSuccess(ctx, challenge.accountType, challenge.email); err != nil {
+		log.Printf("⚠️ Failed to reset login counter: %v", err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,222 @@
+package services_impl
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/oidc"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type oidcServiceImpl struct {
+	states   repository.OIDCStateRepository
+	students repository.UserRepository
+}
+
+// NewOIDCService - Constructor
+func NewOIDCService() services.OIDCService {
+	store := repository.Default()
+	return &oidcServiceImpl{
+		states:   store.OIDCStates,
+		students: store.Users,
+	}
+}
+
+// oidcStateTTL - How long the user has to finish signing in at the provider
+func oidcStateTTL() time.Duration {
+	return utils.GetEnvDuration("OIDC_STATE_TTL", 10*time.Minute)
+}
+
+func (s *oidcServiceImpl) Providers() []string {
+	return oidc.Names()
+}
+
+func (s *oidcServiceImpl) Begin(ctx context.Context, providerName string) (*models.OIDCAuthorization, error) {
+	provider, ok := oidc.Get(providerName)
+	if !ok {
+		return nil, services.ErrUnknownProvider
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	browserNonce, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("⚠️ OIDC provider %s unavailable: %v", provider.Config.Name, err)
+		return nil, services.ErrOIDCLoginFailed
+	}
+
+	now := time.Now()
+	pending := models.OIDCState{
+		StateHash:        utils.HashToken(state),
+		BrowserNonceHash: utils.HashToken(browserNonce),
+		Provider:         provider.Config.Name,
+		Nonce:            nonce,
+		CodeVerifier:     verifier,
+		ExpiresAt:        now.Add(oidcStateTTL()),
+		CreatedAt:        now,
+	}
+	if err := s.states.Insert(ctx, &pending); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorization{
+		URL:          authURL,
+		BrowserNonce: browserNonce,
+		ExpiresAt:    pending.ExpiresAt,
+	}, nil
+}
+
+func (s *oidcServiceImpl) Complete(ctx context.Context, providerName, code, state, browserNonce string) (*models.LoginResult, error) {
+	provider, ok := oidc.Get(providerName)
+	if !ok {
+		return nil, services.ErrUnknownProvider
+	}
+	if code == "" || state == "" || browserNonce == "" {
+		return nil, services.ErrInvalidOIDCState
+	}
+
+	// Deleting on read makes each state single-use
+	pending, err := s.states.Take(ctx, utils.HashToken(state), provider.Config.Name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The callback must come back to the browser that started the login,
+	// otherwise an attacker could sign a victim into the attacker's account
+	if subtle.ConstantTimeCompare([]byte(pending.BrowserNonceHash), []byte(utils.HashToken(browserNonce))) != 1 {
+		return nil, services.ErrInvalidOIDCState
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		log.Printf("⚠️ OIDC login with %s failed: %v", provider.Config.Name, err)
+		return nil, services.ErrOIDCLoginFailed
+	}
+
+	student, err := s.linkStudent(ctx, provider.Config.Name, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMFAService().StartLogin(ctx, models.AccountTypeStudent, student.ID.Hex(), student.Email, models.RoleStudent)
+}
+
+// linkStudent - The student already linked to this identity, else the student
+// with the same (verified) email, else a new student
+func (s *oidcServiceImpl) linkStudent(ctx context.Context, provider string, identity *oidc.Identity) (*models.User, error) {
+	student, err := s.students.FindByIdentity(ctx, provider, identity.Subject)
+	if err == nil {
+		if student.DeletionRequestedAt != nil {
+			return nil, services.ErrAccountPendingDeletion
+		}
+		return student, nil
+	}
+	if err != services.ErrUserNotFound {
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider vouches for it
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, services.ErrOIDCEmailNotVerified
+	}
+
+	now := time.Now()
+	link := models.ExternalIdentity{
+		Provider: provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		LinkedAt: now,
+	}
+
+	student, err = s.students.FindByEmailFold(ctx, identity.Email)
+	if err == services.ErrUserNotFound {
+		return s.createStudent(ctx, identity, link)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if student.DeletionRequestedAt != nil {
+		return nil, services.ErrAccountPendingDeletion
+	}
+
+	set := bson.M{"updated_at": now}
+	var unset []string
+	if !student.Verified {
+		// Nobody has proven they own this email yet, so the password may have
+		// been set by someone else. The provider just proved ownership: verify
+		// the account and drop that password and any sessions it started.
+		set = bson.M{
+			"verified":            true,
+			"verified_at":         now,
+			"password":            "",
+			"password_changed_at": now,
+			"updated_at":          now,
+		}
+		unset = []string{"code", "code_expires_at"}
+	}
+
+	if err := s.students.AddIdentity(ctx, student.ID, link, set, unset...); err != nil {
+		return nil, err
+	}
+	if !student.Verified {
+		if err := NewSessionService().RevokeAllForAccount(ctx, models.AccountTypeStudent, student.ID.Hex(), "email claimed via "+provider); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("🔗 Linked %s identity to student %s", provider, student.ID.Hex())
+	return student, nil
+}
+
+// createStudent - New account for a first-time social login. It has no
+// password until the student sets one through /forgot-password.
+func (s *oidcServiceImpl) createStudent(ctx context.Context, identity *oidc.Identity, link models.ExternalIdentity) (*models.User, error) {
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	now := time.Now()
+	student := models.User{
+		ID:         primitive.NewObjectID(),
+		Name:       name,
+		Email:      identity.Email,
+		Verified:   true,
+		VerifiedAt: &now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Identities: []models.ExternalIdentity{link},
+	}
+	if strings.HasPrefix(identity.Picture, "https://") {
+		student.Avatar = identity.Picture
+	}
+
+	if err := s.students.Insert(ctx, &student); err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
@@ -0,0 +1,158 @@
+package services_impl
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/mailer"
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type passwordResetServiceImpl struct {
+	resets repository.PasswordResetRepository
+	mailer mailer.Mailer
+}
+
+// NewPasswordResetService - Constructor
+func NewPasswordResetService() services.PasswordResetService {
+	return &passwordResetServiceImpl{
+		resets: repository.Default().PasswordResets,
+		mailer: mailer.Default(),
+	}
+}
+
+// passwordResetTTL - How long an emailed reset token stays valid
+func passwordResetTTL() time.Duration {
+	return utils.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+}
+
+// passwordResetURL - Frontend page that accepts ?token=..., per account type
+func passwordResetURL(accountType string) string {
+	switch accountType {
+	case models.AccountTypeAdmin:
+		return utils.GetEnv("ADMIN_PASSWORD_RESET_URL", "")
+	case models.AccountTypeTutor:
+		return utils.GetEnv("TUTOR_PASSWORD_RESET_URL", utils.GetEnv("PASSWORD_RESET_URL", ""))
+	}
+	return utils.GetEnv("PASSWORD_RESET_URL", "")
+}
+
+func (s *passwordResetServiceImpl) RequestReset(ctx context.Context, accountType, email string) error {
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return err
+	}
+
+	account, err := accounts.FindByEmail(ctx, strings.TrimSpace(email))
+	if err == services.ErrAccountNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !account.IsActive {
+		return nil
+	}
+
+	token, err := s.issue(ctx, accountType, account, passwordResetTTL())
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.PasswordResetEmail(account.Email, account.Name, token, passwordResetURL(accountType), passwordResetTTL())
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("⚠️ Failed to send password reset email to %s: %v", account.Email, err)
+		return err
+	}
+	return nil
+}
+
+// invitationTTL - How long an invitation's set-password token stays valid
+func invitationTTL() time.Duration {
+	return utils.GetEnvDuration("INVITATION_TTL", 72*time.Hour)
+}
+
+func (s *passwordResetServiceImpl) SendInvitation(ctx context.Context, accountType, email, invitedBy string) error {
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return err
+	}
+
+	account, err := accounts.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issue(ctx, accountType, account, invitationTTL())
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.InvitationEmail(account.Email, account.Name, invitedBy, token, passwordResetURL(accountType), invitationTTL())
+	return s.mailer.Send(msg)
+}
+
+// issue invalidates any outstanding tokens for the account and stores a new one
+func (s *passwordResetServiceImpl) issue(ctx context.Context, accountType string, account *repository.Account, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := s.resets.InvalidateAll(ctx, accountType, account.ID, now); err != nil {
+		return "", err
+	}
+
+	err = s.resets.Insert(ctx, &models.PasswordReset{
+		TokenHash:   utils.HashToken(token),
+		AccountType: accountType,
+		AccountID:   account.ID,
+		Email:       account.Email,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *passwordResetServiceImpl) ResetPassword(ctx context.Context, accountType, token, newPassword string) error {
+	accounts, err := accountsFor(accountType)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	// Claim the token atomically so it can only be redeemed once
+	reset, err := s.resets.Claim(ctx, utils.HashToken(strings.TrimSpace(token)), accountType, now)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := accounts.SetPassword(ctx, reset.AccountID, string(hashedPassword), now); err != nil {
+		return err
+	}
+
+	// Access tokens die through the password-changed marker; refresh tokens die with their sessions
+	if err := NewSessionService().RevokeAllForAccount(ctx, accountType, reset.AccountID.Hex(), "password reset"); err != nil {
+		return err
+	}
+
+	// Any other outstanding tokens for this account are now pointless
+	return s.resets.InvalidateAll(ctx, accountType, reset.AccountID, now)
+}
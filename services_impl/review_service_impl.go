@@ -5,26 +5,25 @@ import (
     "errors"
     "time"
 	"fmt"
-    "github.com/AbaraEmmanuel/jaromind-backend/database"
     "github.com/AbaraEmmanuel/jaromind-backend/models"
+    "github.com/AbaraEmmanuel/jaromind-backend/repository"
+    "github.com/AbaraEmmanuel/jaromind-backend/services"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
-    "go.mongodb.org/mongo-driver/mongo"
-    "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ReviewServiceImpl implements the ReviewService interface
 type ReviewServiceImpl struct {
-    reviewCollection *mongo.Collection
-    courseCollection *mongo.Collection
+    reviews repository.ReviewRepository
+    courses repository.CourseRepository
 }
 
 // NewReviewServiceImpl creates a new review service implementation
 func NewReviewServiceImpl() *ReviewServiceImpl {
-    db := database.GetDB()
+    store := repository.Default()
     return &ReviewServiceImpl{
-        reviewCollection: db.Collection("reviews"),
-        courseCollection: db.Collection("courses"),
+        reviews: store.Reviews,
+        courses: store.Courses,
     }
 }
 
@@ -57,13 +56,20 @@ func (s *ReviewServiceImpl) CreateReview(ctx context.Context, review *models.Rev
     fmt.Printf("Inserting review into database...\n")
     
     // Insert review
-    result, err := s.reviewCollection.InsertOne(ctx, review)
+    err = s.reviews.Insert(ctx, review)
+    if errors.Is(err, services.ErrReviewExists) {
+        // A concurrent request created the review first - update that one
+        existingReview, err := s.GetReviewByUserAndCourse(ctx, review.UserID.Hex(), review.CourseID)
+        if err != nil || existingReview == nil {
+            return nil, services.ErrReviewExists
+        }
+        return s.UpdateReview(ctx, existingReview.ID.Hex(), review)
+    }
     if err != nil {
         fmt.Printf("Error inserting review: %v\n", err)
         return nil, err
     }
     
-    review.ID = result.InsertedID.(primitive.ObjectID)
     fmt.Printf("Review inserted with ID: %v\n", review.ID)
     
     // Update course rating
@@ -84,27 +90,11 @@ func (s *ReviewServiceImpl) GetReviewsByCourseID(ctx context.Context, courseID s
     fmt.Printf("Course ID received: %s\n", courseID)
     
     // Since CourseID is stored as a string (UUID), query directly as string
-    filter := bson.M{"course_id": courseID}
-    opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-    
-    fmt.Printf("Querying with filter: %+v\n", filter)
-    
-    cursor, err := s.reviewCollection.Find(ctx, filter, opts)
+    reviews, err := s.reviews.ListByCourse(ctx, courseID, 0)
     if err != nil {
         fmt.Printf("❌ Error querying reviews: %v\n", err)
         return nil, err
     }
-    defer cursor.Close(ctx)
-    
-    var reviews []models.Review
-    if err := cursor.All(ctx, &reviews); err != nil {
-        fmt.Printf("❌ Error decoding reviews: %v\n", err)
-        return nil, err
-    }
-    
-    if reviews == nil {
-        reviews = []models.Review{}
-    }
     
     fmt.Printf("✅ Found %d reviews\n", len(reviews))
     fmt.Println("=== GET REVIEWS - SUCCESS ===")
@@ -119,18 +109,7 @@ func (s *ReviewServiceImpl) GetReviewByID(ctx context.Context, reviewID string)
         return nil, errors.New("invalid review ID")
     }
 
-    var review models.Review
-    filter := bson.M{"_id": objectID}
-
-    err = s.reviewCollection.FindOne(ctx, filter).Decode(&review)
-    if err != nil {
-        if err == mongo.ErrNoDocuments {
-            return nil, errors.New("review not found")
-        }
-        return nil, err
-    }
-
-    return &review, nil
+    return s.reviews.Get(ctx, objectID)
 }
 
 // UpdateReview updates an existing review
@@ -142,22 +121,8 @@ func (s *ReviewServiceImpl) UpdateReview(ctx context.Context, reviewID string, r
 
     review.UpdatedAt = time.Now()
 
-    update := bson.M{
-        "$set": bson.M{
-            "rating":     review.Rating,
-            "comment":    review.Comment,
-            "updated_at": review.UpdatedAt,
-        },
-    }
-
-    opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-    result := s.reviewCollection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts)
-
-    var updatedReview models.Review
-    if err := result.Decode(&updatedReview); err != nil {
-        if err == mongo.ErrNoDocuments {
-            return nil, errors.New("review not found")
-        }
+    updatedReview, err := s.reviews.Update(ctx, objectID, review.Rating, review.Comment, review.UpdatedAt)
+    if err != nil {
         return nil, err
     }
 
@@ -166,7 +131,7 @@ func (s *ReviewServiceImpl) UpdateReview(ctx context.Context, reviewID string, r
         // Log error but don't fail the update
     }
 
-    return &updatedReview, nil
+    return updatedReview, nil
 }
 
 // DeleteReview deletes a review
@@ -182,15 +147,10 @@ func (s *ReviewServiceImpl) DeleteReview(ctx context.Context, reviewID string) e
         return err
     }
 
-    result, err := s.reviewCollection.DeleteOne(ctx, bson.M{"_id": objectID})
-    if err != nil {
+    if err := s.reviews.Delete(ctx, objectID); err != nil {
         return err
     }
 
-    if result.DeletedCount == 0 {
-        return errors.New("review not found")
-    }
-
     // Update course rating
     if err := s.updateCourseRating(ctx, review.CourseID); err != nil {
         // Log error but don't fail the deletion
@@ -211,17 +171,9 @@ func (s *ReviewServiceImpl) GetReviewByUserAndCourse(ctx context.Context, userID
     }
 
     // Query with string course_id directly
-    filter := bson.M{
-        "user_id":   userObjectID,
-        "course_id": courseID,  // ✅ Use string directly
-    }
-    
-    fmt.Printf("Filter: %+v\n", filter)
-
-    var review models.Review
-    err = s.reviewCollection.FindOne(ctx, filter).Decode(&review)
+    review, err := s.reviews.FindByUserAndCourse(ctx, userObjectID, courseID)
     if err != nil {
-        if err == mongo.ErrNoDocuments {
+        if errors.Is(err, services.ErrReviewNotFound) {
             fmt.Println("ℹ️ No existing review found")
             return nil, nil // No existing review found (not an error)
         }
@@ -230,7 +182,7 @@ func (s *ReviewServiceImpl) GetReviewByUserAndCourse(ctx context.Context, userID
     }
 
     fmt.Printf("✅ Found existing review: %v\n", review.ID)
-    return &review, nil
+    return review, nil
 }
 
 // CalculateCourseRating calculates average rating for a course
@@ -238,43 +190,19 @@ func (s *ReviewServiceImpl) CalculateCourseRating(ctx context.Context, courseID
     fmt.Println("\n=== CALCULATE COURSE RATING ===")
     fmt.Printf("Course ID: %s\n", courseID)
     
-    // Query with string course_id directly
-    filter := bson.M{"course_id": courseID}
-    
-    // Aggregate pipeline to calculate average rating
-    pipeline := mongo.Pipeline{
-        {{Key: "$match", Value: filter}},
-        {{Key: "$group", Value: bson.M{
-            "_id":          nil,
-            "avgRating":    bson.M{"$avg": "$rating"},
-            "totalReviews": bson.M{"$sum": 1},
-        }}},
-    }
-
-    cursor, err := s.reviewCollection.Aggregate(ctx, pipeline)
+    avgRating, totalReviews, err := s.reviews.Rating(ctx, courseID)
     if err != nil {
         fmt.Printf("❌ Error aggregating: %v\n", err)
         return 0, 0, err
     }
-    defer cursor.Close(ctx)
-
-    var result []struct {
-        AvgRating    float64 `bson:"avgRating"`
-        TotalReviews int     `bson:"totalReviews"`
-    }
-
-    if err := cursor.All(ctx, &result); err != nil {
-        fmt.Printf("❌ Error decoding results: %v\n", err)
-        return 0, 0, err
-    }
 
-    if len(result) == 0 {
+    if totalReviews == 0 {
         fmt.Println("ℹ️ No reviews found for this course")
         return 0, 0, nil
     }
 
-    fmt.Printf("✅ Average rating: %.2f, Total reviews: %d\n", result[0].AvgRating, result[0].TotalReviews)
-    return result[0].AvgRating, result[0].TotalReviews, nil
+    fmt.Printf("✅ Average rating: %.2f, Total reviews: %d\n", avgRating, totalReviews)
+    return avgRating, totalReviews, nil
 }
 
 // updateCourseRating updates the course document with new rating
@@ -284,26 +212,9 @@ func (s *ReviewServiceImpl) updateCourseRating(ctx context.Context, courseID str
         return err
     }
 
-    // Try to update by ID (string/UUID) first
-    update := bson.M{
-        "$set": bson.M{
-            "rating":       avgRating,
-            "review_count": totalReviews,
-            "updated_at":   time.Now(),
-        },
-    }
-
-    // Try to update with courseID as string first (for UUIDs)
-    _, err = s.courseCollection.UpdateOne(ctx, bson.M{"id": courseID}, update)
-    if err == nil {
-        return nil
-    }
-
-    // If that failed, try to convert to ObjectID
-    if objID, err2 := primitive.ObjectIDFromHex(courseID); err2 == nil {
-        _, err = s.courseCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-        return err
-    }
-
-    return errors.New("could not update course rating - invalid course ID format")
-}
\ No newline at end of file
+    return s.courses.Update(ctx, courseID, nil, bson.M{
+        "rating":      avgRating,
+        "reviewCount": totalReviews,
+        "updatedAt":   time.Now(),
+    })
+}
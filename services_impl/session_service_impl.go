@@ -0,0 +1,195 @@
+package services_impl
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+	"github.com/AbaraEmmanuel/jaromind-backend/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxRotatedTokenHashes bounds how many old refresh token hashes a session
+// remembers for reuse detection
+const maxRotatedTokenHashes = 20
+
+type sessionServiceImpl struct {
+	sessions repository.SessionRepository
+	revoked  repository.RevokedTokenRepository
+}
+
+// NewSessionService - Constructor
+func NewSessionService() services.SessionService {
+	store := repository.Default()
+	return &sessionServiceImpl{
+		sessions: store.Sessions,
+		revoked:  store.RevokedTokens,
+	}
+}
+
+// refreshTokenTTL - Lifetime of a session's refresh token (REFRESH_TOKEN_TTL, default 30 days)
+func refreshTokenTTL() time.Duration {
+	return utils.GetEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+func (s *sessionServiceImpl) Create(ctx context.Context, accountType, accountID, email, role string) (*models.TokenPair, error) {
+	refreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		ID:               primitive.NewObjectID(),
+		AccountType:      accountType,
+		AccountID:        accountID,
+		Email:            email,
+		Role:             role,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		ExpiresAt:        now.Add(refreshTokenTTL()),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.sessions.Insert(ctx, &session); err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(&session, refreshToken)
+}
+
+func (s *sessionServiceImpl) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	hash := utils.HashToken(refreshToken)
+	now := time.Now()
+
+	newRefreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swap the current hash for the new one in a single step so two
+	// concurrent refreshes with the same token can't both succeed
+	session, err := s.sessions.Rotate(ctx, hash, utils.HashToken(newRefreshToken), maxRotatedTokenHashes, now)
+	if err == services.ErrSessionNotFound {
+		// A rotated-out token being replayed means it leaked: kill the session
+		_ = s.sessions.RevokeRotated(ctx, hash, "refresh token reuse", now)
+		return nil, services.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(session, newRefreshToken)
+}
+
+func (s *sessionServiceImpl) tokenPair(session *models.Session, refreshToken string) (*models.TokenPair, error) {
+	accessToken, _, err := utils.GenerateAccessToken(session.AccountID, session.Email, session.Role, session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+		SessionID:    session.ID.Hex(),
+	}, nil
+}
+
+func (s *sessionServiceImpl) Logout(ctx context.Context, jti, sessionID string, tokenExpiresAt time.Time) error {
+	if jti != "" {
+		if err := s.RevokeToken(ctx, jti, "logout", tokenExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	if sessionID == "" {
+		return nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil
+	}
+
+	err = s.sessions.Revoke(ctx, objectID, nil, "logout", time.Now())
+	if err == services.ErrSessionNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *sessionServiceImpl) RevokeToken(ctx context.Context, jti, reason string, tokenExpiresAt time.Time) error {
+	_, err := s.revoked.Add(ctx, &models.RevokedToken{
+		JTI:       jti,
+		Reason:    reason,
+		ExpiresAt: tokenExpiresAt,
+		RevokedAt: time.Now(),
+	})
+	return err
+}
+
+func (s *sessionServiceImpl) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.revoked.Contains(ctx, jti)
+}
+
+func (s *sessionServiceImpl) RevokeAllForAccount(ctx context.Context, accountType, accountID, reason string) error {
+	return s.sessions.RevokeAll(ctx, accountType, accountID, reason, time.Now())
+}
+
+// lastSeenResolution - Touch only writes when lastSeenAt is older than this
+const lastSeenResolution = time.Minute
+
+// maxUserAgentLength - User agents are client-controlled, so they are capped
+const maxUserAgentLength = 512
+
+func (s *sessionServiceImpl) ListForAccount(ctx context.Context, accountType, accountID string) ([]models.Session, error) {
+	return s.sessions.ListActive(ctx, accountType, accountID, time.Now())
+}
+
+func (s *sessionServiceImpl) RevokeForAccount(ctx context.Context, accountType, accountID, sessionID, reason string) error {
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return services.ErrSessionNotFound
+	}
+
+	owner := repository.Fields{"accountType": accountType, "accountId": accountID}
+	return s.sessions.Revoke(ctx, objectID, owner, reason, time.Now())
+}
+
+func (s *sessionServiceImpl) Touch(ctx context.Context, sessionID, userAgent, ip string) error {
+	objectID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return services.ErrSessionTerminated
+	}
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session, err := s.sessions.Find(ctx, objectID)
+	if err == services.ErrSessionNotFound {
+		return services.ErrSessionTerminated
+	}
+	if err != nil {
+		return err
+	}
+	if session.RevokedAt != nil {
+		return services.ErrSessionTerminated
+	}
+
+	now := time.Now()
+	stale := session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) > lastSeenResolution
+	if !stale && session.UserAgent == userAgent && session.IP == ip {
+		return nil
+	}
+
+	// The session is valid; failing to record the device doesn't change that
+	if err := s.sessions.SetDevice(ctx, objectID, userAgent, ip, now); err != nil {
+		log.Printf("⚠️ Could not record the device of session %s: %v", sessionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package services_impl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
+	"github.com/AbaraEmmanuel/jaromind-backend/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// profileSyncActor - Who copies of a tutor's profile onto their courses are recorded as
+var profileSyncActor = services.CourseActor{ID: "tutor-profile", Role: "system"}
+
+type tutorServiceImpl struct {
+	tutors  repository.TutorRepository
+	courses repository.CourseRepository
+}
+
+// NewTutorService - Constructor
+func NewTutorService() services.TutorService {
+	store := repository.Default()
+	return &tutorServiceImpl{
+		tutors:  store.Tutors,
+		courses: store.Courses,
+	}
+}
+
+func (s *tutorServiceImpl) List(ctx context.Context) ([]models.TutorAccount, error) {
+	return s.tutors.List(ctx)
+}
+
+func (s *tutorServiceImpl) GetByID(ctx context.Context, id string) (*models.TutorAccount, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, services.ErrTutorNotFound
+	}
+
+	return s.tutors.FindByID(ctx, objectID)
+}
+
+func (s *tutorServiceImpl) Invite(ctx context.Context, email, name, invitedBy string) (*models.TutorAccount, error) {
+	email = strings.TrimSpace(email)
+
+	if _, err := s.tutors.FindByEmail(ctx, email); err == nil {
+		return nil, services.ErrTutorExists
+	} else if !errors.Is(err, services.ErrTutorNotFound) {
+		return nil, err
+	}
+
+	// No usable password until the invitation is accepted
+	now := time.Now()
+	tutor := models.TutorAccount{
+		ID:        primitive.NewObjectID(),
+		Email:     email,
+		Name:      strings.TrimSpace(name),
+		IsActive:  true,
+		InvitedBy: invitedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.tutors.Insert(ctx, &tutor); err != nil {
+		return nil, err
+	}
+
+	inviterName := ""
+	if inviter, err := NewAdminService().GetByID(ctx, invitedBy); err == nil {
+		inviterName = inviter.Name
+	}
+
+	if err := NewPasswordResetService().SendInvitation(ctx, models.AccountTypeTutor, tutor.Email, inviterName); err != nil {
+		log.Printf("⚠️ Failed to send tutor invitation to %s: %v", tutor.Email, err)
+	}
+	return &tutor, nil
+}
+
+func (s *tutorServiceImpl) Update(ctx context.Context, id string, update services.TutorUpdate) (*models.TutorAccount, error) {
+	tutor, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	setString := func(field string, value *string) {
+		if value != nil {
+			set[field] = strings.TrimSpace(*value)
+		}
+	}
+	setString("name", update.Name)
+	setString("bio", update.Bio)
+	setString("avatar", update.Avatar)
+	setString("expertise", update.Expertise)
+	setString("credentials", update.Credentials)
+	if update.YearsExp != nil {
+		set["yearsExp"] = *update.YearsExp
+	}
+
+	deactivating := update.IsActive != nil && !*update.IsActive && tutor.IsActive
+	if update.IsActive != nil {
+		set["isActive"] = *update.IsActive
+	}
+
+	if err := s.tutors.Update(ctx, tutor.ID, set); err != nil {
+		return nil, err
+	}
+
+	if deactivating {
+		if err := NewSessionService().RevokeAllForAccount(ctx, models.AccountTypeTutor, id, "tutor deactivated"); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Courses show the tutor through an embedded copy of the profile
+	var courses []struct {
+		ID string `bson:"id"`
+	}
+	if err := s.courses.List(ctx, repository.Fields{"tutorId": id}, repository.Sort{}, &courses); err != nil {
+		return nil, err
+	}
+	profile := bson.M{"tutor": updated.DisplayProfile()}
+	history := NewCourseRevisionService()
+	for _, course := range courses {
+		_, err := history.Update(ctx, profileSyncActor, models.CourseRevisionTutorProfile, course.ID, repository.Fields{"tutorId": id}, profile)
+		if err != nil && !errors.Is(err, services.ErrCourseNotFound) {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+func (s *tutorServiceImpl) Login(ctx context.Context, email, password string) (*models.LoginResult, error) {
+	tutor, err := s.tutors.FindByEmail(ctx, strings.TrimSpace(email))
+	if err == services.ErrTutorNotFound {
+		return nil, services.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Invited tutors have no password until they accept the invitation
+	if tutor.Password == "" || bcrypt.CompareHashAndPassword([]byte(tutor.Password), []byte(password)) != nil {
+		return nil, services.ErrInvalidCredentials
+	}
+	if !tutor.IsActive {
+		return nil, services.ErrTutorDeactivated
+	}
+
+	return NewMFAService().StartLogin(ctx, models.AccountTypeTutor, tutor.ID.Hex(), tutor.Email, models.RoleTutor)
+}
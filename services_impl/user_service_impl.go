@@ -2,31 +2,53 @@ package services_impl
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
-	"github.com/AbaraEmmanuel/jaromind-backend/database"
+	"github.com/AbaraEmmanuel/jaromind-backend/mailer"
 	"github.com/AbaraEmmanuel/jaromind-backend/models"
+	"github.com/AbaraEmmanuel/jaromind-backend/repository"
 	"github.com/AbaraEmmanuel/jaromind-backend/services"
 	"github.com/AbaraEmmanuel/jaromind-backend/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type userServiceImpl struct {
-	collection *mongo.Collection
+	users  repository.UserRepository
+	mailer mailer.Mailer
 }
 
 // Constructor
 func NewUserService() services.UserService {
 	return &userServiceImpl{
-		collection: database.GetCollection("students"),
+		users:  repository.Default().Users,
+		mailer: mailer.Default(),
 	}
 }
 
+// verificationCodeTTL - How long an emailed verification code stays valid
+func verificationCodeTTL() time.Duration {
+	return utils.GetEnvDuration("VERIFICATION_CODE_TTL", 24*time.Hour)
+}
+
+// verificationResendCooldown - Minimum gap between two verification emails
+func verificationResendCooldown() time.Duration {
+	return utils.GetEnvDuration("VERIFICATION_RESEND_COOLDOWN", time.Minute)
+}
+
+// requireEmailVerification - When true, unverified students cannot log in
+func requireEmailVerification() bool {
+	return utils.GetEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+}
+
 // -------- REGISTER METHOD --------
 func (s *userServiceImpl) Register(student models.User) error {
 
@@ -34,12 +56,12 @@ func (s *userServiceImpl) Register(student models.User) error {
 	defer cancel()
 
 	// Check if email already exists
-	count, err := s.collection.CountDocuments(ctx, bson.M{"email": student.Email})
-	if err != nil {
-		return err
+	_, err := s.users.FindByEmail(ctx, student.Email)
+	if err == nil {
+		return services.ErrUserExists
 	}
-	if count > 0 {
-		return errors.New("user with this email already exists")
+	if !errors.Is(err, services.ErrUserNotFound) {
+		return err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(student.Password), bcrypt.DefaultCost)
@@ -53,51 +75,288 @@ func (s *userServiceImpl) Register(student models.User) error {
 		return err
 	}
 
+	now := time.Now()
+	expiresAt := now.Add(verificationCodeTTL())
+
 	// Create new student object
 	newStudent := models.User{
-		ID:        primitive.NewObjectID(),
-		Name:      student.Name,
-		Email:     student.Email,
-		Password:  string(hashedPassword),
-		Code:      code,
-		Verified:  false,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-
-	// Insert into DB
-	_, err = s.collection.InsertOne(ctx, newStudent)
-	if err != nil {
+		ID:            primitive.NewObjectID(),
+		Name:          student.Name,
+		Email:         student.Email,
+		Password:      string(hashedPassword),
+		Code:          code,
+		Verified:      false,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		CodeExpiresAt: &expiresAt,
+		CodeSentAt:    &now,
+	}
+
+	// Insert into DB (the unique email index catches concurrent registrations)
+	if err := s.users.Insert(ctx, &newStudent); err != nil {
 		return err
 	}
 
+	// The account exists even if the email fails; the student can use /verify-email/resend
+	if err := s.mailer.Send(mailer.VerificationEmail(newStudent.Email, newStudent.Name, code, verificationCodeTTL())); err != nil {
+		log.Printf("⚠️ Failed to send verification email to %s: %v", newStudent.Email, err)
+	}
+
 	return nil
 }
 
-func (s *userServiceImpl) Login(email, password string) (string, error) {
+func (s *userServiceImpl) Login(email, password string) (*models.LoginResult, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var user models.User
-	err := s.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	user, err := s.users.FindByEmail(ctx, email)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		return nil, services.ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		return nil, services.ErrInvalidCredentials
+	}
+
+	if user.DeletionRequestedAt != nil {
+		return nil, services.ErrAccountPendingDeletion
+	}
+
+	if requireEmailVerification() && !user.Verified {
+		return nil, services.ErrEmailNotVerified
+	}
+
+	result, err := NewMFAService().StartLogin(ctx, models.AccountTypeStudent, user.ID.Hex(), user.Email, models.RoleStudent)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return result, nil
+
+}
+
+// -------- EMAIL VERIFICATION --------
+
+func (s *userServiceImpl) VerifyEmail(email, code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, services.ErrUserNotFound) {
+		return services.ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.Verified {
+		return services.ErrAlreadyVerified
+	}
+
+	code = strings.TrimSpace(code)
+	if user.Code == "" || subtle.ConstantTimeCompare([]byte(user.Code), []byte(code)) != 1 {
+		return services.ErrInvalidCode
+	}
+	if user.CodeExpiresAt == nil || time.Now().After(*user.CodeExpiresAt) {
+		return services.ErrInvalidCode
+	}
+
+	now := time.Now()
+	return s.users.Update(ctx, user.ID, bson.M{
+		"verified":    true,
+		"verified_at": now,
+		"updated_at":  now,
+	}, "code", "code_expires_at", "code_sent_at")
+}
+
+func (s *userServiceImpl) ResendVerification(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, services.ErrUserNotFound) {
+		// Don't reveal whether the address is registered
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Verified accounts and recent sends get the same answer as unknown addresses
+	if user.Verified {
+		return nil
+	}
+	if user.CodeSentAt != nil && time.Since(*user.CodeSentAt) < verificationResendCooldown() {
+		return nil
+	}
+
+	code, err := utils.GenerateVerificationCode()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.users.Update(ctx, user.ID, bson.M{
+		"code":            code,
+		"code_expires_at": now.Add(verificationCodeTTL()),
+		"code_sent_at":    now,
+		"updated_at":      now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.VerificationEmail(user.Email, user.Name, code, verificationCodeTTL()))
+}
+
+// -------- PROFILE --------
+
+// Allowed values for profile fields that feed UI choices
+var profileThemes = map[string]bool{"": true, "light": true, "dark": true, "system": true}
+
+const (
+	maxNameLength       = 100
+	maxClassLevelLength = 50
+	maxAvatarLength     = 2048
+)
+
+func (s *userServiceImpl) GetProfile(userID string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.findByID(ctx, userID)
+}
+
+func (s *userServiceImpl) findByID(ctx context.Context, userID string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, services.ErrUserNotFound
 	}
 
-	// if !user.Verified {
-	// 	return "", errors.New("email is not verified")
-	// }
+	return s.users.FindByID(ctx, objectID)
+}
+
+func (s *userServiceImpl) UpdateProfile(userID string, update services.ProfileUpdate) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	token, err := utils.GenerateJWT(user.ID.Hex(), user.Email)
+	user, err := s.findByID(ctx, userID)
 	if err != nil {
-		return "", errors.New("failed to generate token")
+		return nil, err
 	}
 
-	return token, nil
+	set, err := profileChanges(update)
+	if err != nil {
+		return nil, err
+	}
+	set["updated_at"] = time.Now()
 
+	if err := s.users.Update(ctx, user.ID, set); err != nil {
+		return nil, err
+	}
+
+	return s.findByID(ctx, userID)
+}
+
+// profileChanges - Validates the update and turns it into a $set document
+func profileChanges(update services.ProfileUpdate) (bson.M, error) {
+	set := bson.M{}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" || len(name) > maxNameLength {
+			return nil, fmt.Errorf("%w: name must be 1-%d characters", services.ErrInvalidProfile, maxNameLength)
+		}
+		set["name"] = name
+	}
+
+	if update.Avatar != nil {
+		avatar := strings.TrimSpace(*update.Avatar)
+		if avatar != "" {
+			parsed, err := url.Parse(avatar)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(avatar) > maxAvatarLength {
+				return nil, fmt.Errorf("%w: avatar must be an http(s) URL", services.ErrInvalidProfile)
+			}
+		}
+		set["avatar"] = avatar
+	}
+
+	if update.ClassLevel != nil {
+		classLevel := strings.TrimSpace(*update.ClassLevel)
+		if len(classLevel) > maxClassLevelLength {
+			return nil, fmt.Errorf("%w: class_level must be at most %d characters", services.ErrInvalidProfile, maxClassLevelLength)
+		}
+		set["class_level"] = classLevel
+	}
+
+	if update.Language != nil {
+		language := strings.TrimSpace(*update.Language)
+		if language != "" && !isLanguageTag(language) {
+			return nil, fmt.Errorf("%w: language must be a language code such as \"en\" or \"en-NG\"", services.ErrInvalidProfile)
+		}
+		set["language"] = language
+	}
+
+	if update.Preferences != nil {
+		if !profileThemes[update.Preferences.Theme] {
+			return nil, fmt.Errorf("%w: theme must be light, dark or system", services.ErrInvalidProfile)
+		}
+		set["preferences"] = update.Preferences
+	}
+
+	return set, nil
+}
+
+// isLanguageTag - Loose BCP 47 check: a 2-3 letter language with optional subtags
+func isLanguageTag(tag string) bool {
+	parts := strings.Split(tag, "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" || len(part) > 8 {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *userServiceImpl) ChangePassword(userID, currentPassword, newPassword string) (*models.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := s.findByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return nil, services.ErrIncorrectPassword
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := accountsFor(models.AccountTypeStudent)
+	if err != nil {
+		return nil, err
+	}
+	if err := accounts.SetPassword(ctx, user.ID, string(hashedPassword), time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Other devices are signed out; the caller continues on a fresh session
+	sessions := NewSessionService()
+	if err := sessions.RevokeAllForAccount(ctx, models.AccountTypeStudent, userID, "password changed"); err != nil {
+		return nil, err
+	}
+	return sessions.Create(ctx, models.AccountTypeStudent, userID, user.Email, models.RoleStudent)
 }
@@ -1,113 +1,166 @@
 package utils
 
 import (
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte(getJWTSecret())
+// Token types carried in the "typ" claim. Only access tokens are accepted by
+// the auth middleware; tokens without "typ" predate it and are access tokens.
+const (
+	TokenTypeAccess       = "access"
+	TokenTypeMFAChallenge = "mfa_challenge"
+)
 
-// Helper function to get JWT secret with fallback
-func getJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// Fallback for development - MUST MATCH middleware/auth.go!
-		return "your-jwt-secret-key-change-in-production"
-	}
-	return secret
+// TokenIssuer - Value of the "iss" claim (JWT_ISSUER, default "jaromind")
+func TokenIssuer() string {
+	return GetEnv("JWT_ISSUER", "jaromind")
 }
 
-// ============================================
-// FOR STUDENTS (EXISTING CODE - BACKWARD COMPATIBLE)
-// ============================================
+// AccessTokenTTL - Lifetime of access tokens (ACCESS_TOKEN_TTL, default 15 minutes)
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// GenerateAccessToken - Short-lived token with a unique jti, bound to a
+// session ("sid") so it can be revoked together with its refresh token.
+// Returns the signed token and its jti.
+func GenerateAccessToken(userID, email, role, sessionID string) (string, string, error) {
+	now := time.Now()
+	jti := uuid.New().String()
+
+	claims := jwt.MapClaims{
+		"user_id": userID,  // Consistent naming (all lowercase)
+		"email":   email,
+		"role":    role,    // Role: "student", "tutor", "content-admin" or "super-admin"
+		"jti":     jti,     // Token ID for the revocation list
+		"typ":     TokenTypeAccess,
+		"exp":     now.Add(AccessTokenTTL()).Unix(),
+		"iat":     now.Unix(), // Issued at
+		"sid":     sessionID,
+	}
 
-// GenerateJWT - Original function for students (keeps backward compatibility)
-func GenerateJWT(userID, email string) (string, error) {
-	// Calls the new function with default "user" role
-	return GenerateJWTWithClaims(userID, email, "user")
+	signed, err := SignClaims(claims)
+	if err != nil {
+		return "", "", err
+	}
+	return signed, jti, nil
 }
 
 // ============================================
-// FOR ADMINS (NEW FUNCTION)
+// IMPERSONATION TOKENS
 // ============================================
 
-// GenerateAdminJWT - Specifically for admins
-func GenerateAdminJWT(userID, email string) (string, error) {
-	return GenerateJWTWithClaims(userID, email, "admin")
+// ImpersonationTTL - Lifetime of impersonation tokens (IMPERSONATION_TTL, default 15 minutes)
+func ImpersonationTTL() time.Duration {
+	return GetEnvDuration("IMPERSONATION_TTL", 15*time.Minute)
 }
 
-// ============================================
-// UNIVERSAL FUNCTION (INTERNAL USE)
-// ============================================
+// GenerateImpersonationToken - Access token for userID that is marked, via the
+// RFC 8693 "act" claim, as used by the admin actorID. It has no session, so it
+// can't be refreshed. Returns the signed token and its jti.
+func GenerateImpersonationToken(userID, email, role, actorID, actorEmail string) (string, string, error) {
+	now := time.Now()
+	jti := uuid.New().String()
 
-// GenerateJWTWithClaims - Universal function with role support
-func GenerateJWTWithClaims(userID, email, role string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,  // Consistent naming (all lowercase)
+	signed, err := SignClaims(jwt.MapClaims{
+		"user_id": userID,
 		"email":   email,
-		"role":    role,    // Role: "user" or "admin"
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), // 3 days expiry
-		"iat":     time.Now().Unix(), // Issued at
+		"role":    role,
+		"jti":     jti,
+		"typ":     TokenTypeAccess,
+		"act":     map[string]interface{}{"sub": actorID, "email": actorEmail},
+		"exp":     now.Add(ImpersonationTTL()).Unix(),
+		"iat":     now.Unix(),
+	})
+	if err != nil {
+		return "", "", err
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return signed, jti, nil
 }
 
 // ============================================
-// HELPER FUNCTIONS
+// MFA CHALLENGE TOKENS
 // ============================================
 
-// ValidateToken - Validate and parse JWT token
-func ValidateToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return jwtSecret, nil
+// MFAChallengeTTL - How long a user has to enter their code after the password step
+func MFAChallengeTTL() time.Duration {
+	return GetEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// GenerateMFAChallengeToken - Proof that the password step succeeded. It can
+// only be exchanged at /login/mfa, never used as an access token. enroll marks
+// accounts that must set up 2FA before they can finish logging in.
+func GenerateMFAChallengeToken(accountType, accountID, email, role string, enroll bool) (string, error) {
+	now := time.Now()
+	return SignClaims(jwt.MapClaims{
+		"typ":    TokenTypeMFAChallenge,
+		"sub":    accountID,
+		"acct":   accountType,
+		"email":  email,
+		"role":   role,
+		"enroll": enroll,
+		"jti":    uuid.New().String(),
+		"exp":    now.Add(MFAChallengeTTL()).Unix(),
+		"iat":    now.Unix(),
 	})
+}
 
+// ValidateMFAChallengeToken - Parse a challenge token, rejecting any other token type
+func ValidateMFAChallengeToken(tokenString string) (jwt.MapClaims, error) {
+	claims, err := ValidateToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	if typ, _ := claims["typ"].(string); typ != TokenTypeMFAChallenge {
+		return nil, jwt.ErrTokenInvalidClaims
 	}
-
-	return nil, jwt.ErrTokenInvalidClaims
+	return claims, nil
 }
 
-// GetUserIDFromToken - Extract user ID from token
-func GetUserIDFromToken(tokenString string) (string, error) {
-	claims, err := ValidateToken(tokenString)
+// SignClaims - Sign claims with the active key, setting "iss" and the "kid" header
+func SignClaims(claims jwt.MapClaims) (string, error) {
+	keys, err := LoadKeys()
 	if err != nil {
 		return "", err
 	}
 
-	// Try different claim names for backward compatibility
-	if userID, ok := claims["user_id"].(string); ok {
-		return userID, nil
-	}
-	if userID, ok := claims["user_Id"].(string); ok { // Old format
-		return userID, nil
+	claims["iss"] = TokenIssuer()
+	token := jwt.NewWithClaims(keys.Active.Method, claims)
+	token.Header["kid"] = keys.Active.ID
+	return token.SignedString(keys.Active.Private)
+}
+
+// ValidateToken - Validate and parse JWT token
+func ValidateToken(tokenString string) (jwt.MapClaims, error) {
+	keys, err := LoadKeys()
+	if err != nil {
+		return nil, err
 	}
 
-	return "", jwt.ErrTokenInvalidClaims
-}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// The algorithm is pinned by the key, never taken from the token
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return key.Public, nil
+	}, jwt.WithIssuer(TokenIssuer()), jwt.WithValidMethods([]string{"RS256", "EdDSA"}))
 
-// GetRoleFromToken - Extract role from token
-func GetRoleFromToken(tokenString string) (string, error) {
-	claims, err := ValidateToken(tokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if role, ok := claims["role"].(string); ok {
-		return role, nil
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
 	}
 
-	return "user", nil // Default to "user" if no role
-}
\ No newline at end of file
+	return nil, jwt.ErrTokenInvalidClaims
+}
@@ -0,0 +1,308 @@
+package utils
+
+// JWT signing keys.
+//
+// Keys live in JWT_KEYS_DIR (default "./keys"), one PEM file per key, and the
+// file name (minus extension) is the key ID written to the token "kid" header:
+//
+//	keys/2026-10-01.pem      PKCS#8 private key (RSA or Ed25519) - can sign and verify
+//	keys/2026-04-01.pub.pem  PKIX public key - verify only (retired signing key)
+//
+// The signing key is JWT_ACTIVE_KID, or the lexically greatest private key ID
+// when unset (date-based IDs therefore pick the newest key). Every key in the
+// directory is published at /.well-known/jwks.json.
+//
+// Rotation (see `jaromindctl keys`):
+//  1. `keys generate` a new key and deploy it everywhere WITHOUT making it
+//     active (pin JWT_ACTIVE_KID to the old key). Other services now see it in
+//     the JWKS.
+//  2. Unpin / set JWT_ACTIVE_KID to the new key and redeploy. New tokens use it.
+//  3. `keys retire` the old key once ACCESS_TOKEN_TTL has passed; it is kept
+//     as .pub.pem so stragglers still verify, and can be deleted later.
+//
+// With no keys on disk startup fails, unless JWT_EPHEMERAL_KEY=true: then an
+// ephemeral Ed25519 key is generated. That is only suitable for local
+// development: tokens die with the process and other instances can't verify
+// them, so it has to be chosen on purpose.
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey - A key usable for verification, and for signing when Private is set
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	Private crypto.Signer
+}
+
+// KeySet - All keys known to this process
+type KeySet struct {
+	Active *SigningKey
+	Keys   map[string]*SigningKey
+}
+
+var (
+	keySet     *KeySet
+	keySetErr  error
+	keySetOnce sync.Once
+)
+
+// KeysDir - Directory holding the PEM key files
+func KeysDir() string {
+	return GetEnv("JWT_KEYS_DIR", "keys")
+}
+
+// LoadKeys - Load (once) and return the key set. Call at startup to fail fast.
+func LoadKeys() (*KeySet, error) {
+	keySetOnce.Do(func() {
+		keySet, keySetErr = loadKeySet(KeysDir(), os.Getenv("JWT_ACTIVE_KID"), GetEnvBool("JWT_EPHEMERAL_KEY", false))
+	})
+	return keySet, keySetErr
+}
+
+func loadKeySet(dir, activeKID string, allowEphemeral bool) (*KeySet, error) {
+	set := &KeySet{Keys: map[string]*SigningKey{}}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var privateIDs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		key, err := ReadKeyFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", name, err)
+		}
+		if _, exists := set.Keys[key.ID]; exists {
+			return nil, fmt.Errorf("jwt key %s: duplicate kid %q", name, key.ID)
+		}
+		set.Keys[key.ID] = key
+		if key.Private != nil {
+			privateIDs = append(privateIDs, key.ID)
+		}
+	}
+
+	if len(privateIDs) == 0 {
+		if len(set.Keys) > 0 {
+			return nil, fmt.Errorf("jwt keys in %s are all public; at least one private key is needed to sign", dir)
+		}
+		if !allowEphemeral {
+			return nil, fmt.Errorf("no jwt keys found in %s (create one with `jaromindctl keys generate`, or set JWT_EPHEMERAL_KEY=true for development)", dir)
+		}
+		log.Printf("⚠️ JWT_EPHEMERAL_KEY=true and no JWT keys found in %q - using an ephemeral Ed25519 key (development only)", dir)
+		key, err := GenerateSigningKey("ephemeral", "EdDSA")
+		if err != nil {
+			return nil, err
+		}
+		set.Keys[key.ID] = key
+		set.Active = key
+		return set, nil
+	}
+
+	if activeKID == "" {
+		sort.Strings(privateIDs)
+		activeKID = privateIDs[len(privateIDs)-1]
+	}
+	active, ok := set.Keys[activeKID]
+	if !ok || active.Private == nil {
+		return nil, fmt.Errorf("JWT_ACTIVE_KID %q has no private key in %s", activeKID, dir)
+	}
+	set.Active = active
+	return set, nil
+}
+
+// ReadKeyFile - Parse <kid>.pem (private) or <kid>.pub.pem (public)
+func ReadKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, ".pub.pem") {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKey(strings.TrimSuffix(name, ".pub.pem"), pub, nil)
+	}
+
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return newSigningKey(strings.TrimSuffix(name, ".pem"), signer.Public(), signer)
+}
+
+func newSigningKey(kid string, pub crypto.PublicKey, priv crypto.Signer) (*SigningKey, error) {
+	key := &SigningKey{ID: kid, Public: pub, Private: priv}
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", pub)
+	}
+	return key, nil
+}
+
+// GenerateSigningKey - New in-memory key; alg is "EdDSA" or "RS256"
+func GenerateSigningKey(kid, alg string) (*SigningKey, error) {
+	switch alg {
+	case "EdDSA":
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKey(kid, pub, priv)
+	case "RS256":
+		priv, err := rsa.GenerateKey(rand.Reader, 3072)
+		if err != nil {
+			return nil, err
+		}
+		return newSigningKey(kid, &priv.PublicKey, priv)
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q (use EdDSA or RS256)", alg)
+}
+
+// WritePrivateKey - Save key as <dir>/<kid>.pem (PKCS#8, mode 0600)
+func WritePrivateKey(dir string, key *SigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, key.ID+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return path, writeNewFile(path, data, 0o600)
+}
+
+// WritePublicKey - Save key as <dir>/<kid>.pub.pem (PKIX)
+func WritePublicKey(dir string, key *SigningKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, key.ID+".pub.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return path, writeNewFile(path, data, 0o644)
+}
+
+func writeNewFile(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ============================================
+// JWKS
+// ============================================
+
+// JWK - Public key in JSON Web Key format (RFC 7517 / RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet - The /.well-known/jwks.json document
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS - Every verification key of this process, active key first
+func PublicJWKS() (*JWKSet, error) {
+	set, err := LoadKeys()
+	if err != nil {
+		return nil, err
+	}
+	return set.JWKS()
+}
+
+// JWKS - Every key of the set, active key first, then newest first
+func (set *KeySet) JWKS() (*JWKSet, error) {
+	ids := make([]string, 0, len(set.Keys))
+	for id := range set.Keys {
+		if id != set.Active.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	ids = append([]string{set.Active.ID}, ids...)
+
+	jwks := &JWKSet{Keys: []JWK{}}
+	for _, id := range ids {
+		jwk, err := toJWK(set.Keys[id])
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+func toJWK(key *SigningKey) (JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+	return jwk, nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// keyDir - A key directory holding a private key for each of private and a
+// public key for each of public
+func keyDir(t *testing.T, private, public []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, kid := range private {
+		key, err := GenerateSigningKey(kid, "EdDSA")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := WritePrivateKey(dir, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, kid := range public {
+		key, err := GenerateSigningKey(kid, "EdDSA")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := WritePublicKey(dir, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadKeySet(t *testing.T) {
+	tests := []struct {
+		name           string
+		private        []string
+		public         []string
+		activeKID      string
+		allowEphemeral bool
+		wantActive     string // "" = an error
+		wantKeys       int
+	}{
+		{name: "no keys", wantActive: ""},
+		{name: "no keys, ephemeral allowed", allowEphemeral: true, wantActive: "ephemeral", wantKeys: 1},
+		{name: "newest private key", private: []string{"2026-04-01", "2026-10-01", "2025-10-01"}, wantActive: "2026-10-01", wantKeys: 3},
+		{name: "pinned key", private: []string{"2026-04-01", "2026-10-01"}, activeKID: "2026-04-01", wantActive: "2026-04-01", wantKeys: 2},
+		{name: "retired keys verify only", private: []string{"2026-04-01"}, public: []string{"2026-10-01"}, wantActive: "2026-04-01", wantKeys: 2},
+		{name: "pinned to a retired key", private: []string{"2026-04-01"}, public: []string{"2026-10-01"}, activeKID: "2026-10-01"},
+		{name: "pinned to an unknown key", private: []string{"2026-04-01"}, activeKID: "2027-01-01"},
+		{name: "only public keys", public: []string{"2026-04-01"}, allowEphemeral: true},
+		{name: "duplicate kid", private: []string{"2026-04-01"}, public: []string{"2026-04-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := loadKeySet(keyDir(t, tt.private, tt.public), tt.activeKID, tt.allowEphemeral)
+			if tt.wantActive == "" {
+				if err == nil {
+					t.Fatalf("loadKeySet = active %q, want an error", set.Active.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.Active.ID != tt.wantActive || set.Active.Private == nil {
+				t.Errorf("active key = %q, want %q with a private key", set.Active.ID, tt.wantActive)
+			}
+			if len(set.Keys) != tt.wantKeys {
+				t.Errorf("loaded %d keys, want %d", len(set.Keys), tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestLoadKeySetMissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	if _, err := loadKeySet(dir, "", false); err == nil {
+		t.Error("a missing key directory must fail without JWT_EPHEMERAL_KEY")
+	}
+	if _, err := loadKeySet(dir, "", true); err != nil {
+		t.Errorf("a missing key directory with JWT_EPHEMERAL_KEY: %v", err)
+	}
+}
+
+func TestLoadKeySetBrokenKey(t *testing.T) {
+	dir := keyDir(t, []string{"2026-04-01"}, nil)
+	if err := os.WriteFile(filepath.Join(dir, "2026-10-01.pem"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadKeySet(dir, "", true); err == nil {
+		t.Error("an unreadable key file must fail instead of being skipped")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := keyDir(t, []string{"2026-04-01", "2026-10-01"}, []string{"2025-10-01"})
+	rsaKey, err := GenerateSigningKey("2026-07-01", "RS256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WritePublicKey(dir, rsaKey); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := loadKeySet(dir, "2026-04-01", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := set.JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Active key first, then newest first
+	want := []string{"2026-04-01", "2026-10-01", "2026-07-01", "2025-10-01"}
+	if len(jwks.Keys) != len(want) {
+		t.Fatalf("JWKS has %d keys, want %d", len(jwks.Keys), len(want))
+	}
+	for i, kid := range want {
+		if jwks.Keys[i].Kid != kid {
+			t.Errorf("JWKS key %d = %q, want %q", i, jwks.Keys[i].Kid, kid)
+		}
+	}
+
+	active := jwks.Keys[0]
+	publicKey := set.Keys["2026-04-01"].Public.(ed25519.PublicKey)
+	if active.Kty != "OKP" || active.Crv != "Ed25519" || active.Alg != "EdDSA" || active.Use != "sig" ||
+		active.X != base64.RawURLEncoding.EncodeToString(publicKey) || active.N != "" {
+		t.Errorf("Ed25519 JWK = %+v", active)
+	}
+
+	rsa := jwks.Keys[2]
+	if rsa.Kty != "RSA" || rsa.Alg != "RS256" || rsa.E != "AQAB" || rsa.N == "" || rsa.X != "" {
+		t.Errorf("RSA JWK = %+v", rsa)
+	}
+}
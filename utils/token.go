@@ -2,6 +2,8 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 )
 
@@ -13,3 +15,18 @@ func GenerateVerificationCode() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// GenerateSecureToken - URL-safe random token with n bytes of entropy
+func GenerateSecureToken(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken - SHA-256 hex digest used to store one-time tokens at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA-1 secret of RFC 6238 appendix B ("12345678901234567890"), base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, last six digits of the eight-digit codes
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	codeAt := func(s int64) string {
+		code, err := TOTPCode(rfcSecret, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfcSecret, "050471", step, true},
+		{"previous step", rfcSecret, codeAt(step - 1), step - 1, true},
+		{"next step", rfcSecret, codeAt(step + 1), step + 1, true},
+		{"two steps ago", rfcSecret, codeAt(step - 2), 0, false},
+		{"two steps ahead", rfcSecret, codeAt(step + 2), 0, false},
+		{"spaces ignored", rfcSecret, " 050 471 ", step, true},
+		{"lower-case secret", strings.ToLower(rfcSecret), "050471", step, true},
+		{"wrong code", rfcSecret, "123456", 0, false},
+		{"too short", rfcSecret, "50471", 0, false},
+		{"too long", rfcSecret, "0504710", 0, false},
+		{"empty", rfcSecret, "", 0, false},
+		{"other secret", "JBSWY3DPEHPK3PXP", "050471", 0, false},
+		{"invalid secret", "not base32!", "050471", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := ValidateTOTP(tt.secret, tt.code, now)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q) = %d, %v, want %d, %v", tt.code, gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"abcde-fghjk", "abcde-fghjk"},
+		{"ABCDE-FGHJK", "abcde-fghjk"},
+		{"abcdefghjk", "abcde-fghjk"},
+		{" abcde fghjk ", "abcde-fghjk"},
+		{"abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	codes, err := GenerateRecoveryCodes(500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	counts := map[rune]int{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || NormalizeRecoveryCode(code) != code {
+			t.Fatalf("recovery code %q, want xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Fatalf("recovery code %q generated twice", code)
+		}
+		seen[code] = true
+		for _, r := range strings.ReplaceAll(code, "-", "") {
+			if !strings.ContainsRune(alphabet, r) {
+				t.Fatalf("recovery code %q has %q, outside the alphabet", code, r)
+			}
+			counts[r]++
+		}
+	}
+
+	// 5000 characters, about 161 of each; a loose bound that only a broken
+	// generator misses
+	for _, r := range alphabet {
+		if counts[r] < 80 || counts[r] > 260 {
+			t.Errorf("%q appears %d times in 5000 characters, want about 161", r, counts[r])
+		}
+	}
+}